@@ -70,6 +70,17 @@ func ExpectedChainID(network Network) (int64, bool) {
 	return chainID, ok
 }
 
+// NetworkForChainID is the inverse of ExpectedChainID.
+// Returns the network and true if the chain ID is known, or "" and false otherwise.
+func NetworkForChainID(chainID int64) (Network, bool) {
+	for network, id := range NetworkChainIDs {
+		if id == chainID {
+			return network, true
+		}
+	}
+	return "", false
+}
+
 // WarmStorageAddresses aliases the FWSS addresses (root of trust)
 // WarmStorageAddresses -- initialized in addresses_generated.go from FWSS root of trust vars
 
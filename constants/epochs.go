@@ -30,14 +30,34 @@ const (
 	PieceAdditionPollIntervalMS          = 1000
 )
 
+// Clock supplies the current time for epoch calculations. Tests can pass a
+// fixed clock to make time-dependent results deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a plain function to the Clock interface.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// SystemClock is the real wall clock, the default wherever a Clock is
+// optional.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// CurrentEpoch returns the chain epoch at the current wall-clock time, or 0
+// for an unknown chain.
 func CurrentEpoch(chainID int64) *big.Int {
-	genesis, ok := GenesisTimestampsByChainID[chainID]
-	if !ok {
-		return big.NewInt(0)
+	return CurrentEpochWithClock(chainID, SystemClock)
+}
+
+// CurrentEpochWithClock is CurrentEpoch with the time taken from clock. A nil
+// clock means SystemClock.
+func CurrentEpochWithClock(chainID int64, clock Clock) *big.Int {
+	if clock == nil {
+		clock = SystemClock
 	}
-	now := time.Now().Unix()
-	epochsSinceGenesis := (now - genesis) / EpochDurationSeconds
-	return big.NewInt(epochsSinceGenesis)
+	return TimeToEpoch(chainID, clock.Now())
 }
 
 func EpochToTime(chainID int64, epoch *big.Int) time.Time {
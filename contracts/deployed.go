@@ -0,0 +1,51 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrContractNotDeployed is returned when a contract address has no code on
+// the connected chain, typically a mainnet address used on calibration or
+// the other way round.
+var ErrContractNotDeployed = errors.New("contract not deployed")
+
+// deploymentCheckTimeout bounds CheckDeployed when the caller's context has
+// no deadline of its own.
+const deploymentCheckTimeout = 30 * time.Second
+
+// ChainCodeReader reads contract code and the chain ID; *ethclient.Client
+// implements it.
+type ChainCodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// CheckDeployed returns ErrContractNotDeployed, naming name, addr and the
+// chain ID, if there is no code at addr.
+func CheckDeployed(ctx context.Context, client ChainCodeReader, name string, addr common.Address) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deploymentCheckTimeout)
+		defer cancel()
+	}
+
+	code, err := client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check %s contract code: %w", name, err)
+	}
+	if len(code) > 0 {
+		return nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %s at %s", ErrContractNotDeployed, name, addr.Hex())
+	}
+	return fmt.Errorf("%w: %s at %s on chain %s", ErrContractNotDeployed, name, addr.Hex(), chainID)
+}
@@ -104,6 +104,57 @@ const ERC20ABIJSON = `[
 		"inputs": [],
 		"outputs": [{"name": "", "type": "bytes32"}],
 		"stateMutability": "view"
+	},
+	{
+		"type": "error",
+		"name": "ERC20InsufficientBalance",
+		"inputs": [
+			{"name": "sender", "type": "address"},
+			{"name": "balance", "type": "uint256"},
+			{"name": "needed", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "ERC20InsufficientAllowance",
+		"inputs": [
+			{"name": "spender", "type": "address"},
+			{"name": "allowance", "type": "uint256"},
+			{"name": "needed", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "ERC20InvalidSender",
+		"inputs": [{"name": "sender", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC20InvalidReceiver",
+		"inputs": [{"name": "receiver", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC20InvalidApprover",
+		"inputs": [{"name": "approver", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC20InvalidSpender",
+		"inputs": [{"name": "spender", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC2612ExpiredSignature",
+		"inputs": [{"name": "deadline", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC2612InvalidSigner",
+		"inputs": [
+			{"name": "signer", "type": "address"},
+			{"name": "owner", "type": "address"}
+		]
 	}
 ]`
 
@@ -145,7 +196,7 @@ func (e *ERC20Contract) Name(ctx context.Context) (string, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return "", fmt.Errorf("name call failed: %w", err)
+		return "", fmt.Errorf("name call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("name", result)
@@ -168,7 +219,7 @@ func (e *ERC20Contract) Symbol(ctx context.Context) (string, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return "", fmt.Errorf("symbol call failed: %w", err)
+		return "", fmt.Errorf("symbol call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("symbol", result)
@@ -191,7 +242,7 @@ func (e *ERC20Contract) Decimals(ctx context.Context) (uint8, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return 0, fmt.Errorf("decimals call failed: %w", err)
+		return 0, fmt.Errorf("decimals call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("decimals", result)
@@ -214,7 +265,7 @@ func (e *ERC20Contract) BalanceOf(ctx context.Context, account common.Address) (
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("balanceOf call failed: %w", err)
+		return nil, fmt.Errorf("balanceOf call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("balanceOf", result)
@@ -237,7 +288,7 @@ func (e *ERC20Contract) Allowance(ctx context.Context, owner, spender common.Add
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("allowance call failed: %w", err)
+		return nil, fmt.Errorf("allowance call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("allowance", result)
@@ -260,7 +311,7 @@ func (e *ERC20Contract) Nonces(ctx context.Context, owner common.Address) (*big.
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("nonces call failed: %w", err)
+		return nil, fmt.Errorf("nonces call failed: %w", DecodeCallError(e.abi, err))
 	}
 
 	values, err := e.abi.Unpack("nonces", result)
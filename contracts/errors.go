@@ -0,0 +1,148 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// UpgradeableErrorsABIJSON declares the custom errors of the OpenZeppelin
+// Ownable and UUPS proxy contracts the FOC services inherit, as a fragment
+// of ABI entries to splice into a contract's ABI JSON array.
+const UpgradeableErrorsABIJSON = `
+	{
+		"type": "error",
+		"name": "OwnableUnauthorizedAccount",
+		"inputs": [{"name": "account", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "OwnableInvalidOwner",
+		"inputs": [{"name": "owner", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "InvalidInitialization",
+		"inputs": []
+	},
+	{
+		"type": "error",
+		"name": "NotInitializing",
+		"inputs": []
+	},
+	{
+		"type": "error",
+		"name": "UUPSUnauthorizedCallContext",
+		"inputs": []
+	},
+	{
+		"type": "error",
+		"name": "UUPSUnsupportedProxiableUUID",
+		"inputs": [{"name": "slot", "type": "bytes32"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC1967InvalidImplementation",
+		"inputs": [{"name": "implementation", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ERC1967NonPayable",
+		"inputs": []
+	},
+	{
+		"type": "error",
+		"name": "AddressEmptyCode",
+		"inputs": [{"name": "target", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "FailedCall",
+		"inputs": []
+	}`
+
+// DecodeError decodes revert data returned by a contract call. Error(string)
+// and Panic(uint256) yield their message; custom errors declared in
+// contractABI are rendered as Name(arg=value, ...). Data matching none of
+// them is an error.
+func DecodeError(contractABI abi.ABI, data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("revert data too short: %d bytes", len(data))
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason, nil
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	abiErr, err := contractABI.ErrorByID(selector)
+	if err != nil {
+		return "", fmt.Errorf("unknown error selector %s", hexutil.Encode(selector[:]))
+	}
+	unpacked, err := abiErr.Unpack(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to unpack %s: %w", abiErr.Name, err)
+	}
+	values, _ := unpacked.([]interface{})
+
+	args := make([]string, len(abiErr.Inputs))
+	for i, input := range abiErr.Inputs {
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		if input.Name == "" {
+			args[i] = fmt.Sprint(value)
+		} else {
+			args[i] = fmt.Sprintf("%s=%v", input.Name, value)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(args, ", ")), nil
+}
+
+// ErrNoReturnData is returned when a view call succeeds but returns no data,
+// as happens when the contract has no such function and no fallback reverts.
+var ErrNoReturnData = errors.New("call returned no data")
+
+// RevertError is a failed contract call whose revert data DecodeError could
+// decode. It unwraps to the underlying RPC error.
+type RevertError struct {
+	Reason string
+	Data   []byte
+	Err    error
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.Reason)
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeCallError wraps err from CallContract in a RevertError when the node
+// attached revert data that decodes against contractABI and the message does
+// not already carry the reason. Any other error is returned unchanged.
+func DecodeCallError(contractABI abi.ABI, err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	s, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(s)
+	if decodeErr != nil {
+		return err
+	}
+	reason, decodeErr := DecodeError(contractABI, data)
+	if decodeErr != nil || strings.Contains(err.Error(), reason) {
+		return err
+	}
+	return &RevertError{Reason: reason, Data: data, Err: err}
+}
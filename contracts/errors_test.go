@@ -0,0 +1,88 @@
+package contracts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// revertDataError mimics the JSON-RPC error go-ethereum returns for a
+// reverted eth_call.
+type revertDataError struct {
+	msg  string
+	data string
+}
+
+func (e *revertDataError) Error() string          { return e.msg }
+func (e *revertDataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeError(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(PaymentsABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	futureErr := parsed.Errors["CannotSettleFutureEpochs"]
+	args, err := futureErr.Inputs.Pack(big.NewInt(7), big.NewInt(100), big.NewInt(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := append(futureErr.ID[:4:4], args...)
+
+	stringType, _ := abi.NewType("string", "", nil)
+	revert, err := abi.Arguments{{Type: stringType}}.Pack("not allowed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revert = append([]byte{0x08, 0xc3, 0x79, 0xa0}, revert...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "custom error", data: custom, want: "CannotSettleFutureEpochs(railId=7, maxAllowedEpoch=100, attemptedEpoch=200)"},
+		{name: "Error(string)", data: revert, want: "not allowed"},
+		{name: "unknown selector", data: []byte{0xde, 0xad, 0xbe, 0xef}, wantErr: true},
+		{name: "short data", data: []byte{0x01}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeError(parsed, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("DecodeCallError", func(t *testing.T) {
+		rpcErr := &revertDataError{msg: "execution reverted", data: hexutil.Encode(custom)}
+		err := DecodeCallError(parsed, rpcErr)
+		var revertErr *RevertError
+		if !errors.As(err, &revertErr) || !strings.HasPrefix(revertErr.Reason, "CannotSettleFutureEpochs(") {
+			t.Fatalf("DecodeCallError() = %v, want a RevertError", err)
+		}
+		if !errors.Is(err, rpcErr) {
+			t.Error("RevertError should unwrap to the RPC error")
+		}
+
+		// the node already spelled out the reason
+		rpcErr = &revertDataError{msg: "execution reverted: not allowed", data: hexutil.Encode(revert)}
+		if got := DecodeCallError(parsed, rpcErr); got != error(rpcErr) {
+			t.Errorf("DecodeCallError() = %v, want the RPC error unchanged", got)
+		}
+
+		plain := errors.New("connection refused")
+		if got := DecodeCallError(parsed, plain); got != plain {
+			t.Errorf("DecodeCallError() = %v, want the error unchanged", got)
+		}
+	})
+}
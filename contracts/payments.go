@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/data-preservation-programs/go-synapse/pkg/abix"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -220,6 +221,64 @@ const PaymentsABIJSON = `[
 			{"name": "note", "type": "string"}
 		],
 		"stateMutability": "nonpayable"
+	},
+	{
+		"type": "function",
+		"name": "terminateRail",
+		"inputs": [
+			{"name": "railId", "type": "uint256"}
+		],
+		"outputs": [],
+		"stateMutability": "nonpayable"
+	},
+	{
+		"type": "function",
+		"name": "NETWORK_FEE",
+		"inputs": [],
+		"outputs": [
+			{"name": "", "type": "uint256"}
+		],
+		"stateMutability": "view"
+	},
+	{
+		"type": "error",
+		"name": "RailInactiveOrSettled",
+		"inputs": [{"name": "railId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "RailNotTerminated",
+		"inputs": [{"name": "railId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "CannotSettleFutureEpochs",
+		"inputs": [
+			{"name": "railId", "type": "uint256"},
+			{"name": "maxAllowedEpoch", "type": "uint256"},
+			{"name": "attemptedEpoch", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "InsufficientUnlockedFunds",
+		"inputs": [
+			{"name": "available", "type": "uint256"},
+			{"name": "requested", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "InsufficientNativeTokenForBurn",
+		"inputs": [
+			{"name": "required", "type": "uint256"},
+			{"name": "sent", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "ZeroAddressNotAllowed",
+		"inputs": [{"name": "varName", "type": "string"}]
 	}
 ]`
 
@@ -247,6 +306,17 @@ type RailViewResult struct {
 }
 
 
+// SettleRailResult is the return tuple of settleRail.
+type SettleRailResult struct {
+	TotalSettledAmount      *big.Int
+	TotalNetPayeeAmount     *big.Int
+	TotalOperatorCommission *big.Int
+	TotalNetworkFee         *big.Int
+	FinalSettledEpoch       *big.Int
+	Note                    string
+}
+
+
 type RailInfoResult struct {
 	RailId       *big.Int
 	IsTerminated bool
@@ -299,7 +369,7 @@ func (p *PaymentsContract) Address() common.Address {
 }
 
 
-func (p *PaymentsContract) Accounts(ctx context.Context, token, owner common.Address) (funds, lockupCurrent, lockupRate, lockupLastSettledAt *big.Int, err error) {
+func (p *PaymentsContract) Accounts(ctx context.Context, token, owner common.Address, opts ...callopt.Option) (funds, lockupCurrent, lockupRate, lockupLastSettledAt *big.Int, err error) {
 	data, err := p.abi.Pack("accounts", token, owner)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to pack accounts call: %w", err)
@@ -308,9 +378,9 @@ func (p *PaymentsContract) Accounts(ctx context.Context, token, owner common.Add
 	result, err := p.client.CallContract(ctx, ethereum.CallMsg{
 		To:   &p.address,
 		Data: data,
-	}, nil)
+	}, callopt.Apply(opts...).BlockNumber)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("accounts call failed: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("accounts call failed: %w", DecodeCallError(p.abi, err))
 	}
 
 	values, err := p.abi.Unpack("accounts", result)
@@ -322,7 +392,7 @@ func (p *PaymentsContract) Accounts(ctx context.Context, token, owner common.Add
 }
 
 
-func (p *PaymentsContract) GetAccountInfoIfSettled(ctx context.Context, token, owner common.Address) (fundedUntilEpoch, currentFunds, availableFunds, currentLockupRate *big.Int, err error) {
+func (p *PaymentsContract) GetAccountInfoIfSettled(ctx context.Context, token, owner common.Address, opts ...callopt.Option) (fundedUntilEpoch, currentFunds, availableFunds, currentLockupRate *big.Int, err error) {
 	data, err := p.abi.Pack("getAccountInfoIfSettled", token, owner)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to pack getAccountInfoIfSettled call: %w", err)
@@ -331,9 +401,9 @@ func (p *PaymentsContract) GetAccountInfoIfSettled(ctx context.Context, token, o
 	result, err := p.client.CallContract(ctx, ethereum.CallMsg{
 		To:   &p.address,
 		Data: data,
-	}, nil)
+	}, callopt.Apply(opts...).BlockNumber)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("getAccountInfoIfSettled call failed: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("getAccountInfoIfSettled call failed: %w", DecodeCallError(p.abi, err))
 	}
 
 	values, err := p.abi.Unpack("getAccountInfoIfSettled", result)
@@ -345,6 +415,34 @@ func (p *PaymentsContract) GetAccountInfoIfSettled(ctx context.Context, token, o
 }
 
 
+// NetworkFee reads the NETWORK_FEE constant, the native-token fee settleRail
+// must be sent with.
+func (p *PaymentsContract) NetworkFee(ctx context.Context, opts ...callopt.Option) (*big.Int, error) {
+	data, err := p.abi.Pack("NETWORK_FEE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack NETWORK_FEE call: %w", err)
+	}
+
+	result, err := p.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &p.address,
+		Data: data,
+	}, callopt.Apply(opts...).BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("NETWORK_FEE call failed: %w", DecodeCallError(p.abi, err))
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("NETWORK_FEE call failed: %w", ErrNoReturnData)
+	}
+
+	values, err := p.abi.Unpack("NETWORK_FEE", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack NETWORK_FEE result: %w", err)
+	}
+
+	return values[0].(*big.Int), nil
+}
+
+
 func (p *PaymentsContract) GetOperatorApproval(ctx context.Context, token, client, operator common.Address) (isApproved bool, rateAllowance, lockupAllowance, rateUsed, lockupUsed, maxLockupPeriod *big.Int, err error) {
 	data, err := p.abi.Pack("operatorApprovals", token, client, operator)
 	if err != nil {
@@ -356,7 +454,7 @@ func (p *PaymentsContract) GetOperatorApproval(ctx context.Context, token, clien
 		Data: data,
 	}, nil)
 	if err != nil {
-		return false, nil, nil, nil, nil, nil, fmt.Errorf("operatorApprovals call failed: %w", err)
+		return false, nil, nil, nil, nil, nil, fmt.Errorf("operatorApprovals call failed: %w", DecodeCallError(p.abi, err))
 	}
 
 	values, err := p.abi.Unpack("operatorApprovals", result)
@@ -379,7 +477,7 @@ func (p *PaymentsContract) GetRail(ctx context.Context, railId *big.Int) (*RailV
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("getRail call failed: %w", err)
+		return nil, fmt.Errorf("getRail call failed: %w", DecodeCallError(p.abi, err))
 	}
 
 	var raw getRailOutput
@@ -415,7 +513,7 @@ func (p *PaymentsContract) GetRailsForPayerAndToken(ctx context.Context, payer,
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("getRailsForPayerAndToken call failed: %w", err)
+		return nil, nil, nil, fmt.Errorf("getRailsForPayerAndToken call failed: %w", DecodeCallError(p.abi, err))
 	}
 
 	values, err := p.abi.Unpack("getRailsForPayerAndToken", result)
@@ -496,10 +594,61 @@ func (p *PaymentsContract) SettleRail(opts *bind.TransactOpts, railId, untilEpoc
 	return p.transact(opts, data)
 }
 
-func (p *PaymentsContract) transact(opts *bind.TransactOpts, data []byte) (*types.Transaction, error) {
-	nonce, err := p.client.PendingNonceAt(opts.Context, opts.From)
+// TerminateRail ends a rail. Its payments stop accruing at the rail's end
+// epoch, after which it can be settled one last time.
+func (p *PaymentsContract) TerminateRail(opts *bind.TransactOpts, railId *big.Int) (*types.Transaction, error) {
+	data, err := p.abi.Pack("terminateRail", railId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, fmt.Errorf("failed to pack terminateRail call: %w", err)
+	}
+
+	return p.transact(opts, data)
+}
+
+// SimulateSettleRail runs settleRail as an eth_call from the given account
+// against the pending block, sending value as the settlement fee, and returns
+// what the transaction would settle. Nothing is submitted.
+func (p *PaymentsContract) SimulateSettleRail(ctx context.Context, from common.Address, value, railId, untilEpoch *big.Int) (*SettleRailResult, error) {
+	data, err := p.abi.Pack("settleRail", railId, untilEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack settleRail call: %w", err)
+	}
+
+	result, err := p.client.PendingCallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &p.address,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("settleRail call failed: %w", DecodeCallError(p.abi, err))
+	}
+
+	values, err := p.abi.Unpack("settleRail", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack settleRail result: %w", err)
+	}
+
+	return &SettleRailResult{
+		TotalSettledAmount:      values[0].(*big.Int),
+		TotalNetPayeeAmount:     values[1].(*big.Int),
+		TotalOperatorCommission: values[2].(*big.Int),
+		TotalNetworkFee:         values[3].(*big.Int),
+		FinalSettledEpoch:       values[4].(*big.Int),
+		Note:                    values[5].(string),
+	}, nil
+}
+
+func (p *PaymentsContract) transact(opts *bind.TransactOpts, data []byte) (*types.Transaction, error) {
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	} else {
+		pending, err := p.client.PendingNonceAt(opts.Context, opts.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		nonce = pending
 	}
 
 	gasPrice, err := p.client.SuggestGasPrice(opts.Context)
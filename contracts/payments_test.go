@@ -31,6 +31,7 @@ func TestPaymentsABI(t *testing.T) {
 			"getRailsForPayeeAndToken",
 			"settleRail",
 			"settleTerminatedRailWithoutValidation",
+			"terminateRail",
 		}
 
 		for _, method := range methods {
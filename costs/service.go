@@ -28,6 +28,7 @@ type Service struct {
 	usdfcAddress     common.Address
 	fwssAddress      common.Address
 	pdpVerifierAddr  common.Address
+	clock            constants.Clock
 }
 
 type ServiceConfig struct {
@@ -37,7 +38,21 @@ type ServiceConfig struct {
 	USDFCAddress       common.Address
 }
 
-func NewService(client *ethclient.Client, chainID int64, config ServiceConfig) (*Service, error) {
+type ServiceOption func(*Service) error
+
+// WithClock sets the clock GetAccountSummary uses for CurrentEpoch. Defaults
+// to constants.SystemClock.
+func WithClock(clock constants.Clock) ServiceOption {
+	return func(s *Service) error {
+		if clock == nil {
+			return fmt.Errorf("clock must not be nil")
+		}
+		s.clock = clock
+		return nil
+	}
+}
+
+func NewService(client *ethclient.Client, chainID int64, config ServiceConfig, opts ...ServiceOption) (*Service, error) {
 	fwss, err := warmstorage.NewFWSSContract(config.FWSSAddress, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create FWSS contract: %w", err)
@@ -53,7 +68,7 @@ func NewService(client *ethclient.Client, chainID int64, config ServiceConfig) (
 		return nil, fmt.Errorf("failed to create payments contract: %w", err)
 	}
 
-	return &Service{
+	s := &Service{
 		ethClient:        client,
 		chainID:          chainID,
 		fwss:             fwss,
@@ -62,7 +77,14 @@ func NewService(client *ethclient.Client, chainID int64, config ServiceConfig) (
 		usdfcAddress:     config.USDFCAddress,
 		fwssAddress:      config.FWSSAddress,
 		pdpVerifierAddr:  config.PDPVerifierAddress,
-	}, nil
+		clock:            constants.SystemClock,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
 }
 
 func (s *Service) GetServicePrice(ctx context.Context) (*warmstorage.ServicePrice, error) {
@@ -280,7 +302,7 @@ func (s *Service) GetAccountSummary(ctx context.Context, owner common.Address) (
 
 	ratePerMonth := new(big.Int).Mul(currentRate, big.NewInt(constants.EpochsPerMonth))
 
-	currentEpoch := constants.CurrentEpoch(s.chainID)
+	currentEpoch := constants.CurrentEpochWithClock(s.chainID, s.clock)
 
 	return &AccountSummary{
 		Funds:              funds,
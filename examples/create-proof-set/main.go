@@ -13,7 +13,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ipfs/go-cid"
 )
 
 func main() {
@@ -127,7 +126,7 @@ func run() error {
 	if pieceCIDStr != "" {
 		log.Println("\nAdding piece to proof set...")
 
-		pieceCID, err := cid.Parse(pieceCIDStr)
+		pieceCID, err := pdp.ParsePieceCID(pieceCIDStr)
 		if err != nil {
 			return fmt.Errorf("failed to parse piece CID: %w", err)
 		}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	synapse "github.com/data-preservation-programs/go-synapse"
+	"github.com/data-preservation-programs/go-synapse/signer"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -29,9 +30,11 @@ func main() {
 		log.Fatal("PROVIDER_URL environment variable is required")
 	}
 
+	// without RPC_URL, use the public calibration endpoint
+	var network synapse.Network
 	rpcURL := os.Getenv("RPC_URL")
 	if rpcURL == "" {
-		rpcURL = "https://api.calibration.node.glif.io/rpc/v1"
+		network = synapse.NetworkCalibration
 	}
 
 	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
@@ -47,6 +50,7 @@ func main() {
 	client, err := synapse.New(ctx, synapse.Options{
 		PrivateKey:  privateKey,
 		RPCURL:      rpcURL,
+		Network:     network,
 		ProviderURL: providerURL,
 	})
 	if err != nil {
@@ -56,13 +60,19 @@ func main() {
 
 	fmt.Printf("Connected to %s (chain ID: %d)\n", client.Network(), client.ChainID())
 	fmt.Printf("Client address: %s\n", client.Address().Hex())
+	// FWSS and Payments report payers as 0x addresses; a Lotus wallet shows
+	// the same account as f410
+	if filAddr, err := signer.EVMToFilecoin(client.Address()); err == nil {
+		fmt.Printf("Client Filecoin address: %s\n", filAddr)
+	}
 
 	storage, err := client.Storage()
 	if err != nil {
 		log.Fatalf("Failed to get storage manager: %v", err)
 	}
 
-	testData := []byte("Hello, Filecoin! This is a test upload from the Synapse Go SDK.")
+	// pieces must be at least MinUploadSize bytes
+	testData := bytes.Repeat([]byte("Hello, Filecoin! This is a test upload from the Synapse Go SDK. "), 4)
 	fmt.Printf("\nUploading %d bytes of data...\n", len(testData))
 
 	result, err := storage.UploadBytes(ctx, testData, nil)
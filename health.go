@@ -0,0 +1,267 @@
+package synapse
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/payments"
+	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// HealthStatus is the outcome of a single health check. Statuses are ordered
+// so the worst of several can be found by comparison.
+type HealthStatus int
+
+const (
+	HealthOK HealthStatus = iota
+	HealthWarning
+	HealthError
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthOK:
+		return "ok"
+	case HealthWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// HealthCheck is the result of one check in a HealthReport.
+type HealthCheck struct {
+	Name    string
+	Status  HealthStatus
+	Message string
+}
+
+// HealthReport aggregates account and proof set checks.
+type HealthReport struct {
+	Epoch  int64
+	Checks []HealthCheck
+}
+
+// Status returns the worst status across all checks.
+func (r *HealthReport) Status() HealthStatus {
+	worst := HealthOK
+	for _, c := range r.Checks {
+		if c.Status > worst {
+			worst = c.Status
+		}
+	}
+	return worst
+}
+
+// Healthy reports whether every check passed.
+func (r *HealthReport) Healthy() bool {
+	return r.Status() == HealthOK
+}
+
+// HealthOptions tunes HealthCheck thresholds. Zero values use the defaults.
+type HealthOptions struct {
+	// Token is the payment token to check. Defaults to USDFC.
+	Token payments.Token
+	// ProofSetIDs are the proof sets whose proving deadlines are checked.
+	ProofSetIDs []*big.Int
+	// MinFundedEpochs warns when the account's lockup is funded for fewer
+	// epochs than this. Defaults to 10 days.
+	MinFundedEpochs int64
+	// MinChallengeLeadEpochs warns when a proof set's next challenge is
+	// closer than this. Defaults to one hour.
+	MinChallengeLeadEpochs int64
+	// AllowanceWarnPercent warns when operator rate or lockup usage exceeds
+	// this share of the allowance. Defaults to 90.
+	AllowanceWarnPercent int64
+}
+
+func (o *HealthOptions) withDefaults() HealthOptions {
+	opts := HealthOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Token == "" {
+		opts.Token = payments.TokenUSDFC
+	}
+	if opts.MinFundedEpochs == 0 {
+		opts.MinFundedEpochs = 10 * constants.EpochsPerDay
+	}
+	if opts.MinChallengeLeadEpochs == 0 {
+		opts.MinChallengeLeadEpochs = 120
+	}
+	if opts.AllowanceWarnPercent == 0 {
+		opts.AllowanceWarnPercent = 90
+	}
+	return opts
+}
+
+// HealthCheck runs the payments account, operator approval and proof set
+// deadline checks concurrently and returns a report. A check that cannot run
+// (e.g. an RPC failure) is reported with HealthError rather than failing the
+// whole report.
+func (c *Client) HealthCheck(ctx context.Context, opts *HealthOptions) (*HealthReport, error) {
+	o := opts.withDefaults()
+
+	head, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current epoch: %w", err)
+	}
+	epoch := new(big.Int).SetUint64(head)
+
+	var paymentsOpts []payments.ServiceOption
+	if c.usdfcAddress != (common.Address{}) {
+		paymentsOpts = append(paymentsOpts, payments.WithUSDFCAddress(c.usdfcAddress))
+	}
+	paymentsSvc, paymentsErr := payments.NewService(c.ethClient, c.privateKey, big.NewInt(c.chainID), c.paymentsAddress, paymentsOpts...)
+
+	// each check writes its own slot so the report order is stable
+	checks := make([]HealthCheck, 2+len(o.ProofSetIDs))
+	var wg sync.WaitGroup
+	run := func(slot int, fn func() HealthCheck) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[slot] = fn()
+		}()
+	}
+
+	run(0, func() HealthCheck {
+		if paymentsErr != nil {
+			return HealthCheck{Name: "funds", Status: HealthError, Message: paymentsErr.Error()}
+		}
+		return checkFunds(ctx, paymentsSvc, o, epoch)
+	})
+	run(1, func() HealthCheck {
+		if paymentsErr != nil {
+			return HealthCheck{Name: "operator approval", Status: HealthError, Message: paymentsErr.Error()}
+		}
+		return checkOperatorApproval(ctx, paymentsSvc, c.warmStorageAddress, o)
+	})
+
+	if len(o.ProofSetIDs) > 0 {
+		config := pdp.DefaultManagerConfig()
+		config.ContractAddress = c.pdpVerifierAddress
+		manager, err := pdp.NewManagerWithConfig(ctx, c.ethClient, pdp.NewPrivateKeySigner(c.privateKey), constants.Network(c.network), &config)
+		for i, id := range o.ProofSetIDs {
+			id := id
+			run(2+i, func() HealthCheck {
+				name := fmt.Sprintf("proof set %s", id)
+				if err != nil {
+					return HealthCheck{Name: name, Status: HealthError, Message: err.Error()}
+				}
+				return checkProofSet(ctx, manager, id, name, o, epoch)
+			})
+		}
+	}
+
+	wg.Wait()
+
+	return &HealthReport{Epoch: epoch.Int64(), Checks: checks}, nil
+}
+
+func checkFunds(ctx context.Context, svc *payments.Service, o HealthOptions, epoch *big.Int) HealthCheck {
+	check := HealthCheck{Name: "funds"}
+
+	info, err := svc.AccountInfo(ctx, o.Token)
+	if err != nil {
+		check.Status = HealthError
+		check.Message = err.Error()
+		return check
+	}
+
+	remaining := new(big.Int).Sub(info.FundedUntilEpoch, epoch)
+	switch {
+	case remaining.Sign() <= 0:
+		check.Status = HealthError
+		check.Message = fmt.Sprintf("lockup funded only until epoch %s; deposit more funds", info.FundedUntilEpoch)
+	case remaining.Cmp(big.NewInt(o.MinFundedEpochs)) < 0:
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("lockup funded for %s more epochs, below %d", remaining, o.MinFundedEpochs)
+	default:
+		check.Message = fmt.Sprintf("%s available", info.AvailableFunds)
+	}
+	return check
+}
+
+func checkOperatorApproval(ctx context.Context, svc *payments.Service, operator common.Address, o HealthOptions) HealthCheck {
+	check := HealthCheck{Name: "operator approval"}
+
+	approval, err := svc.ServiceApproval(ctx, operator, o.Token)
+	if err != nil {
+		check.Status = HealthError
+		check.Message = err.Error()
+		return check
+	}
+
+	if !approval.IsApproved {
+		check.Status = HealthError
+		check.Message = fmt.Sprintf("warm storage service %s is not approved as operator", operator.Hex())
+		return check
+	}
+
+	if nearLimit(approval.RateUsed, approval.RateAllowance, o.AllowanceWarnPercent) {
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("rate allowance %s/%s used", approval.RateUsed, approval.RateAllowance)
+		return check
+	}
+	if nearLimit(approval.LockupUsed, approval.LockupAllowance, o.AllowanceWarnPercent) {
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("lockup allowance %s/%s used", approval.LockupUsed, approval.LockupAllowance)
+		return check
+	}
+
+	check.Message = "approved"
+	return check
+}
+
+func checkProofSet(ctx context.Context, manager *pdp.Manager, id *big.Int, name string, o HealthOptions, epoch *big.Int) HealthCheck {
+	check := HealthCheck{Name: name}
+
+	live, err := manager.DataSetLive(ctx, id)
+	if err != nil {
+		check.Status = HealthError
+		check.Message = err.Error()
+		return check
+	}
+	if !live {
+		check.Status = HealthError
+		check.Message = "proof set is not live"
+		return check
+	}
+
+	next, err := manager.GetNextChallengeEpoch(ctx, id)
+	if err != nil {
+		check.Status = HealthError
+		check.Message = err.Error()
+		return check
+	}
+
+	lead := int64(next) - epoch.Int64()
+	switch {
+	case next == 0:
+		check.Message = "no challenge scheduled"
+	case lead <= 0:
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("challenge epoch %d reached; proof due", next)
+	case lead < o.MinChallengeLeadEpochs:
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("next challenge in %d epochs", lead)
+	default:
+		check.Message = fmt.Sprintf("next challenge at epoch %d", next)
+	}
+	return check
+}
+
+// nearLimit reports whether used is at least percent% of allowance.
+func nearLimit(used, allowance *big.Int, percent int64) bool {
+	if used == nil || allowance == nil || allowance.Sign() == 0 {
+		return false
+	}
+	lhs := new(big.Int).Mul(used, big.NewInt(100))
+	rhs := new(big.Int).Mul(allowance, big.NewInt(percent))
+	return lhs.Cmp(rhs) >= 0
+}
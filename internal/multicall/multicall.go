@@ -0,0 +1,121 @@
+// Package multicall batches read-only contract calls through Multicall3's
+// aggregate3 so many views cost a single eth_call.
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const multicall3ABIJSON = `[
+	{
+		"type": "function",
+		"name": "aggregate3",
+		"inputs": [
+			{
+				"name": "calls",
+				"type": "tuple[]",
+				"components": [
+					{"name": "target", "type": "address"},
+					{"name": "allowFailure", "type": "bool"},
+					{"name": "callData", "type": "bytes"}
+				]
+			}
+		],
+		"outputs": [
+			{
+				"name": "returnData",
+				"type": "tuple[]",
+				"components": [
+					{"name": "success", "type": "bool"},
+					{"name": "returnData", "type": "bytes"}
+				]
+			}
+		],
+		"stateMutability": "payable"
+	}
+]`
+
+var multicall3ABI = mustParseABI(multicall3ABIJSON)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Call is one call in a batch.
+type Call struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// Result is the outcome of one call. A reverted call has Success false and
+// ReturnData holding the revert data.
+type Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Aggregate3 runs calls through the Multicall3 contract at addr, allowing
+// individual calls to fail, and returns one Result per call in order.
+// blockNumber pins the batch to a block; nil means latest.
+func Aggregate3(ctx context.Context, caller ethereum.ContractCaller, addr common.Address, calls []Call, blockNumber *big.Int) ([]Result, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	args := make([]call3, len(calls))
+	for i, c := range calls {
+		args[i] = call3{Target: c.Target, AllowFailure: true, CallData: c.CallData}
+	}
+
+	data, err := multicall3ABI.Pack("aggregate3", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack aggregate3 call: %w", err)
+	}
+
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: data}, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call aggregate3: %w", err)
+	}
+
+	values, err := multicall3ABI.Unpack("aggregate3", out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack aggregate3 result: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty result from aggregate3")
+	}
+
+	raw, ok := values[0].([]struct {
+		Success    bool   `json:"success"`
+		ReturnData []byte `json:"returnData"`
+	})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for aggregate3 result: %T", values[0])
+	}
+	if len(raw) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 returned %d results for %d calls", len(raw), len(calls))
+	}
+
+	results := make([]Result, len(raw))
+	for i, r := range raw {
+		results[i] = Result{Success: r.Success, ReturnData: r.ReturnData}
+	}
+	return results, nil
+}
@@ -0,0 +1,108 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// echoCaller decodes aggregate3 calls and answers each one with its own call
+// data, failing calls whose data is empty.
+type echoCaller struct {
+	block *big.Int
+}
+
+func (e *echoCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	e.block = blockNumber
+	args, err := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := args[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	out := make([]result, len(calls))
+	for i, c := range calls {
+		out[i] = result{Success: len(c.CallData) > 0, ReturnData: c.CallData}
+	}
+	return multicall3ABI.Methods["aggregate3"].Outputs.Pack(out)
+}
+
+func TestAggregate3(t *testing.T) {
+	target := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	calls := []Call{
+		{Target: target, CallData: []byte{0x01, 0x02}},
+		{Target: target},
+		{Target: target, CallData: []byte{0x03}},
+	}
+
+	caller := &echoCaller{}
+	results, err := Aggregate3(context.Background(), caller, common.Address{}, calls, big.NewInt(99))
+	if err != nil {
+		t.Fatalf("Aggregate3() error = %v", err)
+	}
+	if caller.block == nil || caller.block.Int64() != 99 {
+		t.Errorf("block = %v, want 99", caller.block)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("got %d results, want %d", len(results), len(calls))
+	}
+	for i, c := range calls {
+		wantOK := len(c.CallData) > 0
+		if results[i].Success != wantOK || !bytes.Equal(results[i].ReturnData, c.CallData) {
+			t.Errorf("results[%d] = %+v, want success %v data %x", i, results[i], wantOK, c.CallData)
+		}
+	}
+}
+
+func TestAggregate3_Empty(t *testing.T) {
+	results, err := Aggregate3(context.Background(), nil, common.Address{}, nil, nil)
+	if err != nil || results != nil {
+		t.Errorf("Aggregate3(nil) = %v, %v; want nil, nil", results, err)
+	}
+}
+
+type codeReader struct {
+	code  map[common.Address][]byte
+	calls int
+}
+
+func (r *codeReader) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	r.calls++
+	return r.code[account], nil
+}
+
+func TestProbe(t *testing.T) {
+	deployed := common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	missing := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	reader := &codeReader{code: map[common.Address][]byte{deployed: {0x60, 0x80}}}
+
+	var reported []common.Address
+	p := &Probe{OnMissing: func(addr common.Address) { reported = append(reported, addr) }}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if !p.Available(ctx, reader, deployed) {
+			t.Error("Available() = false for an address with code")
+		}
+		if p.Available(ctx, reader, missing) {
+			t.Error("Available() = true for an address without code")
+		}
+	}
+	if reader.calls != 2 {
+		t.Errorf("CodeAt called %d times, want once per address", reader.calls)
+	}
+	if len(reported) != 1 || reported[0] != missing {
+		t.Errorf("OnMissing calls = %v, want [%s]", reported, missing.Hex())
+	}
+}
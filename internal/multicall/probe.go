@@ -0,0 +1,57 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CodeReader reads contract code; *ethclient.Client implements it.
+type CodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Probe remembers whether Multicall3 is deployed at the addresses it has
+// checked, so batched reads can fall back to one call per item on chains
+// without it. The zero value is ready to use.
+type Probe struct {
+	// OnMissing, if set, is called once for each address found to have no
+	// code.
+	OnMissing func(addr common.Address)
+
+	mu       sync.Mutex
+	deployed map[common.Address]bool
+}
+
+// Available reports whether Multicall3 is deployed at addr, checking with
+// CodeAt the first time. If the check itself fails it reports true without
+// remembering the answer, leaving the batch call to surface the problem.
+func (p *Probe) Available(ctx context.Context, reader CodeReader, addr common.Address) bool {
+	p.mu.Lock()
+	deployed, known := p.deployed[addr]
+	p.mu.Unlock()
+	if known {
+		return deployed
+	}
+
+	code, err := reader.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return true
+	}
+	deployed = len(code) > 0
+
+	p.mu.Lock()
+	_, raced := p.deployed[addr]
+	if p.deployed == nil {
+		p.deployed = make(map[common.Address]bool)
+	}
+	p.deployed[addr] = deployed
+	p.mu.Unlock()
+
+	if !deployed && !raced && p.OnMissing != nil {
+		p.OnMissing(addr)
+	}
+	return deployed
+}
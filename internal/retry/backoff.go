@@ -2,6 +2,9 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
 	"time"
 )
 
@@ -11,6 +14,9 @@ type Config struct {
 	InitialInterval time.Duration
 	MaxInterval time.Duration
 	Multiplier float64
+	// Retryable, if set, reports whether an error is worth retrying; Do
+	// returns the first error it rejects. Nil retries every error.
+	Retryable func(error) bool
 }
 
 
@@ -38,7 +44,7 @@ func Do(ctx context.Context, cfg Config, fn func() error) error {
 			return nil
 		}
 
-		if attempt == cfg.MaxRetries {
+		if attempt == cfg.MaxRetries || (cfg.Retryable != nil && !cfg.Retryable(lastErr)) {
 			break
 		}
 
@@ -58,33 +64,66 @@ func Do(ctx context.Context, cfg Config, fn func() error) error {
 }
 
 
-func Poll(ctx context.Context, interval time.Duration, timeout time.Duration, fn func() (bool, error)) error {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// ErrTimeout is returned (wrapped, together with context.DeadlineExceeded)
+// by Poll when its own timeout elapses. Cancellation or expiry of the parent
+// context is returned as the parent's ctx.Err() instead, and errors from fn
+// are returned unchanged, so callers can tell the three apart.
+var ErrTimeout = errors.New("timed out")
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// Poll calls fn every interval until it reports done, returns an error, or
+// timeout elapses. fn receives a context that is cancelled at the timeout.
+func Poll(ctx context.Context, interval time.Duration, timeout time.Duration, fn func(ctx context.Context) (bool, error)) error {
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	done, err := fn()
-	if err != nil {
+	// classify attributes a stop to the parent context or to our timeout
+	classify := func(err error) error {
+		if parentErr := ctx.Err(); parentErr != nil {
+			return parentErr
+		}
+		if pollCtx.Err() != nil {
+			return fmt.Errorf("%w after %s: %w", ErrTimeout, timeout, context.DeadlineExceeded)
+		}
 		return err
 	}
-	if done {
-		return nil
-	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	for {
+		done, err := fn(pollCtx)
+		if err != nil {
+			return classify(err)
+		}
+		if done {
+			return nil
+		}
+
 		select {
-		case <-ctx.Done():
-			return ctx.Err()
+		case <-pollCtx.Done():
+			return classify(pollCtx.Err())
 		case <-ticker.C:
-			done, err := fn()
-			if err != nil {
-				return err
-			}
-			if done {
-				return nil
-			}
 		}
 	}
 }
+
+// CalculateBackoff returns the next wait using decorrelated jitter: a random
+// duration between base and three times the previous wait, capped at max.
+// Pass prev == 0 for the first wait. The jitter keeps concurrent callers
+// from polling in lockstep.
+func CalculateBackoff(prev, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	if prev < base {
+		prev = base
+	}
+	upper := prev * 3
+	if max > 0 && upper > max {
+		upper = max
+	}
+	if upper <= base {
+		return base
+	}
+	return base + time.Duration(rand.Int63n(int64(upper-base)+1))
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCalculateBackoff(t *testing.T) {
+	base := time.Second
+	max := 10 * time.Second
+
+	prev := time.Duration(0)
+	for i := 0; i < 100; i++ {
+		next := CalculateBackoff(prev, base, max)
+		if next < base || next > max {
+			t.Fatalf("CalculateBackoff(%v) = %v, want within [%v, %v]", prev, next, base, max)
+		}
+		upper := 3 * prev
+		if upper < 3*base {
+			upper = 3 * base
+		}
+		if next > upper {
+			t.Fatalf("CalculateBackoff(%v) = %v, want <= %v", prev, next, upper)
+		}
+		prev = next
+	}
+
+	if got := CalculateBackoff(0, 0, max); got != 0 {
+		t.Errorf("CalculateBackoff with zero base = %v, want 0", got)
+	}
+	if got := CalculateBackoff(time.Minute, base, base); got != base {
+		t.Errorf("CalculateBackoff with max == base = %v, want %v", got, base)
+	}
+}
+
+func TestPoll_Errors(t *testing.T) {
+	notDone := func(context.Context) (bool, error) { return false, nil }
+
+	err := Poll(context.Background(), time.Millisecond, 20*time.Millisecond, notDone)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Poll() timeout error = %v, want ErrTimeout and DeadlineExceeded", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	err = Poll(ctx, time.Millisecond, time.Minute, notDone)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Poll() cancel error = %v, want context.Canceled", err)
+	}
+
+	parent, cancelParent := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelParent()
+	err = Poll(parent, time.Millisecond, time.Minute, notDone)
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Poll() parent deadline error = %v, want the parent's DeadlineExceeded", err)
+	}
+
+	statusErr := errors.New("status failed")
+	err = Poll(context.Background(), time.Millisecond, time.Minute, func(context.Context) (bool, error) { return false, statusErr })
+	if err != statusErr {
+		t.Errorf("Poll() fn error = %v, want %v", err, statusErr)
+	}
+
+	// an fn error caused by the poll timeout is reported as the timeout
+	err = Poll(context.Background(), time.Millisecond, 10*time.Millisecond, func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Poll() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestDo_Retryable(t *testing.T) {
+	transient := errors.New("connection reset")
+	permanent := errors.New("execution reverted")
+	cfg := Config{
+		MaxRetries:      5,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+		Retryable:       func(err error) bool { return err == transient },
+	}
+
+	calls := 0
+	err := Do(context.Background(), cfg, func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Do() = %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	err = Do(context.Background(), cfg, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want the permanent error after 1", err, calls)
+	}
+}
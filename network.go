@@ -64,17 +64,11 @@ func DetectNetwork(ctx context.Context, client *ethclient.Client) (Network, int6
 
 func NetworkFromChainID(chainID *big.Int) (Network, int64, error) {
 	id := chainID.Int64()
-
-	switch id {
-	case ChainIDMainnet:
-		return NetworkMainnet, id, nil
-	case ChainIDCalibration:
-		return NetworkCalibration, id, nil
-	case ChainIDDevnet:
-		return NetworkDevnet, id, nil
-	default:
+	network, ok := constants.NetworkForChainID(id)
+	if !ok {
 		return "", 0, fmt.Errorf("unsupported chain ID: %d", id)
 	}
+	return network, id, nil
 }
 
 func GetSPRegistryAddress(network Network) common.Address {
@@ -17,6 +17,8 @@ const (
 	EpochsPerMonth = constants.EpochsPerMonth
 )
 
+// SettlementFee is the native-token fee (0.0013 FIL) Settle sends when the
+// Payments contract has no NETWORK_FEE.
 var SettlementFee = big.NewInt(1300000000000000)
 
 var GenesisTimestamps = constants.GenesisTimestampsByChainID
@@ -24,10 +26,15 @@ var GenesisTimestamps = constants.GenesisTimestampsByChainID
 const PermitDeadline = 3600 * time.Second
 const TokenDecimals = 18
 
+type Clock = constants.Clock
+type ClockFunc = constants.ClockFunc
+
 var (
-	CurrentEpoch = constants.CurrentEpoch
-	EpochToTime  = constants.EpochToTime
-	TimeToEpoch  = constants.TimeToEpoch
+	SystemClock           = constants.SystemClock
+	CurrentEpoch          = constants.CurrentEpoch
+	CurrentEpochWithClock = constants.CurrentEpochWithClock
+	EpochToTime           = constants.EpochToTime
+	TimeToEpoch           = constants.TimeToEpoch
 )
 
 var PaymentsAddresses = map[int64]common.Address{
@@ -23,6 +23,21 @@ func TestCurrentEpoch(t *testing.T) {
 	}
 }
 
+func TestCurrentEpochWithClock(t *testing.T) {
+	fixed := time.Unix(GenesisTimestamps[314159]+100*30+15, 0)
+	clock := ClockFunc(func() time.Time { return fixed })
+
+	if got := CurrentEpochWithClock(314159, clock); got.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("CurrentEpochWithClock() = %v, want 100", got)
+	}
+	if got := CurrentEpochWithClock(999999, clock); got.Sign() != 0 {
+		t.Errorf("CurrentEpochWithClock() for unknown chain = %v, want 0", got)
+	}
+	if got, want := CurrentEpochWithClock(314, nil), CurrentEpoch(314); got.Cmp(want) < 0 {
+		t.Errorf("CurrentEpochWithClock(nil) = %v, want the system clock epoch %v", got, want)
+	}
+}
+
 func TestEpochToTime(t *testing.T) {
 	t.Run("should convert epoch 0 to genesis timestamp for mainnet", func(t *testing.T) {
 		genesis := EpochToTime(314, big.NewInt(0))
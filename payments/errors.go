@@ -0,0 +1,43 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrInsufficientFunds means the payments account has less available
+	// (unlocked) balance than the operation needs.
+	ErrInsufficientFunds = errors.New("insufficient available funds")
+	// ErrInsufficientAllowance means the payments contract is not approved to
+	// pull enough tokens from the wallet.
+	ErrInsufficientAllowance = errors.New("insufficient allowance")
+	// ErrInsufficientWalletBalance means the wallet holds fewer tokens than
+	// the operation needs.
+	ErrInsufficientWalletBalance = errors.New("insufficient wallet balance")
+	// ErrOperatorNotApproved means the operator has no approval to extend.
+	ErrOperatorNotApproved = errors.New("operator not approved")
+)
+
+// InsufficientAmountError carries the amounts behind one of the
+// ErrInsufficient* sentinels. It matches the sentinel with errors.Is.
+type InsufficientAmountError struct {
+	Err   error
+	Token Token
+	Have  *big.Int
+	Want  *big.Int
+}
+
+func (e *InsufficientAmountError) Error() string {
+	return fmt.Sprintf("%v: have %s, want %s", e.Err, e.Have, e.Want)
+}
+
+func (e *InsufficientAmountError) Unwrap() error {
+	return e.Err
+}
+
+// Shortfall returns how much more is needed, Want - Have.
+func (e *InsufficientAmountError) Shortfall() *big.Int {
+	return new(big.Int).Sub(e.Want, e.Have)
+}
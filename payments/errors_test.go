@@ -0,0 +1,37 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestInsufficientAmountError(t *testing.T) {
+	err := fmt.Errorf("failed to withdraw: %w", &InsufficientAmountError{
+		Err:   ErrInsufficientFunds,
+		Token: TokenUSDFC,
+		Have:  big.NewInt(40),
+		Want:  big.NewInt(100),
+	})
+
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Error("errors.Is(err, ErrInsufficientFunds) = false, want true")
+	}
+	if errors.Is(err, ErrInsufficientAllowance) {
+		t.Error("errors.Is(err, ErrInsufficientAllowance) = true, want false")
+	}
+
+	var amountErr *InsufficientAmountError
+	if !errors.As(err, &amountErr) {
+		t.Fatal("errors.As(err, *InsufficientAmountError) = false, want true")
+	}
+	if got := amountErr.Shortfall(); got.Cmp(big.NewInt(60)) != 0 {
+		t.Errorf("Shortfall() = %s, want 60", got)
+	}
+
+	want := "failed to withdraw: insufficient available funds: have 40, want 100"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -3,16 +3,35 @@ package payments
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/data-preservation-programs/go-synapse/constants"
 	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/data-preservation-programs/go-synapse/internal/retry"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
+	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// balancePollInterval is roughly a tenth of a Filecoin epoch.
+const balancePollInterval = 3 * time.Second
+
+// DefaultPageSize is the number of rails requested per
+// getRailsForPayerAndToken call.
+const DefaultPageSize = 100
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
 
 type Service struct {
 	client           *ethclient.Client
@@ -23,6 +42,47 @@ type Service struct {
 	paymentsAddress  common.Address
 	usdfcContract    *contracts.ERC20Contract
 	usdfcAddress     common.Address
+	pageSize         int
+	confirmations    uint64
+	approvalMu       sync.Mutex
+	transactor       *txutil.BaseTransactor
+}
+
+type ServiceOption func(*Service) error
+
+// WithPageSize sets how many rails GetRailsAsPayer requests per call.
+// Defaults to DefaultPageSize.
+func WithPageSize(n int) ServiceOption {
+	return func(s *Service) error {
+		if n <= 0 {
+			return fmt.Errorf("page size must be positive, got %d", n)
+		}
+		s.pageSize = n
+		return nil
+	}
+}
+
+// WithUSDFCAddress overrides the USDFC token address, which otherwise comes
+// from USDFCAddresses for the chain ID. Use it with custom deployments.
+func WithUSDFCAddress(addr common.Address) ServiceOption {
+	return func(s *Service) error {
+		if addr == (common.Address{}) {
+			return fmt.Errorf("USDFC address must not be zero")
+		}
+		s.usdfcAddress = addr
+		return nil
+	}
+}
+
+// WithSettlementConfirmations makes Settle wait until its transaction has n
+// confirmations before returning. The default of 0 returns as soon as the
+// transaction is submitted; payees settling high-value rails should set n > 0
+// so a reorg cannot unwind a settlement they have already acted on.
+func WithSettlementConfirmations(n uint64) ServiceOption {
+	return func(s *Service) error {
+		s.confirmations = n
+		return nil
+	}
 }
 
 
@@ -31,34 +91,58 @@ func NewService(
 	privateKey *ecdsa.PrivateKey,
 	chainID *big.Int,
 	paymentsAddress common.Address,
+	opts ...ServiceOption,
 ) (*Service, error) {
 	address := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	usdfcAddress, ok := USDFCAddresses[chainID.Int64()]
-	if !ok {
-		return nil, fmt.Errorf("USDFC address not found for chain ID %d", chainID.Int64())
+	s := &Service{
+		client:          client,
+		privateKey:      privateKey,
+		address:         address,
+		chainID:         chainID,
+		paymentsAddress: paymentsAddress,
+		pageSize:        DefaultPageSize,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.usdfcAddress == (common.Address{}) {
+		usdfcAddress, ok := USDFCAddresses[chainID.Int64()]
+		if !ok {
+			return nil, fmt.Errorf("USDFC address not found for chain ID %d", chainID.Int64())
+		}
+		s.usdfcAddress = usdfcAddress
+	}
+
+	transactor, err := txutil.NewBaseTransactor(privateKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	s.transactor = transactor
+
+	// a nil client is allowed for offline use and has nothing to check
+	if client != nil {
+		if err := contracts.CheckDeployed(context.Background(), client, "Payments", paymentsAddress); err != nil {
+			return nil, err
+		}
 	}
 
 	paymentsContract, err := contracts.NewPaymentsContract(paymentsAddress, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create payments contract: %w", err)
 	}
+	s.paymentsContract = paymentsContract
 
-	usdfcContract, err := contracts.NewERC20Contract(usdfcAddress, client)
+	usdfcContract, err := contracts.NewERC20Contract(s.usdfcAddress, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create USDFC contract: %w", err)
 	}
+	s.usdfcContract = usdfcContract
 
-	return &Service{
-		client:           client,
-		privateKey:       privateKey,
-		address:          address,
-		chainID:          chainID,
-		paymentsContract: paymentsContract,
-		paymentsAddress:  paymentsAddress,
-		usdfcContract:    usdfcContract,
-		usdfcAddress:     usdfcAddress,
-	}, nil
+	return s, nil
 }
 
 
@@ -77,9 +161,9 @@ func (s *Service) USDFCAddress() common.Address {
 }
 
 
-func (s *Service) Balance(ctx context.Context, token Token) (*big.Int, error) {
+func (s *Service) Balance(ctx context.Context, token Token, opts ...callopt.Option) (*big.Int, error) {
 	tokenAddr := s.tokenAddress(token)
-	funds, _, _, _, err := s.paymentsContract.Accounts(ctx, tokenAddr, s.address)
+	funds, _, _, _, err := s.paymentsContract.Accounts(ctx, tokenAddr, s.address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account balance: %w", err)
 	}
@@ -102,15 +186,17 @@ func (s *Service) WalletBalance(ctx context.Context, token Token) (*big.Int, err
 }
 
 
-func (s *Service) AccountInfo(ctx context.Context, token Token) (*AccountInfo, error) {
+// AccountInfo returns the caller's payments account state. Pass
+// callopt.WithBlock to read it as of a historical block.
+func (s *Service) AccountInfo(ctx context.Context, token Token, opts ...callopt.Option) (*AccountInfo, error) {
 	tokenAddr := s.tokenAddress(token)
 
-	funds, lockupCurrent, lockupRate, lockupLastSettled, err := s.paymentsContract.Accounts(ctx, tokenAddr, s.address)
+	funds, lockupCurrent, lockupRate, lockupLastSettled, err := s.paymentsContract.Accounts(ctx, tokenAddr, s.address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
-	fundedUntilEpoch, _, availableFunds, currentLockupRate, err := s.paymentsContract.GetAccountInfoIfSettled(ctx, tokenAddr, s.address)
+	fundedUntilEpoch, _, availableFunds, currentLockupRate, err := s.paymentsContract.GetAccountInfoIfSettled(ctx, tokenAddr, s.address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get settled account info: %w", err)
 	}
@@ -127,6 +213,84 @@ func (s *Service) AccountInfo(ctx context.Context, token Token) (*AccountInfo, e
 }
 
 
+// FundingRunsOutAt returns the epoch until which the account's lockup is
+// funded, as reported by getAccountInfoIfSettled, and the corresponding wall
+// clock time. An account with no lockup rate never runs out; the contract
+// reports a maximal epoch for it, and the returned time is zero. The time is
+// also zero on chains without a known genesis timestamp.
+func (s *Service) FundingRunsOutAt(ctx context.Context, token Token) (*big.Int, time.Time, error) {
+	tokenAddr := s.tokenAddress(token)
+
+	fundedUntilEpoch, _, _, _, err := s.paymentsContract.GetAccountInfoIfSettled(ctx, tokenAddr, s.address)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get settled account info: %w", err)
+	}
+
+	return fundedUntilEpoch, fundedUntilTime(s.chainID.Int64(), fundedUntilEpoch), nil
+}
+
+// fundedUntilTime converts a fundedUntilEpoch to wall clock time, returning
+// the zero time for epochs too large to represent (an unfunded-forever
+// account reports type(uint256).max).
+func fundedUntilTime(chainID int64, epoch *big.Int) time.Time {
+	if !epoch.IsInt64() || epoch.Int64() > math.MaxInt64/2/constants.EpochDurationSeconds {
+		return time.Time{}
+	}
+	return EpochToTime(chainID, epoch)
+}
+
+
+// Spendable returns the amount Withdraw would currently permit. The contract
+// settles the account's lockup before a withdrawal, so the lockup rate that
+// has accrued since LockupLastSettled counts against the balance:
+//
+//	available = max(funds - lockupCurrent, 0)
+//	accrued   = lockupRate * (epoch - lockupLastSettled)
+//	spendable = available - accrued             if accrued <= available
+//	          = available mod lockupRate        otherwise
+//
+// The second case mirrors the contract's partial settlement, which only
+// locks whole epochs of rate when the account cannot cover the full period.
+// Pass callopt.WithBlock to evaluate at a historical block.
+func (s *Service) Spendable(ctx context.Context, token Token, opts ...callopt.Option) (*big.Int, error) {
+	epoch := callopt.Apply(opts...).BlockNumber
+	if epoch == nil {
+		head, err := s.client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current epoch: %w", err)
+		}
+		epoch = new(big.Int).SetUint64(head)
+	}
+
+	tokenAddr := s.tokenAddress(token)
+	funds, lockupCurrent, lockupRate, lockupLastSettled, err := s.paymentsContract.Accounts(ctx, tokenAddr, s.address, callopt.WithBlock(epoch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	return spendableAt(funds, lockupCurrent, lockupRate, lockupLastSettled, epoch), nil
+}
+
+// spendableAt applies the formula documented on Spendable.
+func spendableAt(funds, lockupCurrent, lockupRate, lockupLastSettled, epoch *big.Int) *big.Int {
+	available := new(big.Int).Sub(funds, lockupCurrent)
+	if available.Sign() <= 0 {
+		return new(big.Int)
+	}
+
+	elapsed := new(big.Int).Sub(epoch, lockupLastSettled)
+	if lockupRate.Sign() == 0 || elapsed.Sign() <= 0 {
+		return available
+	}
+
+	accrued := new(big.Int).Mul(lockupRate, elapsed)
+	if accrued.Cmp(available) <= 0 {
+		return available.Sub(available, accrued)
+	}
+	return available.Mod(available, lockupRate)
+}
+
+
 func (s *Service) Allowance(ctx context.Context, token Token) (*big.Int, error) {
 	tokenAddr := s.tokenAddress(token)
 	tokenContract, err := contracts.NewERC20Contract(tokenAddr, s.client)
@@ -137,6 +301,19 @@ func (s *Service) Allowance(ctx context.Context, token Token) (*big.Int, error)
 	return tokenContract.Allowance(ctx, s.address, s.paymentsAddress)
 }
 
+// CheckAllowance returns an *InsufficientAmountError wrapping
+// ErrInsufficientAllowance if the payments contract may not pull amount.
+func (s *Service) CheckAllowance(ctx context.Context, amount *big.Int, token Token) error {
+	allowance, err := s.Allowance(ctx, token)
+	if err != nil {
+		return fmt.Errorf("failed to check allowance: %w", err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		return &InsufficientAmountError{Err: ErrInsufficientAllowance, Token: token, Have: allowance, Want: amount}
+	}
+	return nil
+}
+
 
 func (s *Service) Approve(ctx context.Context, amount *big.Int, token Token) (common.Hash, error) {
 	tokenAddr := s.tokenAddress(token)
@@ -162,6 +339,14 @@ func (s *Service) Approve(ctx context.Context, amount *big.Int, token Token) (co
 func (s *Service) Deposit(ctx context.Context, amount *big.Int, token Token, opts *DepositOptions) (common.Hash, error) {
 	tokenAddr := s.tokenAddress(token)
 
+	walletBalance, err := s.WalletBalance(ctx, token)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to check wallet balance: %w", err)
+	}
+	if walletBalance.Cmp(amount) < 0 {
+		return common.Hash{}, &InsufficientAmountError{Err: ErrInsufficientWalletBalance, Token: token, Have: walletBalance, Want: amount}
+	}
+
 	allowance, err := s.Allowance(ctx, token)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to check allowance: %w", err)
@@ -192,6 +377,28 @@ func (s *Service) Deposit(ctx context.Context, amount *big.Int, token Token, opt
 	return tx.Hash(), nil
 }
 
+// WaitForBalanceAtLeast polls the caller's payments balance until it reaches
+// minBalance, returning the balance observed. Use it after Deposit to wait
+// for the funds to land before starting an upload.
+func (s *Service) WaitForBalanceAtLeast(ctx context.Context, token Token, minBalance *big.Int, timeout time.Duration) (*big.Int, error) {
+	var balance *big.Int
+	err := retry.Poll(ctx, balancePollInterval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		balance, err = s.Balance(ctx, token)
+		if err != nil {
+			return false, err
+		}
+		return balance.Cmp(minBalance) >= 0, nil
+	})
+	if err != nil {
+		if balance != nil {
+			return balance, fmt.Errorf("balance %s did not reach %s: %w", balance, minBalance, err)
+		}
+		return nil, err
+	}
+	return balance, nil
+}
+
 
 func (s *Service) Withdraw(ctx context.Context, amount *big.Int, token Token) (common.Hash, error) {
 	tokenAddr := s.tokenAddress(token)
@@ -202,7 +409,7 @@ func (s *Service) Withdraw(ctx context.Context, amount *big.Int, token Token) (c
 	}
 
 	if info.AvailableFunds.Cmp(amount) < 0 {
-		return common.Hash{}, fmt.Errorf("insufficient available funds: have %s, want %s", info.AvailableFunds.String(), amount.String())
+		return common.Hash{}, &InsufficientAmountError{Err: ErrInsufficientFunds, Token: token, Have: info.AvailableFunds, Want: amount}
 	}
 
 	opts, err := s.transactOpts(ctx)
@@ -236,6 +443,50 @@ func (s *Service) ApproveService(ctx context.Context, operator common.Address, r
 }
 
 
+// ExtendServiceApproval raises an existing operator approval by
+// additionalRate and additionalLockup, keeping its max lockup period. It
+// reads the current allowances and sends the new absolute values, so callers
+// cannot shrink an approval by passing a stale total. Allowances saturate at
+// the uint256 maximum. Extensions through the same Service are serialized;
+// an approval change from elsewhere that is not yet mined is not seen.
+func (s *Service) ExtendServiceApproval(ctx context.Context, operator common.Address, additionalRate, additionalLockup *big.Int, token Token) (common.Hash, error) {
+	if additionalRate == nil {
+		additionalRate = new(big.Int)
+	}
+	if additionalLockup == nil {
+		additionalLockup = new(big.Int)
+	}
+	if additionalRate.Sign() < 0 || additionalLockup.Sign() < 0 {
+		return common.Hash{}, fmt.Errorf("approval increase must not be negative: rate %s, lockup %s", additionalRate, additionalLockup)
+	}
+
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+
+	current, err := s.ServiceApproval(ctx, operator, token)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !current.IsApproved {
+		return common.Hash{}, fmt.Errorf("%w: %s", ErrOperatorNotApproved, operator.Hex())
+	}
+
+	rateAllowance := extendAllowance(current.RateAllowance, additionalRate)
+	lockupAllowance := extendAllowance(current.LockupAllowance, additionalLockup)
+
+	return s.ApproveService(ctx, operator, rateAllowance, lockupAllowance, current.MaxLockupPeriod, token)
+}
+
+// extendAllowance returns current + additional, capped at the uint256
+// maximum.
+func extendAllowance(current, additional *big.Int) *big.Int {
+	sum := new(big.Int).Add(current, additional)
+	if sum.Cmp(maxUint256) > 0 {
+		return new(big.Int).Set(maxUint256)
+	}
+	return sum
+}
+
 func (s *Service) RevokeService(ctx context.Context, operator common.Address, token Token) (common.Hash, error) {
 	tokenAddr := s.tokenAddress(token)
 
@@ -294,15 +545,31 @@ func (s *Service) GetRail(ctx context.Context, railID *big.Int) (*RailView, erro
 	}, nil
 }
 
+// VerifyRailValidator reports whether the rail is validated by
+// expectedValidator. A rail pointed at another validator can have its
+// payments cut or redirected at settlement, so payees should check this
+// before calling Settle.
+func (s *Service) VerifyRailValidator(ctx context.Context, railID *big.Int, expectedValidator common.Address) (bool, error) {
+	rail, err := s.paymentsContract.GetRail(ctx, railID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get rail: %w", err)
+	}
+	return rail.Validator == expectedValidator, nil
+}
+
 
 func (s *Service) GetRailsAsPayer(ctx context.Context, token Token) ([]RailInfo, error) {
 	tokenAddr := s.tokenAddress(token)
 
 	var allRails []RailInfo
 	offset := big.NewInt(0)
-	limit := big.NewInt(100)
+	limit := big.NewInt(int64(s.pageSize))
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		results, nextOffset, _, err := s.paymentsContract.GetRailsForPayerAndToken(ctx, s.address, tokenAddr, offset, limit)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get rails: %w", err)
@@ -326,24 +593,225 @@ func (s *Service) GetRailsAsPayer(ctx context.Context, token Token) ([]RailInfo,
 }
 
 
+// NetworkFee reads the fee settleRail must be paid with from the Payments
+// contract.
+func (s *Service) NetworkFee(ctx context.Context) (*big.Int, error) {
+	fee, err := s.paymentsContract.NetworkFee(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network fee: %w", err)
+	}
+	return fee, nil
+}
+
+// TerminateRail terminates a rail, for example one paying for a data set
+// that has been deleted. The rail stops charging once its lockup period has
+// run out; Settle it after that to release the remaining lockup. The caller
+// must be the rail's payer or operator.
+func (s *Service) TerminateRail(ctx context.Context, railID *big.Int) (common.Hash, error) {
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := s.paymentsContract.TerminateRail(opts, railID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to terminate rail: %w", err)
+	}
+
+	return tx.Hash(), nil
+}
+
+// Settle settles a rail up to untilEpoch, paying the contract's current
+// NetworkFee, or SettlementFee on a deployment without NETWORK_FEE. An RPC
+// failure reading the fee is returned. With WithSettlementConfirmations set it
+// also waits for the transaction to reach that many confirmations and returns
+// its receipt.
 func (s *Service) Settle(ctx context.Context, railID, untilEpoch *big.Int) (*SettlementResult, error) {
+	fee, err := s.settlementValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	opts, err := s.transactOpts(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	opts.Value = SettlementFee
+	opts.Value = fee
 
 	tx, err := s.paymentsContract.SettleRail(opts, railID, untilEpoch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to settle rail: %w", err)
 	}
 
+	if s.confirmations == 0 {
+		return &SettlementResult{
+			Note:            fmt.Sprintf("Settlement transaction submitted: %s", tx.Hash().Hex()),
+			TransactionHash: tx.Hash(),
+		}, nil
+	}
+
+	receipt, err := txutil.WaitForConfirmation(ctx, s.client, tx.Hash(), s.confirmations, txutil.DefaultReceiptWaitConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm settlement %s: %w", tx.Hash().Hex(), err)
+	}
+
 	return &SettlementResult{
-		Note: fmt.Sprintf("Settlement transaction submitted: %s", tx.Hash().Hex()),
+		Note:            fmt.Sprintf("Settlement transaction confirmed: %s (%d confirmations)", tx.Hash().Hex(), s.confirmations),
+		TransactionHash: tx.Hash(),
+		Receipt:         receipt,
 	}, nil
 }
 
+// SettleRails settles each of railIDs up to untilEpoch, one transaction per
+// rail at the NetworkFee Settle would pay. settleRail checks its caller, so
+// the rails cannot be settled through an aggregating contract; instead the
+// transactions are sent back to back with consecutive nonces, without
+// waiting for each to be mined, and with WithSettlementConfirmations set
+// they are then confirmed concurrently. Results are in railIDs order and
+// carry the transaction hash only, as the settled amounts are not known
+// until the transaction runs. A rail whose transaction could not be sent has
+// a nil result; failures are returned together as one joined error, each
+// naming its rail, alongside the results that did succeed.
+func (s *Service) SettleRails(ctx context.Context, railIDs []*big.Int, untilEpoch *big.Int) ([]*SettlementResult, error) {
+	results := make([]*SettlementResult, len(railIDs))
+	if len(railIDs) == 0 {
+		return results, nil
+	}
+
+	fee, err := s.settlementValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.transactOpts(ctx); err != nil {
+		return nil, err
+	}
+
+	nonces := txutil.NewNonceManager(s.client, s.address)
+	errs := make([]error, len(railIDs))
+	for i, railID := range railIDs {
+		tx, err := s.sendSettlement(ctx, nonces, fee, railID, untilEpoch)
+		if err != nil {
+			errs[i] = fmt.Errorf("rail %s: %w", railID, err)
+			continue
+		}
+		results[i] = &SettlementResult{
+			Note:            fmt.Sprintf("Settlement transaction submitted: %s", tx.Hash().Hex()),
+			TransactionHash: tx.Hash(),
+		}
+	}
+
+	if s.confirmations > 0 {
+		var wg sync.WaitGroup
+		for i, result := range results {
+			if result == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, result *SettlementResult) {
+				defer wg.Done()
+				receipt, err := txutil.WaitForConfirmation(ctx, s.client, result.TransactionHash, s.confirmations, txutil.DefaultReceiptWaitConfig())
+				if err != nil {
+					errs[i] = fmt.Errorf("rail %s: failed to confirm settlement %s: %w", railIDs[i], result.TransactionHash.Hex(), err)
+					return
+				}
+				result.Note = fmt.Sprintf("Settlement transaction confirmed: %s (%d confirmations)", result.TransactionHash.Hex(), s.confirmations)
+				result.Receipt = receipt
+			}(i, result)
+		}
+		wg.Wait()
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// sendSettlement submits one settleRail transaction at the next nonce from
+// nonces.
+func (s *Service) sendSettlement(ctx context.Context, nonces *txutil.NonceManager, fee, railID, untilEpoch *big.Int) (*types.Transaction, error) {
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := nonces.GetNonce(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.Value = fee
+
+	tx, err := s.paymentsContract.SettleRail(opts, railID, untilEpoch)
+	if err != nil {
+		// the next rail refetches the pending nonce, so a gap is never left
+		nonces.MarkFailed(nonce)
+		return nil, fmt.Errorf("failed to settle rail: %w", err)
+	}
+	return tx, nil
+}
+
+// PreviewSettlement simulates Settle against the pending block, with the same
+// fee Settle would pay, and returns the amounts it would settle without
+// submitting a transaction. The result also carries the rail's validator so
+// it can be checked before settling.
+func (s *Service) PreviewSettlement(ctx context.Context, railID, untilEpoch *big.Int) (*SettlementResult, error) {
+	rail, err := s.paymentsContract.GetRail(ctx, railID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rail: %w", err)
+	}
+
+	fee, err := s.settlementValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.paymentsContract.SimulateSettleRail(ctx, s.address, fee, railID, untilEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview settlement: %w", err)
+	}
+
+	return &SettlementResult{
+		TotalSettledAmount:      result.TotalSettledAmount,
+		TotalNetPayeeAmount:     result.TotalNetPayeeAmount,
+		TotalOperatorCommission: result.TotalOperatorCommission,
+		TotalNetworkFee:         result.TotalNetworkFee,
+		FinalSettledEpoch:       result.FinalSettledEpoch,
+		Note:                    result.Note,
+		Validator:               rail.Validator,
+	}, nil
+}
+
+// settlementValue is the value Settle sends: the contract's NetworkFee, or
+// SettlementFee for a contract that predates NETWORK_FEE. Any other failure
+// to read the fee, such as a timeout or a dropped connection, is returned
+// rather than guessed around.
+func (s *Service) settlementValue(ctx context.Context) (*big.Int, error) {
+	fee, err := s.NetworkFee(ctx)
+	if err == nil {
+		return fee, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if txutil.IsRetryableError(err) || !networkFeeUnsupported(err) {
+		return nil, err
+	}
+	return new(big.Int).Set(SettlementFee), nil
+}
+
+// networkFeeUnsupported reports whether err from NetworkFee means the
+// contract has no NETWORK_FEE: the call reverted or returned nothing.
+func networkFeeUnsupported(err error) bool {
+	if errors.Is(err, contracts.ErrNoReturnData) {
+		return true
+	}
+	// revert data attached to the error means the call did execute
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
 func (s *Service) tokenAddress(token Token) common.Address {
 	switch token {
 	case TokenUSDFC:
@@ -355,11 +823,10 @@ func (s *Service) tokenAddress(token Token) common.Address {
 	}
 }
 
+// transactOpts returns a per-call copy of the transactor built in NewService.
 func (s *Service) transactOpts(ctx context.Context) (*bind.TransactOpts, error) {
-	opts, err := bind.NewKeyedTransactorWithChainID(s.privateKey, s.chainID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	if s.transactor == nil {
+		return nil, fmt.Errorf("failed to create transactor: no private key and chain ID configured")
 	}
-	opts.Context = ctx
-	return opts, nil
+	return s.transactor.Opts(ctx), nil
 }
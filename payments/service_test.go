@@ -0,0 +1,471 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestGetRailsAsPayer_CancelledContext(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a nil client would panic if the loop reached the contract
+	svc, err := NewService(nil, key, big.NewInt(constants.ChainIDCalibration), PaymentsAddresses[constants.ChainIDCalibration])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := svc.GetRailsAsPayer(ctx, TokenUSDFC); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetRailsAsPayer() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(constants.ChainIDCalibration)
+	addr := PaymentsAddresses[constants.ChainIDCalibration]
+
+	svc, err := NewService(nil, key, chainID, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.pageSize != DefaultPageSize {
+		t.Errorf("default pageSize = %d, want %d", svc.pageSize, DefaultPageSize)
+	}
+
+	svc, err = NewService(nil, key, chainID, addr, WithPageSize(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.pageSize != 25 {
+		t.Errorf("pageSize = %d, want 25", svc.pageSize)
+	}
+
+	if _, err := NewService(nil, key, chainID, addr, WithPageSize(-1)); err == nil {
+		t.Error("expected error for negative page size")
+	}
+}
+
+func TestWithUSDFCAddress(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a chain with no built-in USDFC deployment
+	chainID := big.NewInt(1)
+	addr := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	usdfc := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	if _, err := NewService(nil, key, chainID, addr); err == nil {
+		t.Error("expected error without a USDFC address for the chain")
+	}
+
+	svc, err := NewService(nil, key, chainID, addr, WithUSDFCAddress(usdfc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.USDFCAddress() != usdfc {
+		t.Errorf("USDFCAddress() = %s, want %s", svc.USDFCAddress(), usdfc)
+	}
+	if svc.PaymentsAddress() != addr {
+		t.Errorf("PaymentsAddress() = %s, want %s", svc.PaymentsAddress(), addr)
+	}
+
+	if _, err := NewService(nil, key, chainID, addr, WithUSDFCAddress(common.Address{})); err == nil {
+		t.Error("expected error for zero USDFC address")
+	}
+}
+
+func TestSpendableAt(t *testing.T) {
+	tests := []struct {
+		name                                       string
+		funds, lockupCurrent, rate, settled, epoch int64
+		want                                       int64
+	}{
+		{"no lockup", 1000, 0, 0, 0, 50, 1000},
+		{"settled this epoch", 1000, 300, 10, 50, 50, 700},
+		{"accrual covered", 1000, 300, 10, 40, 50, 600},
+		{"accrual exceeds balance", 1000, 300, 30, 0, 50, 10},
+		{"fully locked", 1000, 1000, 10, 40, 50, 0},
+		{"lockup above funds", 900, 1000, 10, 40, 50, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spendableAt(big.NewInt(tt.funds), big.NewInt(tt.lockupCurrent), big.NewInt(tt.rate), big.NewInt(tt.settled), big.NewInt(tt.epoch))
+			if got.Int64() != tt.want {
+				t.Errorf("spendableAt() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFundedUntilTime(t *testing.T) {
+	chainID := int64(constants.ChainIDCalibration)
+	epoch := big.NewInt(1000)
+	if got, want := fundedUntilTime(chainID, epoch), EpochToTime(chainID, epoch); !got.Equal(want) {
+		t.Errorf("fundedUntilTime(1000) = %v, want %v", got, want)
+	}
+
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	if got := fundedUntilTime(chainID, maxUint256); !got.IsZero() {
+		t.Errorf("fundedUntilTime(max) = %v, want zero time", got)
+	}
+}
+
+// paymentsAPI serves eth_call for the Payments contract: NETWORK_FEE returns
+// fee (or reverts if fee is nil), getRail returns a rail with validator, and
+// settleRail returns settled, recording the call's value and block tag.
+// operatorApprovals reports approval, or no approval if it is nil.
+type paymentsAPI struct {
+	fee       *big.Int
+	feeErr    error
+	feeEmpty  bool
+	validator common.Address
+	approval  *OperatorApproval
+	settled   []byte
+	lastValue *hexutil.Big
+	lastBlock string
+}
+
+// GetCode reports code at every address, so the deployment check passes.
+func (a *paymentsAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return hexutil.Bytes{0x60, 0x80}
+}
+
+func (a *paymentsAPI) Call(args struct {
+	Input hexutil.Bytes `json:"input"`
+	Value *hexutil.Big  `json:"value"`
+}, block string) (hexutil.Bytes, error) {
+	parsed, err := abi.JSON(strings.NewReader(contracts.PaymentsABIJSON))
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(args.Input)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "NETWORK_FEE":
+		if a.feeErr != nil {
+			return nil, a.feeErr
+		}
+		if a.feeEmpty {
+			return hexutil.Bytes{}, nil
+		}
+		if a.fee == nil {
+			return nil, errors.New("execution reverted")
+		}
+		return common.LeftPadBytes(a.fee.Bytes(), 32), nil
+	case "getRail":
+		return method.Outputs.Pack(testRail{
+			Validator:         a.validator,
+			PaymentRate:       big.NewInt(0),
+			LockupPeriod:      big.NewInt(0),
+			LockupFixed:       big.NewInt(0),
+			SettledUpTo:       big.NewInt(0),
+			EndEpoch:          big.NewInt(0),
+			CommissionRateBps: big.NewInt(0),
+		})
+	case "settleRail":
+		a.lastValue, a.lastBlock = args.Value, block
+		return a.settled, nil
+	case "operatorApprovals":
+		ap := a.approval
+		if ap == nil {
+			zero := big.NewInt(0)
+			ap = &OperatorApproval{RateAllowance: zero, LockupAllowance: zero, RateUsed: zero, LockupUsed: zero, MaxLockupPeriod: zero}
+		}
+		return method.Outputs.Pack(ap.IsApproved, ap.RateAllowance, ap.LockupAllowance, ap.RateUsed, ap.LockupUsed, ap.MaxLockupPeriod)
+	}
+	return nil, errors.New("execution reverted")
+}
+
+// testRail matches the getRail output tuple for packing.
+type testRail struct {
+	Token               common.Address
+	From                common.Address
+	To                  common.Address
+	Operator            common.Address
+	Validator           common.Address
+	PaymentRate         *big.Int
+	LockupPeriod        *big.Int
+	LockupFixed         *big.Int
+	SettledUpTo         *big.Int
+	EndEpoch            *big.Int
+	CommissionRateBps   *big.Int
+	ServiceFeeRecipient common.Address
+}
+
+func newPaymentsTestService(t *testing.T, api any) *Service {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	svc, err := NewService(ethclient.NewClient(rpcClient), key, big.NewInt(constants.ChainIDCalibration), PaymentsAddresses[constants.ChainIDCalibration])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestSettlementValue(t *testing.T) {
+	api := &paymentsAPI{fee: big.NewInt(2000000000000000)}
+	svc := newPaymentsTestService(t, api)
+
+	fee, err := svc.settlementValue(context.Background())
+	if err != nil {
+		t.Fatalf("settlementValue() error = %v", err)
+	}
+	if fee.Cmp(api.fee) != 0 {
+		t.Errorf("settlementValue() = %s, want %s", fee, api.fee)
+	}
+
+	// a contract without NETWORK_FEE falls back to the fixed fee
+	api.fee = nil
+	if _, err := svc.NetworkFee(context.Background()); err == nil {
+		t.Error("NetworkFee() expected error")
+	}
+	fee, err = svc.settlementValue(context.Background())
+	if err != nil {
+		t.Fatalf("settlementValue() error = %v", err)
+	}
+	if fee.Cmp(SettlementFee) != 0 {
+		t.Errorf("settlementValue() = %s, want %s", fee, SettlementFee)
+	}
+
+	// so does one that returns nothing for it
+	api.feeEmpty = true
+	fee, err = svc.settlementValue(context.Background())
+	if err != nil {
+		t.Fatalf("settlementValue() error = %v", err)
+	}
+	if fee.Cmp(SettlementFee) != 0 {
+		t.Errorf("settlementValue() = %s, want %s", fee, SettlementFee)
+	}
+
+	// a transient RPC failure is returned rather than paying a guessed fee
+	api.feeErr = errors.New("read tcp 10.0.0.1:443: i/o timeout")
+	if _, err := svc.settlementValue(context.Background()); err == nil || !strings.Contains(err.Error(), "i/o timeout") {
+		t.Errorf("settlementValue() error = %v, want the transient RPC error", err)
+	}
+	api.feeErr = errors.New("429 Too Many Requests")
+	if _, err := svc.settlementValue(context.Background()); err == nil {
+		t.Error("settlementValue() expected error for a rate-limited call")
+	}
+	api.feeErr = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.settlementValue(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("settlementValue() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestPreviewSettlement(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(contracts.PaymentsABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settled, err := parsed.Methods["settleRail"].Outputs.Pack(
+		big.NewInt(1000), big.NewInt(900), big.NewInt(90), big.NewInt(10), big.NewInt(500), "settled",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	api := &paymentsAPI{fee: big.NewInt(2000000000000000), validator: validator, settled: settled}
+	svc := newPaymentsTestService(t, api)
+
+	result, err := svc.PreviewSettlement(context.Background(), big.NewInt(7), big.NewInt(500))
+	if err != nil {
+		t.Fatalf("PreviewSettlement() error = %v", err)
+	}
+	if result.TotalSettledAmount.Int64() != 1000 || result.TotalNetPayeeAmount.Int64() != 900 ||
+		result.TotalOperatorCommission.Int64() != 90 || result.TotalNetworkFee.Int64() != 10 ||
+		result.FinalSettledEpoch.Int64() != 500 || result.Note != "settled" || result.Validator != validator {
+		t.Errorf("PreviewSettlement() = %+v", result)
+	}
+	if api.lastBlock != "pending" {
+		t.Errorf("settleRail simulated at %q, want pending", api.lastBlock)
+	}
+	if api.lastValue == nil || api.lastValue.ToInt().Cmp(api.fee) != 0 {
+		t.Errorf("settleRail simulated with value %v, want %s", api.lastValue, api.fee)
+	}
+}
+
+// settleAPI extends paymentsAPI with what sending transactions needs. Gas
+// estimation reverts for failRail, and the pending nonce counts the
+// transactions sent so far, as a node's mempool would.
+type settleAPI struct {
+	*paymentsAPI
+	failRail int64
+	mu       sync.Mutex
+	sent     []*types.Transaction
+}
+
+func (a *settleAPI) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return hexutil.Uint64(5 + len(a.sent))
+}
+
+func (a *settleAPI) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(100))
+}
+
+func (a *settleAPI) EstimateGas(args struct {
+	Input hexutil.Bytes `json:"input"`
+}) (hexutil.Uint64, error) {
+	if len(args.Input) >= 36 && new(big.Int).SetBytes(args.Input[4:36]).Int64() == a.failRail {
+		return 0, errors.New("execution reverted")
+	}
+	return 100000, nil
+}
+
+func (a *settleAPI) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sent = append(a.sent, tx)
+	return tx.Hash(), nil
+}
+
+func TestSettleRails(t *testing.T) {
+	api := &settleAPI{paymentsAPI: &paymentsAPI{fee: big.NewInt(2000000000000000)}, failRail: 13}
+	svc := newPaymentsTestService(t, api)
+
+	railIDs := []*big.Int{big.NewInt(1), big.NewInt(13), big.NewInt(2)}
+	results, err := svc.SettleRails(context.Background(), railIDs, big.NewInt(500))
+	if err == nil || !strings.Contains(err.Error(), "rail 13") {
+		t.Fatalf("SettleRails() error = %v, want failure for rail 13", err)
+	}
+	if len(results) != 3 || results[0] == nil || results[1] != nil || results[2] == nil {
+		t.Fatalf("SettleRails() results = %+v, want rails 1 and 2 settled", results)
+	}
+
+	if len(api.sent) != 2 {
+		t.Fatalf("sent %d transactions, want 2", len(api.sent))
+	}
+	for i, tx := range api.sent {
+		if tx.Nonce() != uint64(5+i) {
+			t.Errorf("transaction %d nonce = %d, want %d", i, tx.Nonce(), 5+i)
+		}
+		if tx.Value().Cmp(api.fee) != 0 {
+			t.Errorf("transaction %d value = %s, want %s", i, tx.Value(), api.fee)
+		}
+		if tx.Hash() != results[i*2].TransactionHash {
+			t.Errorf("transaction %d hash does not match its result", i)
+		}
+	}
+
+	results, err = svc.SettleRails(context.Background(), nil, big.NewInt(500))
+	if err != nil || len(results) != 0 {
+		t.Errorf("SettleRails(nil) = %v, %v", results, err)
+	}
+}
+
+func TestVerifyRailValidator(t *testing.T) {
+	validator := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	svc := newPaymentsTestService(t, &paymentsAPI{validator: validator})
+
+	ok, err := svc.VerifyRailValidator(context.Background(), big.NewInt(1), validator)
+	if err != nil || !ok {
+		t.Errorf("VerifyRailValidator(expected) = %v, %v; want true", ok, err)
+	}
+
+	rogue := common.HexToAddress("0x4000000000000000000000000000000000000004")
+	ok, err = svc.VerifyRailValidator(context.Background(), big.NewInt(1), rogue)
+	if err != nil || ok {
+		t.Errorf("VerifyRailValidator(rogue) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestExtendAllowance(t *testing.T) {
+	if got := extendAllowance(big.NewInt(100), big.NewInt(50)); got.Cmp(big.NewInt(150)) != 0 {
+		t.Errorf("extendAllowance(100, 50) = %s, want 150", got)
+	}
+	if got := extendAllowance(maxUint256, big.NewInt(1)); got.Cmp(maxUint256) != 0 {
+		t.Errorf("extendAllowance(max, 1) = %s, want max", got)
+	}
+}
+
+func TestExtendServiceApproval_Errors(t *testing.T) {
+	svc := newPaymentsTestService(t, &paymentsAPI{})
+	operator := common.HexToAddress("0x1234")
+	ctx := context.Background()
+
+	if _, err := svc.ExtendServiceApproval(ctx, operator, big.NewInt(-1), nil, TokenUSDFC); err == nil {
+		t.Error("ExtendServiceApproval() expected error for negative rate")
+	}
+	if _, err := svc.ExtendServiceApproval(ctx, operator, big.NewInt(10), big.NewInt(10), TokenUSDFC); !errors.Is(err, ErrOperatorNotApproved) {
+		t.Errorf("ExtendServiceApproval() error = %v, want ErrOperatorNotApproved", err)
+	}
+}
+
+// emptyChainAPI is a chain with no contract code anywhere.
+type emptyChainAPI struct{}
+
+func (emptyChainAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return nil
+}
+
+func (emptyChainAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(constants.ChainIDMainnet))
+}
+
+func TestNewService_NotDeployed(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", emptyChainAPI{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	// a calibration address against a chain where it has no code
+	_, err = NewService(ethclient.NewClient(rpcClient), key, big.NewInt(constants.ChainIDCalibration), PaymentsAddresses[constants.ChainIDCalibration])
+	if !errors.Is(err, contracts.ErrContractNotDeployed) {
+		t.Fatalf("NewService() error = %v, want ErrContractNotDeployed", err)
+	}
+	if !strings.Contains(err.Error(), "chain 314") {
+		t.Errorf("NewService() error = %v, want it to name the connected chain", err)
+	}
+}
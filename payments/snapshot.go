@@ -0,0 +1,66 @@
+package payments
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+)
+
+// AccountSnapshot is a JSON-friendly view of AccountInfo. Amounts and epochs
+// are base-10 strings so uint256 values survive JSON decoders that parse
+// numbers as float64.
+type AccountSnapshot struct {
+	Network           constants.Network `json:"network"`
+	Funds             string            `json:"funds"`
+	LockupCurrent     string            `json:"lockupCurrent"`
+	LockupRate        string            `json:"lockupRate"`
+	LockupLastSettled string            `json:"lockupLastSettled"`
+	AvailableFunds    string            `json:"availableFunds"`
+	CurrentLockupRate string            `json:"currentLockupRate"`
+	// Spendable is what Withdraw would permit at Epoch; see Service.Spendable.
+	Spendable        string `json:"spendable"`
+	FundedUntilEpoch string `json:"fundedUntilEpoch"`
+	// FundedUntil is the wall clock time of FundedUntilEpoch, zero if the
+	// account never runs out or the network has no known genesis timestamp.
+	FundedUntil time.Time `json:"fundedUntil"`
+	// Epoch is the chain epoch, derived from the wall clock, the snapshot was
+	// computed at.
+	Epoch string `json:"epoch"`
+}
+
+// Snapshot converts the account info to an AccountSnapshot for network,
+// computing Spendable at the current epoch according to clock. A nil clock
+// means constants.SystemClock.
+func (a *AccountInfo) Snapshot(network constants.Network, clock constants.Clock) AccountSnapshot {
+	chainID := constants.NetworkChainIDs[network]
+	epoch := constants.CurrentEpochWithClock(chainID, clock)
+
+	snap := AccountSnapshot{
+		Network:           network,
+		Funds:             decimalString(a.Funds),
+		LockupCurrent:     decimalString(a.LockupCurrent),
+		LockupRate:        decimalString(a.LockupRate),
+		LockupLastSettled: decimalString(a.LockupLastSettled),
+		AvailableFunds:    decimalString(a.AvailableFunds),
+		CurrentLockupRate: decimalString(a.CurrentLockupRate),
+		Spendable:         "0",
+		FundedUntilEpoch:  decimalString(a.FundedUntilEpoch),
+		Epoch:             epoch.String(),
+	}
+	if a.Funds != nil && a.LockupCurrent != nil && a.LockupRate != nil && a.LockupLastSettled != nil {
+		snap.Spendable = spendableAt(a.Funds, a.LockupCurrent, a.LockupRate, a.LockupLastSettled, epoch).String()
+	}
+	if a.FundedUntilEpoch != nil {
+		snap.FundedUntil = fundedUntilTime(chainID, a.FundedUntilEpoch)
+	}
+	return snap
+}
+
+// decimalString formats v in base 10, treating nil as zero.
+func decimalString(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
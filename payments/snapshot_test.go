@@ -0,0 +1,52 @@
+package payments
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+)
+
+func TestAccountInfoSnapshot(t *testing.T) {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	info := &AccountInfo{
+		Funds:             big.NewInt(1000),
+		LockupCurrent:     big.NewInt(200),
+		LockupRate:        big.NewInt(10),
+		LockupLastSettled: big.NewInt(100),
+		FundedUntilEpoch:  big.NewInt(180),
+		AvailableFunds:    big.NewInt(800),
+		CurrentLockupRate: big.NewInt(10),
+	}
+
+	at150 := constants.ClockFunc(func() time.Time {
+		return EpochToTime(constants.ChainIDCalibration, big.NewInt(150))
+	})
+	snap := info.Snapshot(constants.NetworkCalibration, at150)
+	if snap.Spendable != "300" {
+		t.Errorf("Spendable = %s, want 300", snap.Spendable)
+	}
+	if snap.Funds != "1000" || snap.Epoch != "150" || snap.Network != constants.NetworkCalibration {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	if want := EpochToTime(constants.ChainIDCalibration, big.NewInt(180)); !snap.FundedUntil.Equal(want) {
+		t.Errorf("FundedUntil = %v, want %v", snap.FundedUntil, want)
+	}
+
+	info.FundedUntilEpoch = maxUint256
+	snap = info.Snapshot(constants.NetworkCalibration, at150)
+	if !snap.FundedUntil.IsZero() {
+		t.Errorf("FundedUntil for max epoch = %v, want zero time", snap.FundedUntil)
+	}
+
+	buf, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), `"fundedUntilEpoch":"`+maxUint256.String()+`"`) {
+		t.Errorf("JSON = %s, want fundedUntilEpoch as a decimal string", buf)
+	}
+}
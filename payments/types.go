@@ -4,6 +4,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 
@@ -56,6 +57,13 @@ type SettlementResult struct {
 	TotalNetworkFee        *big.Int
 	FinalSettledEpoch      *big.Int
 	Note                   string
+	// Validator is the rail's validator contract; set by PreviewSettlement
+	// only. The zero address means the rail settles without validation.
+	Validator              common.Address
+	// TransactionHash and Receipt are set by Settle; Receipt only when it
+	// waits for confirmations.
+	TransactionHash        common.Hash
+	Receipt                *types.Receipt
 }
 
 
@@ -2,10 +2,13 @@ package pdp
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -31,10 +34,16 @@ type AuthHelper struct {
 }
 
 // NewAuthHelper builds an AuthHelper bound to the given signer, payer
-// address, FWSS contract address, and chainID. The address is the
+// address, FWSS contract address, and chainID. chainID is the EIP-712
+// domain chainId the FWSS contract verifies against; it is normally the
+// connected chain's ID but may differ where the contract pins its domain
+// to another chain. The address is the
 // recovered signer of every signature this helper produces; passing a
 // mismatched (signDigest, address) pair results in signatures that
-// FWSS will reject at eth_call time.
+// FWSS will reject at eth_call time. signDigest may be nil for a
+// digest-only helper: the *Digest methods then return what to sign with
+// external tooling, and the resulting signature (V as 27/28) is passed to
+// the Encode* extraData helpers.
 func NewAuthHelper(signDigest SignDigestFunc, address common.Address, warmStorageAddr common.Address, chainID *big.Int) *AuthHelper {
 	return &AuthHelper{
 		signDigest:         signDigest,
@@ -65,6 +74,14 @@ func (a *AuthHelper) Address() common.Address {
 	return a.address
 }
 
+// ChainID returns the EIP-712 domain chainId the helper signs for.
+func (a *AuthHelper) ChainID() *big.Int {
+	if a.chainID == nil {
+		return nil
+	}
+	return new(big.Int).Set(a.chainID)
+}
+
 var eip712Types = apitypes.Types{
 	"EIP712Domain": {
 		{Name: "name", Type: "string"},
@@ -104,6 +121,30 @@ var eip712Types = apitypes.Types{
 }
 
 func (a *AuthHelper) SignCreateDataSet(clientDataSetID *big.Int, payee common.Address, metadata []MetadataEntry) (*AuthSignature, error) {
+	message, err := CreateDataSetMessage(clientDataSetID, payee, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return a.signTypedData("CreateDataSet", message)
+}
+
+// CreateDataSetDigest returns the EIP-712 digest SignCreateDataSet would
+// sign, for callers that sign with external tooling. It needs no signer.
+func (a *AuthHelper) CreateDataSetDigest(clientDataSetID *big.Int, payee common.Address, metadata []MetadataEntry) (common.Hash, error) {
+	message, err := CreateDataSetMessage(clientDataSetID, payee, metadata)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return a.typedDataDigest("CreateDataSet", message)
+}
+
+// CreateDataSetMessage builds the CreateDataSet EIP-712 message, e.g. for
+// TypedDataJSON.
+func CreateDataSetMessage(clientDataSetID *big.Int, payee common.Address, metadata []MetadataEntry) (apitypes.TypedDataMessage, error) {
+	if err := validateMetadataCount(metadata, MaxDataSetMetadataKeys); err != nil {
+		return nil, err
+	}
+
 	metadataArray := make([]interface{}, len(metadata))
 	for i, m := range metadata {
 		metadataArray[i] = map[string]interface{}{
@@ -117,11 +158,29 @@ func (a *AuthHelper) SignCreateDataSet(clientDataSetID *big.Int, payee common.Ad
 		"payee":           payee.Hex(),
 		"metadata":        metadataArray,
 	}
-
-	return a.signTypedData("CreateDataSet", message)
+	return message, nil
 }
 
 func (a *AuthHelper) SignAddPieces(clientDataSetID, nonce *big.Int, pieceCIDs []cid.Cid, metadata [][]MetadataEntry) (*AuthSignature, error) {
+	message, err := AddPiecesMessage(clientDataSetID, nonce, pieceCIDs, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return a.signTypedData("AddPieces", message)
+}
+
+// AddPiecesDigest returns the EIP-712 digest SignAddPieces would sign.
+func (a *AuthHelper) AddPiecesDigest(clientDataSetID, nonce *big.Int, pieceCIDs []cid.Cid, metadata [][]MetadataEntry) (common.Hash, error) {
+	message, err := AddPiecesMessage(clientDataSetID, nonce, pieceCIDs, metadata)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return a.typedDataDigest("AddPieces", message)
+}
+
+// AddPiecesMessage builds the AddPieces EIP-712 message. metadata may be
+// nil; otherwise it must have one entry per piece.
+func AddPiecesMessage(clientDataSetID, nonce *big.Int, pieceCIDs []cid.Cid, metadata [][]MetadataEntry) (apitypes.TypedDataMessage, error) {
 	if len(metadata) == 0 {
 		metadata = make([][]MetadataEntry, len(pieceCIDs))
 		for i := range metadata {
@@ -131,11 +190,16 @@ func (a *AuthHelper) SignAddPieces(clientDataSetID, nonce *big.Int, pieceCIDs []
 	if len(metadata) != len(pieceCIDs) {
 		return nil, fmt.Errorf("metadata length (%d) must match pieceCIDs length (%d)", len(metadata), len(pieceCIDs))
 	}
+	for i, meta := range metadata {
+		if err := validateMetadataCount(meta, MaxPieceMetadataKeys); err != nil {
+			return nil, fmt.Errorf("piece %d: %w", i, err)
+		}
+	}
 
 	pieceData := make([]interface{}, len(pieceCIDs))
 	for i, c := range pieceCIDs {
 		pieceData[i] = map[string]interface{}{
-			"data": c.Bytes(),
+			"data": hexutil.Bytes(c.Bytes()),
 		}
 	}
 
@@ -160,55 +224,96 @@ func (a *AuthHelper) SignAddPieces(clientDataSetID, nonce *big.Int, pieceCIDs []
 		"pieceData":       pieceData,
 		"pieceMetadata":   pieceMetadata,
 	}
+	return message, nil
+}
 
-	return a.signTypedData("AddPieces", message)
+// SignCreateDataSetAndAddPieces signs both halves of a combined create-and-add
+// (a CreateDataSet and an AddPieces message, with the helper's address as
+// payer) and returns the abi.encode(bytes,bytes) extraData the provider's
+// create-and-add endpoint expects. pieceMetadata may be nil; otherwise it
+// must have one entry per piece.
+func (a *AuthHelper) SignCreateDataSetAndAddPieces(clientDataSetID *big.Int, payee common.Address, nonce *big.Int, pieceCIDs []cid.Cid, dataSetMetadata []MetadataEntry, pieceMetadata [][]MetadataEntry) (string, error) {
+	if dataSetMetadata == nil {
+		dataSetMetadata = []MetadataEntry{}
+	}
+	if len(pieceMetadata) == 0 {
+		pieceMetadata = make([][]MetadataEntry, len(pieceCIDs))
+		for i := range pieceMetadata {
+			pieceMetadata[i] = []MetadataEntry{}
+		}
+	}
+
+	createSig, err := a.SignCreateDataSet(clientDataSetID, payee, dataSetMetadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign create data set: %w", err)
+	}
+	createExtra, err := EncodeDataSetCreateData(a.address, clientDataSetID, dataSetMetadata, createSig.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	addSig, err := a.SignAddPieces(clientDataSetID, nonce, pieceCIDs, pieceMetadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign add pieces: %w", err)
+	}
+	addExtra, err := EncodeAddPiecesExtraData(nonce, pieceMetadata, addSig.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	return EncodeCreateDataSetAndAddPiecesExtraData(createExtra, addExtra)
 }
 
 func (a *AuthHelper) SignSchedulePieceRemovals(clientDataSetID *big.Int, pieceIDs []*big.Int) (*AuthSignature, error) {
+	return a.signTypedData("SchedulePieceRemovals", SchedulePieceRemovalsMessage(clientDataSetID, pieceIDs))
+}
+
+// SchedulePieceRemovalsDigest returns the EIP-712 digest
+// SignSchedulePieceRemovals would sign.
+func (a *AuthHelper) SchedulePieceRemovalsDigest(clientDataSetID *big.Int, pieceIDs []*big.Int) (common.Hash, error) {
+	return a.typedDataDigest("SchedulePieceRemovals", SchedulePieceRemovalsMessage(clientDataSetID, pieceIDs))
+}
+
+// SchedulePieceRemovalsMessage builds the SchedulePieceRemovals EIP-712
+// message.
+func SchedulePieceRemovalsMessage(clientDataSetID *big.Int, pieceIDs []*big.Int) apitypes.TypedDataMessage {
 	pieceIDsArray := make([]interface{}, len(pieceIDs))
 	for i, id := range pieceIDs {
 		pieceIDsArray[i] = (*math.HexOrDecimal256)(id)
 	}
 
-	message := apitypes.TypedDataMessage{
+	return apitypes.TypedDataMessage{
 		"clientDataSetId": (*math.HexOrDecimal256)(clientDataSetID),
 		"pieceIds":        pieceIDsArray,
 	}
-
-	return a.signTypedData("SchedulePieceRemovals", message)
 }
 
 func (a *AuthHelper) SignDeleteDataSet(clientDataSetID *big.Int) (*AuthSignature, error) {
-	message := apitypes.TypedDataMessage{
-		"clientDataSetId": (*math.HexOrDecimal256)(clientDataSetID),
-	}
+	return a.signTypedData("DeleteDataSet", DeleteDataSetMessage(clientDataSetID))
+}
 
-	return a.signTypedData("DeleteDataSet", message)
+// DeleteDataSetDigest returns the EIP-712 digest SignDeleteDataSet would sign.
+func (a *AuthHelper) DeleteDataSetDigest(clientDataSetID *big.Int) (common.Hash, error) {
+	return a.typedDataDigest("DeleteDataSet", DeleteDataSetMessage(clientDataSetID))
 }
 
-func (a *AuthHelper) signTypedData(primaryType string, message apitypes.TypedDataMessage) (*AuthSignature, error) {
-	typedData := apitypes.TypedData{
-		Types:       eip712Types,
-		PrimaryType: primaryType,
-		Domain:      a.domain,
-		Message:     message,
+// DeleteDataSetMessage builds the DeleteDataSet EIP-712 message.
+func DeleteDataSetMessage(clientDataSetID *big.Int) apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"clientDataSetId": (*math.HexOrDecimal256)(clientDataSetID),
 	}
+}
 
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash domain: %w", err)
+func (a *AuthHelper) signTypedData(primaryType string, message apitypes.TypedDataMessage) (*AuthSignature, error) {
+	if a.signDigest == nil {
+		return nil, fmt.Errorf("auth helper has no signer; use the Digest methods and sign externally")
 	}
 
-	messageHash, err := typedData.HashStruct(primaryType, message)
+	signedData, err := a.typedDataDigest(primaryType, message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash message: %w", err)
+		return nil, err
 	}
 
-	rawData := []byte{0x19, 0x01}
-	rawData = append(rawData, domainSeparator...)
-	rawData = append(rawData, messageHash...)
-	signedData := crypto.Keccak256Hash(rawData)
-
 	signature, err := a.signDigest(signedData.Bytes())
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign: %w", err)
@@ -233,3 +338,70 @@ func (a *AuthHelper) signTypedData(primaryType string, message apitypes.TypedDat
 		SignedData: signedData,
 	}, nil
 }
+
+// TypedDataJSON returns the EIP-712 typed data for primaryType and message
+// in the eth_signTypedData_v4 JSON format (domain, types, primaryType and
+// message), for signing with a wallet or hardware device that never hands
+// its key to this process. Only the types primaryType references are
+// included. Build message with CreateDataSetMessage, AddPiecesMessage and
+// friends; the resulting signature is passed to the Encode* extraData
+// helpers as usual.
+func (a *AuthHelper) TypedDataJSON(primaryType string, message apitypes.TypedDataMessage) ([]byte, error) {
+	if _, ok := eip712Types[primaryType]; !ok || primaryType == "EIP712Domain" {
+		return nil, fmt.Errorf("unknown primary type %q", primaryType)
+	}
+
+	types := apitypes.Types{"EIP712Domain": eip712Types["EIP712Domain"]}
+	pending := []string{primaryType}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if _, seen := types[name]; seen {
+			continue
+		}
+		types[name] = eip712Types[name]
+		for _, field := range eip712Types[name] {
+			if dep := strings.TrimSuffix(field.Type, "[]"); eip712Types[dep] != nil {
+				pending = append(pending, dep)
+			}
+		}
+	}
+
+	typedData := apitypes.TypedData{
+		Types:       types,
+		PrimaryType: primaryType,
+		Domain:      a.domain,
+		Message:     message,
+	}
+	data, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal typed data: %w", err)
+	}
+	return data, nil
+}
+
+// typedDataDigest returns keccak256(0x1901 || domainSeparator || hashStruct(message)),
+// the digest FWSS recovers the signer from.
+func (a *AuthHelper) typedDataDigest(primaryType string, message apitypes.TypedDataMessage) (common.Hash, error) {
+	typedData := apitypes.TypedData{
+		Types:       eip712Types,
+		PrimaryType: primaryType,
+		Domain:      a.domain,
+		Message:     message,
+	}
+
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash domain: %w", err)
+	}
+
+	messageHash, err := typedData.HashStruct(primaryType, message)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	rawData := []byte{0x19, 0x01}
+	rawData = append(rawData, domainSeparator...)
+	rawData = append(rawData, messageHash...)
+	return crypto.Keccak256Hash(rawData), nil
+}
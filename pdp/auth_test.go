@@ -2,12 +2,14 @@ package pdp
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/ipfs/go-cid"
 )
 
@@ -290,6 +292,58 @@ func TestAuthHelper_SignAddPieces(t *testing.T) {
 	}
 }
 
+func TestAuthHelper_SignCreateDataSetAndAddPieces(t *testing.T) {
+	authHelper := setupAuthHelper(t)
+
+	clientDataSetID := big.NewInt(fixtures.Signatures.AddPieces.ClientDataSetID)
+	nonce := big.NewInt(fixtures.Signatures.AddPieces.Nonce)
+	payee := common.HexToAddress(fixtures.SignerAddress)
+	dataSetMetadata := []MetadataEntry{{Key: "source", Value: "test"}}
+	pieceCIDs := make([]cid.Cid, len(fixtures.Signatures.AddPieces.PieceCIDs))
+	for i, cidStr := range fixtures.Signatures.AddPieces.PieceCIDs {
+		c, err := cid.Decode(cidStr)
+		if err != nil {
+			t.Fatalf("Failed to parse PieceCID %s: %v", cidStr, err)
+		}
+		pieceCIDs[i] = c
+	}
+	pieceMetadata := fixtures.Signatures.AddPieces.Metadata
+
+	combined, err := authHelper.SignCreateDataSetAndAddPieces(clientDataSetID, payee, nonce, pieceCIDs, dataSetMetadata, pieceMetadata)
+	if err != nil {
+		t.Fatalf("SignCreateDataSetAndAddPieces failed: %v", err)
+	}
+
+	createSig, err := authHelper.SignCreateDataSet(clientDataSetID, payee, dataSetMetadata)
+	if err != nil {
+		t.Fatalf("SignCreateDataSet failed: %v", err)
+	}
+	createExtra, err := EncodeDataSetCreateData(authHelper.Address(), clientDataSetID, dataSetMetadata, createSig.Signature)
+	if err != nil {
+		t.Fatalf("EncodeDataSetCreateData failed: %v", err)
+	}
+	addSig, err := authHelper.SignAddPieces(clientDataSetID, nonce, pieceCIDs, pieceMetadata)
+	if err != nil {
+		t.Fatalf("SignAddPieces failed: %v", err)
+	}
+	addExtra, err := EncodeAddPiecesExtraData(nonce, pieceMetadata, addSig.Signature)
+	if err != nil {
+		t.Fatalf("EncodeAddPiecesExtraData failed: %v", err)
+	}
+	want, err := EncodeCreateDataSetAndAddPiecesExtraData(createExtra, addExtra)
+	if err != nil {
+		t.Fatalf("EncodeCreateDataSetAndAddPiecesExtraData failed: %v", err)
+	}
+
+	if combined != want {
+		t.Errorf("combined extraData mismatch:\nExpected: %s\nActual:   %s", want, combined)
+	}
+
+	if _, err := authHelper.SignCreateDataSetAndAddPieces(clientDataSetID, payee, nonce, pieceCIDs, nil, pieceMetadata[:1]); err == nil {
+		t.Error("Expected error for mismatched piece metadata length, got nil")
+	}
+}
+
 func TestAuthHelper_ConsistentSignatures(t *testing.T) {
 	authHelper := setupAuthHelper(t)
 
@@ -399,6 +453,15 @@ func TestAuthHelper_SignDigestFunc(t *testing.T) {
 	if helperFromFn.Address() != address {
 		t.Errorf("Address mismatch: helper=%s want=%s", helperFromFn.Address().Hex(), address.Hex())
 	}
+
+	// EVMSigner.SignHash over the same digest yields the on-chain signature
+	sigC, err := NewPrivateKeySigner(privateKey).SignHash(sigA.SignedData)
+	if err != nil {
+		t.Fatalf("SignHash: %v", err)
+	}
+	if hex.EncodeToString(sigC) != hex.EncodeToString(sigA.Signature) {
+		t.Errorf("SignHash and AuthHelper produced different signatures:\n hash: %x\n auth: %x", sigC, sigA.Signature)
+	}
 }
 
 // TestAuthHelper_RejectsBadSignerOutput verifies the length check in
@@ -421,3 +484,107 @@ func TestAuthHelper_RejectsBadSignerOutput(t *testing.T) {
 		t.Errorf("error did not mention expected length: %v", err)
 	}
 }
+
+// TestAuthHelper_DigestOnly verifies that a helper without a signer produces
+// the same digests the signing paths sign, so external signatures match.
+func TestAuthHelper_DigestOnly(t *testing.T) {
+	signing := setupAuthHelper(t)
+	digestOnly := NewAuthHelper(nil, signing.Address(), common.HexToAddress(fixtures.ContractAddress), big.NewInt(fixtures.ChainID))
+
+	create := fixtures.Signatures.CreateDataSet
+	clientDataSetID := big.NewInt(create.ClientDataSetID)
+	payee := common.HexToAddress(create.Payee)
+
+	if _, err := digestOnly.SignCreateDataSet(clientDataSetID, payee, create.Metadata); err == nil {
+		t.Error("SignCreateDataSet without a signer should fail")
+	}
+
+	digest, err := digestOnly.CreateDataSetDigest(clientDataSetID, payee, create.Metadata)
+	if err != nil {
+		t.Fatalf("CreateDataSetDigest: %v", err)
+	}
+	sig, err := signing.SignCreateDataSet(clientDataSetID, payee, create.Metadata)
+	if err != nil {
+		t.Fatalf("SignCreateDataSet: %v", err)
+	}
+	if digest != sig.SignedData {
+		t.Errorf("CreateDataSetDigest = %s, want %s", digest.Hex(), sig.SignedData.Hex())
+	}
+
+	pieceCIDs := []cid.Cid{mustCID(t, testCIDA)}
+	addDigest, err := digestOnly.AddPiecesDigest(clientDataSetID, big.NewInt(7), pieceCIDs, nil)
+	if err != nil {
+		t.Fatalf("AddPiecesDigest: %v", err)
+	}
+	addSig, err := signing.SignAddPieces(clientDataSetID, big.NewInt(7), pieceCIDs, nil)
+	if err != nil {
+		t.Fatalf("SignAddPieces: %v", err)
+	}
+	if addDigest != addSig.SignedData {
+		t.Errorf("AddPiecesDigest = %s, want %s", addDigest.Hex(), addSig.SignedData.Hex())
+	}
+
+	pieceIDs := []*big.Int{big.NewInt(1), big.NewInt(3)}
+	removeDigest, err := digestOnly.SchedulePieceRemovalsDigest(clientDataSetID, pieceIDs)
+	if err != nil {
+		t.Fatalf("SchedulePieceRemovalsDigest: %v", err)
+	}
+	removeSig, err := signing.SignSchedulePieceRemovals(clientDataSetID, pieceIDs)
+	if err != nil {
+		t.Fatalf("SignSchedulePieceRemovals: %v", err)
+	}
+	if removeDigest != removeSig.SignedData {
+		t.Errorf("SchedulePieceRemovalsDigest = %s, want %s", removeDigest.Hex(), removeSig.SignedData.Hex())
+	}
+
+	deleteDigest, err := digestOnly.DeleteDataSetDigest(clientDataSetID)
+	if err != nil {
+		t.Fatalf("DeleteDataSetDigest: %v", err)
+	}
+	deleteSig, err := signing.SignDeleteDataSet(clientDataSetID)
+	if err != nil {
+		t.Fatalf("SignDeleteDataSet: %v", err)
+	}
+	if deleteDigest != deleteSig.SignedData {
+		t.Errorf("DeleteDataSetDigest = %s, want %s", deleteDigest.Hex(), deleteSig.SignedData.Hex())
+	}
+}
+
+func TestAuthHelper_TypedDataJSON(t *testing.T) {
+	auth := testAuthHelper(t)
+	pieceCID := mustCID(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	metadata := [][]MetadataEntry{{{Key: "name", Value: "piece"}}}
+
+	message, err := AddPiecesMessage(big.NewInt(1), big.NewInt(2), []cid.Cid{pieceCID}, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := auth.TypedDataJSON("AddPieces", message)
+	if err != nil {
+		t.Fatalf("TypedDataJSON() error = %v", err)
+	}
+
+	// what a wallet would hash after parsing the JSON
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		t.Fatalf("TypedDataJSON() is not valid typed data: %v", err)
+	}
+	if _, ok := typedData.Types["DeleteDataSet"]; ok {
+		t.Error("TypedDataJSON() included types AddPieces does not reference")
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatalf("hashing parsed typed data: %v", err)
+	}
+	want, err := auth.AddPiecesDigest(big.NewInt(1), big.NewInt(2), []cid.Cid{pieceCID}, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToHash(digest) != want {
+		t.Errorf("parsed typed data hashes to %x, want %s", digest, want.Hex())
+	}
+
+	if _, err := auth.TypedDataJSON("Transfer", DeleteDataSetMessage(big.NewInt(1))); err == nil {
+		t.Error("TypedDataJSON() expected error for an unknown primary type")
+	}
+}
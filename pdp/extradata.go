@@ -0,0 +1,60 @@
+package pdp
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ExtraDataEncoder builds the extraData passed to a listener (record keeper)
+// contract's dataSetCreated hook when a data set is created. Its format is
+// defined by the listener: WarmStorageExtraData implements the
+// FilecoinWarmStorageService format, and a custom listener plugs in its own
+// encoder through CreateProofSetOptions.ExtraDataEncoder.
+type ExtraDataEncoder interface {
+	// EncodeCreateDataSet returns the extraData for creating a data set
+	// whose listener is listener.
+	EncodeCreateDataSet(listener common.Address) ([]byte, error)
+}
+
+// ExtraDataEncoderFunc adapts a plain function to ExtraDataEncoder.
+type ExtraDataEncoderFunc func(listener common.Address) ([]byte, error)
+
+func (f ExtraDataEncoderFunc) EncodeCreateDataSet(listener common.Address) ([]byte, error) {
+	return f(listener)
+}
+
+// WarmStorageExtraData is the ExtraDataEncoder for the WarmStorage (FWSS)
+// listener. It signs a CreateDataSet authorization with Auth, whose address
+// is the payer, and encodes it with EncodeDataSetCreateData. The listener
+// must be the WarmStorage contract Auth signs for.
+type WarmStorageExtraData struct {
+	Auth            *AuthHelper
+	Payee           common.Address
+	ClientDataSetID *big.Int
+	Metadata        []MetadataEntry
+}
+
+func (w *WarmStorageExtraData) EncodeCreateDataSet(listener common.Address) ([]byte, error) {
+	if w.Auth == nil {
+		return nil, fmt.Errorf("auth helper is required")
+	}
+	if w.ClientDataSetID == nil {
+		return nil, fmt.Errorf("client data set ID is required")
+	}
+	if listener != w.Auth.warmStorageAddress {
+		return nil, fmt.Errorf("listener %s is not the WarmStorage contract %s the signature is bound to", listener.Hex(), w.Auth.warmStorageAddress.Hex())
+	}
+
+	sig, err := w.Auth.SignCreateDataSet(w.ClientDataSetID, w.Payee, w.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign create data set: %w", err)
+	}
+
+	encoded, err := EncodeDataSetCreateData(w.Auth.Address(), w.ClientDataSetID, w.Metadata, sig.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return decodeHex(encoded)
+}
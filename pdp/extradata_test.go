@@ -0,0 +1,90 @@
+package pdp
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestWarmStorageExtraData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warmStorage := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	auth := NewAuthHelperFromKey(key, warmStorage, big.NewInt(314159))
+	payee := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	metadata := []MetadataEntry{{Key: "source", Value: "test"}}
+
+	enc := &WarmStorageExtraData{Auth: auth, Payee: payee, ClientDataSetID: big.NewInt(9), Metadata: metadata}
+	got, err := enc.EncodeCreateDataSet(warmStorage)
+	if err != nil {
+		t.Fatalf("EncodeCreateDataSet() error = %v", err)
+	}
+
+	sig, err := auth.SignCreateDataSet(big.NewInt(9), payee, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := EncodeDataSetCreateData(auth.Address(), big.NewInt(9), metadata, sig.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if "0x"+common.Bytes2Hex(got) != want {
+		t.Errorf("EncodeCreateDataSet() = %x, want %s", got, want)
+	}
+
+	if _, err := enc.EncodeCreateDataSet(common.HexToAddress("0x01")); err == nil {
+		t.Error("EncodeCreateDataSet() expected error for a listener other than the signed WarmStorage contract")
+	}
+}
+
+func TestManager_CreateProofSetExtraDataEncoder(t *testing.T) {
+	api := &offlineAPI{deleteAPI{status: types.ReceiptStatusSuccessful}}
+	config := DefaultManagerConfig()
+	config.DefaultGasLimit = 1000000
+	m := newTestManager(t, api, &config)
+	listener := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	encoder := ExtraDataEncoderFunc(func(listener common.Address) ([]byte, error) {
+		return append([]byte("signed for "), listener.Bytes()...), nil
+	})
+
+	_, err := m.CreateProofSet(context.Background(), CreateProofSetOptions{
+		Listener:         listener,
+		ExtraData:        []byte{1},
+		ExtraDataEncoder: encoder,
+	})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("CreateProofSet() error = %v, want mutually exclusive options", err)
+	}
+	if api.sent != 0 {
+		t.Fatalf("sent %d transactions, want none", api.sent)
+	}
+
+	if _, err := m.CreateProofSet(context.Background(), CreateProofSetOptions{
+		Listener:         listener,
+		ExtraDataEncoder: encoder,
+	}); err != nil {
+		t.Fatalf("CreateProofSet() error = %v", err)
+	}
+
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := verifier.Methods["createDataSet"].Inputs.Unpack(api.data[4:])
+	if err != nil {
+		t.Fatalf("failed to decode createDataSet calldata: %v", err)
+	}
+	want, _ := encoder.EncodeCreateDataSet(listener)
+	if args[0].(common.Address) != listener || !bytes.Equal(args[1].([]byte), want) {
+		t.Errorf("createDataSet(%s, %x), want (%s, %x)", args[0], args[1], listener, want)
+	}
+}
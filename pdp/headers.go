@@ -0,0 +1,60 @@
+package pdp
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+)
+
+const modulePath = "github.com/data-preservation-programs/go-synapse"
+
+// DefaultUserAgent is the User-Agent a Server sends unless
+// ServerOptions.UserAgent overrides it: go-synapse/<version>, with the
+// version taken from the build info of the binary using the SDK.
+var DefaultUserAgent = "go-synapse/" + moduleVersion()
+
+// moduleVersion returns the version of this module the running binary was
+// built with, or "devel" when it is not known (tests, replace directives).
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	mod := &info.Main
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			mod = dep
+			break
+		}
+	}
+	if mod.Path != modulePath || mod.Version == "" || mod.Version == "(devel)" {
+		return "devel"
+	}
+	return mod.Version
+}
+
+// headerTransport sets the User-Agent and, when enabled, a fresh
+// X-Request-ID on every request that does not already carry them. It sits
+// outside retryAfterTransport so a request keeps its ID across retries.
+type headerTransport struct {
+	base       http.RoundTripper
+	userAgent  string
+	requestIDs bool
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	setUA := t.userAgent != "" && req.Header.Get("User-Agent") == ""
+	setID := t.requestIDs && req.Header.Get("X-Request-ID") == ""
+	if setUA || setID {
+		// a RoundTripper must not modify the caller's request
+		req = req.Clone(req.Context())
+		if setUA {
+			req.Header.Set("User-Agent", t.userAgent)
+		}
+		if setID {
+			req.Header.Set("X-Request-ID", uuid.NewString())
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/data-preservation-programs/go-synapse/constants"
 	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/data-preservation-programs/go-synapse/internal/multicall"
+	"github.com/data-preservation-programs/go-synapse/internal/retry"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
 	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -23,13 +28,25 @@ var SybilFee = big.NewInt(100000000000000000) // 0.1 FIL in attoFIL
 
 const defaultReceiptTimeout = 90 * time.Second
 
+// challengeEpochPollInterval is roughly a tenth of a Filecoin epoch.
+const challengeEpochPollInterval = 3 * time.Second
+
+var (
+	// ErrTransactionReverted means a transaction was mined with a failed
+	// status.
+	ErrTransactionReverted = errors.New("transaction reverted")
+	// ErrProofSetStillLive means DeleteProofSet's transaction succeeded but
+	// the proof set was still live afterwards.
+	ErrProofSetStillLive = errors.New("proof set still live after delete")
+)
+
 // ProofSetManager provides high-level operations for managing PDP proof sets
 type ProofSetManager interface {
 	// CreateProofSet creates a new proof set on-chain
 	CreateProofSet(ctx context.Context, opts CreateProofSetOptions) (*ProofSetResult, error)
 
 	// GetProofSet retrieves proof set details
-	GetProofSet(ctx context.Context, proofSetID *big.Int) (*ProofSet, error)
+	GetProofSet(ctx context.Context, proofSetID *big.Int, opts ...callopt.Option) (*ProofSet, error)
 
 	// AddRoots adds data roots to an existing proof set
 	AddRoots(ctx context.Context, proofSetID *big.Int, roots []Root) (*AddRootsResult, error)
@@ -45,6 +62,12 @@ type ProofSetManager interface {
 
 	// DataSetLive checks if a proof set is live
 	DataSetLive(ctx context.Context, proofSetID *big.Int) (bool, error)
+
+	// DataSetsLive checks several proof sets in a single batched call
+	DataSetsLive(ctx context.Context, ids []*big.Int) (map[string]bool, error)
+
+	// ListProofSets returns the live proof sets owned by a storage provider
+	ListProofSets(ctx context.Context, owner common.Address) ([]*ProofSet, error)
 }
 
 // CreateProofSetOptions options for creating a proof set
@@ -54,9 +77,28 @@ type CreateProofSetOptions struct {
 	// PDPListener(addr).dataSetCreated() on non-zero addresses.
 	Listener  common.Address
 	ExtraData []byte
+	// ExtraDataEncoder, when set, builds ExtraData for Listener instead;
+	// setting both is an error. See WarmStorageExtraData.
+	ExtraDataEncoder ExtraDataEncoder
 	// Value overrides the msg.value sent with CreateDataSet. Defaults to
 	// the 0.1 FIL sybil fee when nil.
 	Value *big.Int
+	// ClientDataSetID, when set, makes CreateProofSet safe to retry: if
+	// Payer already has a proof set with this client data set ID, as
+	// reported by ManagerConfig.DataSetFinder, it is returned instead of
+	// creating another. A create still pending in the mempool is not seen.
+	ClientDataSetID *big.Int
+	// Payer is the client paying for the proof set, required with
+	// ClientDataSetID.
+	Payer common.Address
+}
+
+// DataSetFinder looks up a payer's data set by its client data set ID.
+// warmstorage.StateViewContract implements it.
+type DataSetFinder interface {
+	// FindDataSetByClientID returns the ID of payer's data set with the
+	// given client data set ID, or nil if there is none.
+	FindDataSetByClientID(ctx context.Context, payer common.Address, clientDataSetID *big.Int) (*big.Int, error)
 }
 
 // ProofSetResult result of creating a proof set
@@ -64,6 +106,10 @@ type ProofSetResult struct {
 	ProofSetID      *big.Int
 	TransactionHash common.Hash
 	Receipt         *types.Receipt
+	// Existing is set when CreateProofSet found the proof set already
+	// created for CreateProofSetOptions.ClientDataSetID; TransactionHash
+	// and Receipt are then empty.
+	Existing bool
 }
 
 // ProofSet represents a proof set's details
@@ -99,8 +145,10 @@ type Manager struct {
 	contract     *contracts.PDPVerifier
 	contractAddr common.Address
 	chainID      *big.Int
+	network      constants.Network
 	nonceManager *txutil.NonceManager
 	config       ManagerConfig
+	multicall    *multicall.Probe
 }
 
 // NewManagerWithContext creates a new ProofSetManager with context support and default configuration.
@@ -108,6 +156,23 @@ func NewManagerWithContext(ctx context.Context, client *ethclient.Client, signer
 	return NewManagerWithConfig(ctx, client, signer, network, nil)
 }
 
+// NewManagerAutoDetect creates a ProofSetManager for whichever supported
+// network the RPC endpoint serves, detected from its chain ID, using the
+// default configuration.
+func NewManagerAutoDetect(ctx context.Context, client *ethclient.Client, signer Signer) (*Manager, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	network, ok := constants.NetworkForChainID(chainID.Int64())
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain ID: %s", chainID)
+	}
+
+	return NewManagerWithConfig(ctx, client, signer, network, nil)
+}
+
 // NewManagerWithConfig creates a new ProofSetManager with custom configuration.
 // If config is nil, default configuration will be used.
 func NewManagerWithConfig(ctx context.Context, client *ethclient.Client, signer Signer, network constants.Network, config *ManagerConfig) (*Manager, error) {
@@ -139,6 +204,9 @@ func NewManagerWithConfig(ctx context.Context, client *ethclient.Client, signer
 	if config.GasBufferPercent < 0 || config.GasBufferPercent > 100 {
 		return nil, fmt.Errorf("gas buffer percent must be between 0 and 100, got %d", config.GasBufferPercent)
 	}
+	if config.PendingWarnThreshold < 0 {
+		return nil, fmt.Errorf("pending warn threshold must not be negative, got %d", config.PendingWarnThreshold)
+	}
 
 	contractAddr := config.ContractAddress
 	if contractAddr == (common.Address{}) {
@@ -163,11 +231,18 @@ func NewManagerWithConfig(ctx context.Context, client *ethclient.Client, signer
 		contract:     contract,
 		contractAddr: contractAddr,
 		chainID:      chainID,
+		network:      network,
 		nonceManager: nonceManager,
 		config:       *config,
+		multicall:    &multicall.Probe{OnMissing: config.OnMulticall3Unavailable},
 	}, nil
 }
 
+// Network returns the network the manager was created for.
+func (m *Manager) Network() constants.Network {
+	return m.network
+}
+
 func (m *Manager) newTransactor(ctx context.Context, nonce uint64, value *big.Int) (*bind.TransactOpts, error) {
 	auth, err := m.signer.Transactor(m.chainID)
 	if err != nil {
@@ -185,52 +260,81 @@ func (m *Manager) newTransactor(ctx context.Context, nonce uint64, value *big.In
 	return auth, nil
 }
 
-// CreateProofSet creates a new proof set on-chain
-func (m *Manager) CreateProofSet(ctx context.Context, opts CreateProofSetOptions) (*ProofSetResult, error) {
-	nonce, err := m.nonceManager.GetNonce(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
-	}
+// sendWithNonceRetry reserves a nonce and calls send with it. A send that
+// fails releases the nonce. If the node rejected the nonce as already used
+// (another process sending from the same key), the nonce manager is reset
+// and send is retried once with a fresh nonce. On success the nonce stays
+// pending until the caller marks it confirmed.
+func (m *Manager) sendWithNonceRetry(ctx context.Context, send func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, uint64, error) {
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.nonceManager.GetNonce(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get nonce: %w", err)
+		}
 
-	// Track whether transaction was actually sent to the network
-	txSent := false
-	defer func() {
-		if !txSent {
-			// Local failure before sending - release nonce immediately
-			m.nonceManager.MarkFailed(nonce)
+		tx, err := send(nonce)
+		if err == nil {
+			m.checkPendingBacklog()
+			return tx, nonce, nil
+		}
+		if attempt == 0 && txutil.IsNonceError(err) {
+			m.nonceManager.Reset()
+			continue
 		}
-	}()
+		// Local failure or rejection before the tx was accepted - release nonce
+		m.nonceManager.MarkFailed(nonce)
+		return nil, 0, err
+	}
+}
 
+// PendingTransactionCount returns how many transactions the manager has
+// sent that are not yet confirmed.
+func (m *Manager) PendingTransactionCount() int {
+	return m.nonceManager.GetPendingCount()
+}
+
+func (m *Manager) checkPendingBacklog() {
+	if m.config.PendingWarnThreshold == 0 || m.config.OnPendingBacklog == nil {
+		return
+	}
+	if pending := m.nonceManager.GetPendingCount(); pending > m.config.PendingWarnThreshold {
+		m.config.OnPendingBacklog(pending)
+	}
+}
+
+// CreateProofSet creates a new proof set on-chain
+func (m *Manager) CreateProofSet(ctx context.Context, opts CreateProofSetOptions) (*ProofSetResult, error) {
 	// default to sybil fee when caller doesn't specify a value
 	value := opts.Value
 	if value == nil {
 		value = SybilFee
 	}
 
-	auth, err := m.newTransactor(ctx, nonce, value)
+	extraData, err := createDataSetExtraData(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if m.config.DefaultGasLimit == 0 {
-		// estimate gas
-		auth.NoSend = true
-		tx, err := m.contract.CreateDataSet(auth, opts.Listener, opts.ExtraData)
+	if opts.ClientDataSetID != nil {
+		existing, err := m.findProofSet(ctx, opts.Payer, opts.ClientDataSetID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to estimate gas for createDataSet: %w", err)
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
 		}
-		bufferMultiplier := 1.0 + (float64(m.config.GasBufferPercent) / 100.0)
-		auth.GasLimit = uint64(float64(tx.Gas()) * bufferMultiplier)
-		auth.NoSend = false
 	}
 
-	tx, err := m.contract.CreateDataSet(auth, opts.Listener, opts.ExtraData)
+	tx, nonce, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+		auth, err := m.newTransactor(ctx, nonce, value)
+		if err != nil {
+			return nil, err
+		}
+		return m.createDataSet(auth, opts.Listener, extraData)
+	})
 	if err != nil {
-		// txSent is still false - defer will call MarkFailed
-		return nil, fmt.Errorf("failed to create data set: %w", err)
+		return nil, err
 	}
-	// Mark as sent only after successful contract call
-	txSent = true
 
 	receipt, err := txutil.WaitForReceipt(ctx, m.client, tx.Hash(), defaultReceiptTimeout)
 	if err != nil {
@@ -253,9 +357,77 @@ func (m *Manager) CreateProofSet(ctx context.Context, opts CreateProofSetOptions
 	}, nil
 }
 
-// GetProofSet retrieves proof set details
-func (m *Manager) GetProofSet(ctx context.Context, proofSetID *big.Int) (*ProofSet, error) {
-	opts := &bind.CallOpts{Context: ctx}
+// findProofSet returns the live proof set payer already created with
+// clientDataSetID, or nil if there is none.
+func (m *Manager) findProofSet(ctx context.Context, payer common.Address, clientDataSetID *big.Int) (*ProofSetResult, error) {
+	if m.config.DataSetFinder == nil {
+		return nil, errors.New("ClientDataSetID requires ManagerConfig.DataSetFinder")
+	}
+	if payer == (common.Address{}) {
+		return nil, errors.New("ClientDataSetID requires Payer")
+	}
+
+	id, err := m.config.DataSetFinder.FindDataSetByClientID(ctx, payer, clientDataSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up client data set %s: %w", clientDataSetID, err)
+	}
+	if id == nil {
+		return nil, nil
+	}
+
+	live, err := m.DataSetLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !live {
+		return nil, fmt.Errorf("client data set ID %s was used by proof set %s, which has been deleted", clientDataSetID, id)
+	}
+	return &ProofSetResult{ProofSetID: id, Existing: true}, nil
+}
+
+// createDataSetExtraData resolves the extraData for opts, running its
+// ExtraDataEncoder if one is set.
+func createDataSetExtraData(opts CreateProofSetOptions) ([]byte, error) {
+	if opts.ExtraDataEncoder == nil {
+		return opts.ExtraData, nil
+	}
+	if len(opts.ExtraData) > 0 {
+		return nil, fmt.Errorf("ExtraData and ExtraDataEncoder are mutually exclusive")
+	}
+	encoded, err := opts.ExtraDataEncoder.EncodeCreateDataSet(opts.Listener)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extra data: %w", err)
+	}
+	return encoded, nil
+}
+
+// createDataSet calls createDataSet with auth, first estimating the gas
+// limit unless ManagerConfig.DefaultGasLimit is set.
+func (m *Manager) createDataSet(auth *bind.TransactOpts, listener common.Address, extraData []byte) (*types.Transaction, error) {
+	if m.config.DefaultGasLimit == 0 {
+		// estimate gas
+		noSend := auth.NoSend
+		auth.NoSend = true
+		tx, err := m.contract.CreateDataSet(auth, listener, extraData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas for createDataSet: %w", err)
+		}
+		bufferMultiplier := 1.0 + (float64(m.config.GasBufferPercent) / 100.0)
+		auth.GasLimit = uint64(float64(tx.Gas()) * bufferMultiplier)
+		auth.NoSend = noSend
+	}
+
+	tx, err := m.contract.CreateDataSet(auth, listener, extraData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data set: %w", err)
+	}
+	return tx, nil
+}
+
+// GetProofSet retrieves proof set details. Pass callopt.WithBlock to read the
+// proof set as it was at a historical block.
+func (m *Manager) GetProofSet(ctx context.Context, proofSetID *big.Int, callOpts ...callopt.Option) (*ProofSet, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: callopt.Apply(callOpts...).BlockNumber}
 
 	live, err := m.contract.DataSetLive(opts, proofSetID)
 	if err != nil {
@@ -298,65 +470,59 @@ func (m *Manager) GetProofSet(ctx context.Context, proofSetID *big.Int) (*ProofS
 	}, nil
 }
 
-// AddRoots adds data roots to an existing proof set
+// AddRoots adds data roots to an existing proof set. Root CIDs must be piece
+// CIDs; v2 piece CIDs are converted to the v1 form the contract stores.
 func (m *Manager) AddRoots(ctx context.Context, proofSetID *big.Int, roots []Root) (*AddRootsResult, error) {
 	if len(roots) == 0 {
 		return nil, errors.New("no roots provided")
 	}
 
-	// Get the proof set's listener address
-	proofSet, err := m.GetProofSet(ctx, proofSetID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get proof set: %w", err)
-	}
-	listenerAddr := proofSet.Listener
-
 	// Convert roots to contract format
 	pieceData := make([]contracts.CidsCid, len(roots))
 	for i, root := range roots {
+		pieceCID, err := NormalizePieceCID(root.PieceCID)
+		if err != nil {
+			return nil, fmt.Errorf("root %d: %w", i, err)
+		}
 		pieceData[i] = contracts.CidsCid{
-			Data: root.PieceCID.Bytes(),
+			Data: pieceCID.Bytes(),
 		}
 	}
 
-	nonce, err := m.nonceManager.GetNonce(ctx)
+	// Get the proof set's listener address
+	proofSet, err := m.GetProofSet(ctx, proofSetID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, fmt.Errorf("failed to get proof set: %w", err)
 	}
+	listenerAddr := proofSet.Listener
 
-	// Track whether transaction was actually sent to the network
-	txSent := false
-	defer func() {
-		if !txSent {
-			// Local failure before sending - release nonce immediately
-			m.nonceManager.MarkFailed(nonce)
+	tx, nonce, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+		auth, err := m.newTransactor(ctx, nonce, nil)
+		if err != nil {
+			return nil, err
 		}
-	}()
 
-	auth, err := m.newTransactor(ctx, nonce, nil)
-	if err != nil {
-		return nil, err
-	}
+		if m.config.DefaultGasLimit == 0 {
+			// estimate gas
+			auth.NoSend = true
+			tx, err := m.contract.AddPieces(auth, proofSetID, listenerAddr, pieceData, []byte{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to estimate gas for addPieces: %w", err)
+			}
+			bufferMultiplier := 1.0 + (float64(m.config.GasBufferPercent) / 100.0)
+			auth.GasLimit = uint64(float64(tx.Gas()) * bufferMultiplier)
+			auth.NoSend = false
+		}
 
-	if m.config.DefaultGasLimit == 0 {
-		// estimate gas
-		auth.NoSend = true
 		tx, err := m.contract.AddPieces(auth, proofSetID, listenerAddr, pieceData, []byte{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to estimate gas for addPieces: %w", err)
+			return nil, fmt.Errorf("failed to add pieces: %w", err)
 		}
-		bufferMultiplier := 1.0 + (float64(m.config.GasBufferPercent) / 100.0)
-		auth.GasLimit = uint64(float64(tx.Gas()) * bufferMultiplier)
-		auth.NoSend = false
-	}
-
-	tx, err := m.contract.AddPieces(auth, proofSetID, listenerAddr, pieceData, []byte{})
+		return tx, nil
+	})
 	if err != nil {
-		// txSent is still false - defer will call MarkFailed
-		return nil, fmt.Errorf("failed to add pieces: %w", err)
+		return nil, err
 	}
-	// Mark as sent only after successful contract call
-	txSent = true
 
 	receipt, err := txutil.WaitForReceipt(ctx, m.client, tx.Hash(), defaultReceiptTimeout)
 	if err != nil {
@@ -410,43 +576,173 @@ func (m *Manager) GetRoots(ctx context.Context, proofSetID *big.Int, offset, lim
 	return roots, result.HasMore, nil
 }
 
-// DeleteProofSet removes a proof set
+// DeleteProofSet removes a proof set. A delete transaction that is mined but
+// reverts fails with ErrTransactionReverted, carrying the revert reason when
+// it can be recovered. With ManagerConfig.VerifyDeletion it also checks the
+// proof set is no longer live as of the block the delete landed in, failing
+// with ErrProofSetStillLive otherwise.
 func (m *Manager) DeleteProofSet(ctx context.Context, proofSetID *big.Int, extraData []byte) error {
-	nonce, err := m.nonceManager.GetNonce(ctx)
+	tx, nonce, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+		auth, err := m.newTransactor(ctx, nonce, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := m.contract.DeleteDataSet(auth, proofSetID, extraData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete data set: %w", err)
+		}
+		return tx, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get nonce: %w", err)
+		return err
 	}
 
-	// Track whether transaction was actually sent to the network
-	txSent := false
-	defer func() {
-		if !txSent {
-			// Local failure before sending - release nonce immediately
-			m.nonceManager.MarkFailed(nonce)
+	receipt, err := txutil.WaitForReceipt(ctx, m.client, tx.Hash(), defaultReceiptTimeout)
+	if receipt == nil {
+		// Error waiting for receipt - transaction may be pending, don't release nonce
+		return fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+
+	// mined either way, so the nonce is spent
+	m.nonceManager.MarkConfirmed(nonce)
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		reason, _ := txutil.RevertReason(ctx, m.client, tx, receipt.BlockNumber)
+		if reason == "" {
+			reason = "unknown reason"
 		}
-	}()
+		return fmt.Errorf("%w: delete of proof set %s in %s: %s", ErrTransactionReverted, proofSetID, tx.Hash().Hex(), reason)
+	}
+
+	if m.config.VerifyDeletion {
+		live, err := m.contract.DataSetLive(&bind.CallOpts{Context: ctx, BlockNumber: receipt.BlockNumber}, proofSetID)
+		if err != nil {
+			return fmt.Errorf("failed to verify deletion: %w", err)
+		}
+		if live {
+			return fmt.Errorf("%w: proof set %s, tx %s", ErrProofSetStillLive, proofSetID, tx.Hash().Hex())
+		}
+	}
+	return nil
+}
 
-	auth, err := m.newTransactor(ctx, nonce, nil)
+// ListProofSets returns the live proof sets whose current storage provider is
+// owner. Candidates come from the verifier's DataSetCreated and
+// StorageProviderChanged events naming owner (see
+// ManagerConfig.EventsFromBlock and LogBlockRange); their liveness, current
+// provider and details are then read in two batches through Multicall3, all
+// pinned to a single block for a consistent view.
+func (m *Manager) ListProofSets(ctx context.Context, owner common.Address) ([]*ProofSet, error) {
+	head, err := m.client.BlockNumber(ctx)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get block number: %w", err)
 	}
+	block := new(big.Int).SetUint64(head)
 
-	tx, err := m.contract.DeleteDataSet(auth, proofSetID, extraData)
+	ids, err := m.proofSetCandidates(ctx, owner, head)
 	if err != nil {
-		// txSent is still false - defer will call MarkFailed
-		return fmt.Errorf("failed to delete data set: %w", err)
+		return nil, err
 	}
-	// Mark as sent only after successful contract call
-	txSent = true
 
-	_, err = txutil.WaitForReceipt(ctx, m.client, tx.Hash(), defaultReceiptTimeout)
+	liveCalls := make([]viewCall, len(ids))
+	for i, id := range ids {
+		liveCalls[i] = viewCall{method: "dataSetLive", args: []interface{}{id}}
+	}
+	liveResults, err := m.callViews(ctx, block, liveCalls)
 	if err != nil {
-		// Error waiting for receipt - transaction may be pending, don't release nonce
-		return fmt.Errorf("failed to wait for receipt: %w", err)
+		return nil, err
 	}
 
-	m.nonceManager.MarkConfirmed(nonce)
-	return nil
+	// five calls per live candidate: the provider to confirm ownership, then
+	// the fields GetProofSet reads
+	detailMethods := []string{"getDataSetStorageProvider", "getDataSetListener", "getDataSetLeafCount", "getActivePieceCount", "getNextPieceId"}
+	var live []*big.Int
+	var detailCalls []viewCall
+	for i, id := range ids {
+		if !liveResults[i][0].(bool) {
+			continue
+		}
+		live = append(live, id)
+		for _, method := range detailMethods {
+			detailCalls = append(detailCalls, viewCall{method: method, args: []interface{}{id}})
+		}
+	}
+	details, err := m.callViews(ctx, block, detailCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	var proofSets []*ProofSet
+	for i, id := range live {
+		d := details[i*len(detailMethods):]
+		if d[0][0].(common.Address) != owner {
+			continue
+		}
+		proofSets = append(proofSets, &ProofSet{
+			ID:              id,
+			Listener:        d[1][0].(common.Address),
+			StorageProvider: owner,
+			LeafCount:       d[2][0].(*big.Int).Uint64(),
+			ActivePieces:    d[3][0].(*big.Int).Uint64(),
+			NextPieceID:     d[4][0].(*big.Int).Uint64(),
+			Live:            true,
+		})
+	}
+
+	return proofSets, nil
+}
+
+// proofSetCandidates returns, in ascending order, the IDs of the data sets
+// created by owner or transferred to it up to block head.
+func (m *Manager) proofSetCandidates(ctx context.Context, owner common.Address, head uint64) ([]*big.Int, error) {
+	seen := make(map[string]bool)
+	var ids []*big.Int
+	add := func(id *big.Int) {
+		if !seen[id.String()] {
+			seen[id.String()] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for start := m.config.EventsFromBlock; start <= head; {
+		end := head
+		if r := m.config.LogBlockRange; r > 0 && head-start >= r {
+			end = start + r - 1
+		}
+		opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+
+		created, err := m.contract.FilterDataSetCreated(opts, nil, []common.Address{owner})
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter DataSetCreated events: %w", err)
+		}
+		for created.Next() {
+			add(created.Event.SetId)
+		}
+		err = created.Error()
+		created.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DataSetCreated events: %w", err)
+		}
+
+		changed, err := m.contract.FilterStorageProviderChanged(opts, nil, nil, []common.Address{owner})
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter StorageProviderChanged events: %w", err)
+		}
+		for changed.Next() {
+			add(changed.Event.SetId)
+		}
+		err = changed.Error()
+		changed.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read StorageProviderChanged events: %w", err)
+		}
+
+		start = end + 1
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+	return ids, nil
 }
 
 // GetNextChallengeEpoch gets the next challenge epoch for a proof set
@@ -461,6 +757,26 @@ func (m *Manager) GetNextChallengeEpoch(ctx context.Context, proofSetID *big.Int
 	return epoch.Uint64(), nil
 }
 
+// WaitForChallengeEpochAdvance polls GetNextChallengeEpoch until the proof
+// set's next challenge epoch is past previousEpoch, meaning the proving
+// period rolled over after a proof was accepted, and returns the new epoch.
+// It fails with ErrWaitTimeout if the epoch has not moved within timeout.
+func (m *Manager) WaitForChallengeEpochAdvance(ctx context.Context, proofSetID *big.Int, previousEpoch uint64, timeout time.Duration) (uint64, error) {
+	var epoch uint64
+	err := retry.Poll(ctx, challengeEpochPollInterval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		epoch, err = m.GetNextChallengeEpoch(ctx, proofSetID)
+		if err != nil {
+			return false, err
+		}
+		return epoch > previousEpoch, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return epoch, nil
+}
+
 // DataSetLive checks if a proof set is live
 func (m *Manager) DataSetLive(ctx context.Context, proofSetID *big.Int) (bool, error) {
 	opts := &bind.CallOpts{Context: ctx}
@@ -473,6 +789,91 @@ func (m *Manager) DataSetLive(ctx context.Context, proofSetID *big.Int) (bool, e
 	return live, nil
 }
 
+// DataSetsLive checks several proof sets in one eth_call through Multicall3
+// and returns their liveness keyed by decimal ID string. On chains without
+// Multicall3 it checks them one call at a time instead.
+func (m *Manager) DataSetsLive(ctx context.Context, ids []*big.Int) (map[string]bool, error) {
+	calls := make([]viewCall, len(ids))
+	for i, id := range ids {
+		calls[i] = viewCall{method: "dataSetLive", args: []interface{}{id}}
+	}
+	results, err := m.callViews(ctx, nil, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	live := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		live[id.String()] = results[i][0].(bool)
+	}
+	return live, nil
+}
+
+// viewCall is one PDPVerifier view call for callViews.
+type viewCall struct {
+	method string
+	args   []interface{}
+}
+
+// callViews runs calls against the verifier at block (nil for latest) and
+// returns their unpacked outputs in order. They go out as one Multicall3
+// batch, or one call at a time on chains without Multicall3. Any call that
+// fails fails the whole batch.
+func (m *Manager) callViews(ctx context.Context, block *big.Int, calls []viewCall) ([][]interface{}, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	parsed, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDPVerifier ABI: %w", err)
+	}
+
+	batch := make([]multicall.Call, len(calls))
+	for i, c := range calls {
+		data, err := parsed.Pack(c.method, c.args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack %s call: %w", c.method, err)
+		}
+		batch[i] = multicall.Call{Target: m.contractAddr, CallData: data}
+	}
+
+	multicallAddr := m.config.Multicall3Address
+	if multicallAddr == (common.Address{}) {
+		multicallAddr = constants.Multicall3Addresses[m.network]
+	}
+	returnData := make([][]byte, len(calls))
+	if m.multicall.Available(ctx, m.client, multicallAddr) {
+		results, err := multicall.Aggregate3(ctx, m.client, multicallAddr, batch, block)
+		if err != nil {
+			return nil, err
+		}
+		for i, r := range results {
+			if !r.Success {
+				return nil, fmt.Errorf("%s(%v) reverted", calls[i].method, calls[i].args[0])
+			}
+			returnData[i] = r.ReturnData
+		}
+	} else {
+		for i, c := range batch {
+			ret, err := m.client.CallContract(ctx, ethereum.CallMsg{To: &c.Target, Data: c.CallData}, block)
+			if err != nil {
+				return nil, fmt.Errorf("%s(%v): %w", calls[i].method, calls[i].args[0], err)
+			}
+			returnData[i] = ret
+		}
+	}
+
+	out := make([][]interface{}, len(calls))
+	for i, data := range returnData {
+		values, err := parsed.Unpack(calls[i].method, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack %s(%v) result: %w", calls[i].method, calls[i].args[0], err)
+		}
+		out[i] = values
+	}
+	return out, nil
+}
+
 // extractProofSetIDFromReceipt extracts the proof set ID from transaction receipt logs
 func (m *Manager) extractProofSetIDFromReceipt(receipt *types.Receipt) (*big.Int, error) {
 	for _, log := range receipt.Logs {
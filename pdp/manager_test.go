@@ -2,12 +2,21 @@ package pdp
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ipfs/go-cid"
 )
 
@@ -187,6 +196,58 @@ func TestManagerConstructors(t *testing.T) {
 	})
 }
 
+// chainIDAPI serves eth_chainId for an in-process RPC server.
+// chainIDAPI serves eth_chainId. A zero chainID reports calibration, the
+// network newTestManager uses.
+type chainIDAPI struct {
+	chainID int64
+}
+
+func (a *chainIDAPI) ChainId() *hexutil.Big {
+	if a.chainID == 0 {
+		return (*hexutil.Big)(big.NewInt(constants.ChainIDCalibration))
+	}
+	return (*hexutil.Big)(big.NewInt(a.chainID))
+}
+
+func TestNewManagerAutoDetect(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	signer := NewPrivateKeySigner(privateKey)
+
+	dial := func(t *testing.T, chainID int64) *ethclient.Client {
+		srv := rpc.NewServer()
+		if err := srv.RegisterName("eth", &chainIDAPI{chainID: chainID}); err != nil {
+			t.Fatalf("Failed to register RPC API: %v", err)
+		}
+		client := ethclient.NewClient(rpc.DialInProc(srv))
+		t.Cleanup(func() {
+			client.Close()
+			srv.Stop()
+		})
+		return client
+	}
+
+	t.Run("detects calibration", func(t *testing.T) {
+		m, err := NewManagerAutoDetect(context.Background(), dial(t, constants.ChainIDCalibration), signer)
+		if err != nil {
+			t.Fatalf("NewManagerAutoDetect failed: %v", err)
+		}
+		if m.Network() != constants.NetworkCalibration {
+			t.Errorf("expected network %s, got %s", constants.NetworkCalibration, m.Network())
+		}
+	})
+
+	t.Run("rejects unsupported chain ID", func(t *testing.T) {
+		_, err := NewManagerAutoDetect(context.Background(), dial(t, 1), signer)
+		if err == nil {
+			t.Fatal("expected error for unsupported chain ID")
+		}
+	})
+}
+
 // TestGasBufferCalculation tests that gas buffer is applied correctly
 func TestGasBufferCalculation(t *testing.T) {
 	testCases := []struct {
@@ -267,3 +328,670 @@ func TestProofSet_Fields(t *testing.T) {
 		t.Error("Live field not working")
 	}
 }
+
+const aggregate3TestABI = `[{"type":"function","name":"aggregate3","stateMutability":"payable",
+	"inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+	"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+// liveAPI serves eth_chainId and answers aggregate3 batches of dataSetLive
+// calls: even IDs are live, and IDs of 1000 or more revert.
+type liveAPI struct {
+	chainIDAPI
+	multicall common.Address
+	batches   int
+}
+
+func (a *liveAPI) Call(args struct {
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	if args.To != a.multicall {
+		return nil, errors.New("execution reverted")
+	}
+	a.batches++
+
+	mc, err := abi.JSON(strings.NewReader(aggregate3TestABI))
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	in, err := mc.Methods["aggregate3"].Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := in[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	out := make([]result, len(calls))
+	for i, c := range calls {
+		v, err := verifier.Methods["dataSetLive"].Inputs.Unpack(c.CallData[4:])
+		if err != nil {
+			return nil, err
+		}
+		id := v[0].(*big.Int)
+		if id.Int64() >= 1000 {
+			continue
+		}
+		ret, err := verifier.Methods["dataSetLive"].Outputs.Pack(id.Bit(0) == 0)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = result{Success: true, ReturnData: ret}
+	}
+	return mc.Methods["aggregate3"].Outputs.Pack(out)
+}
+
+func TestManager_DataSetsLive(t *testing.T) {
+	api := &liveAPI{multicall: constants.Multicall3Addresses[constants.NetworkCalibration]}
+	m := newTestManager(t, api, nil)
+
+	live, err := m.DataSetsLive(context.Background(), []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(7), big.NewInt(10)})
+	if err != nil {
+		t.Fatalf("DataSetsLive failed: %v", err)
+	}
+	want := map[string]bool{"1": false, "2": true, "7": false, "10": true}
+	if len(live) != len(want) {
+		t.Errorf("got %d results, want %d", len(live), len(want))
+	}
+	for id, w := range want {
+		if got, ok := live[id]; !ok || got != w {
+			t.Errorf("live[%s] = %v, %v; want %v", id, got, ok, w)
+		}
+	}
+	if api.batches != 1 {
+		t.Errorf("made %d eth_calls, want 1", api.batches)
+	}
+
+	if _, err := m.DataSetsLive(context.Background(), []*big.Int{big.NewInt(2), big.NewInt(1000)}); err == nil {
+		t.Error("expected error for a reverted call")
+	}
+}
+
+// nonceAPI serves eth_chainId and reports pending as the account's pending
+// nonce.
+type nonceAPI struct {
+	chainIDAPI
+	pending uint64
+}
+
+func (a *nonceAPI) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	return hexutil.Uint64(a.pending)
+}
+
+// newTestManager returns a calibration Manager backed by the in-process eth
+// API api. A nil config uses the defaults.
+func newTestManager(t *testing.T, api any, config *ManagerConfig) *Manager {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatalf("Failed to register RPC API: %v", err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(srv))
+	t.Cleanup(func() {
+		client.Close()
+		srv.Stop()
+	})
+	m, err := NewManagerWithConfig(context.Background(), client, NewPrivateKeySigner(privateKey), constants.NetworkCalibration, config)
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig failed: %v", err)
+	}
+	return m
+}
+
+func TestManager_SendWithNonceRetry(t *testing.T) {
+	api := &nonceAPI{pending: 4}
+	m := newTestManager(t, api, nil)
+	ctx := context.Background()
+	tx := types.NewTx(&types.LegacyTx{})
+
+	t.Run("retries once with a fresh nonce", func(t *testing.T) {
+		// the cache hands out 5 next, but another process has used 5 and 6
+		if _, err := m.nonceManager.GetNonce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		m.nonceManager.MarkConfirmed(4)
+		api.pending = 7
+
+		var tried []uint64
+		_, nonce, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+			tried = append(tried, nonce)
+			if nonce < 7 {
+				return nil, errors.New("nonce too low: next nonce 7, tx nonce 5")
+			}
+			return tx, nil
+		})
+		if err != nil {
+			t.Fatalf("sendWithNonceRetry failed: %v", err)
+		}
+		if nonce != 7 || len(tried) != 2 {
+			t.Errorf("sent with nonce %d after trying %v, want 7 on the second attempt", nonce, tried)
+		}
+		m.nonceManager.MarkConfirmed(nonce)
+	})
+
+	t.Run("gives up after one retry", func(t *testing.T) {
+		m.nonceManager.Reset()
+		attempts := 0
+		_, _, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+			attempts++
+			return nil, errors.New("nonce too low")
+		})
+		if err == nil || attempts != 2 {
+			t.Errorf("got err %v after %d attempts, want an error after 2", err, attempts)
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		attempts := 0
+		_, _, err := m.sendWithNonceRetry(ctx, func(nonce uint64) (*types.Transaction, error) {
+			attempts++
+			return nil, errors.New("insufficient funds")
+		})
+		if err == nil || attempts != 1 {
+			t.Errorf("got err %v after %d attempts, want an error after 1", err, attempts)
+		}
+	})
+}
+
+func TestManager_PendingBacklog(t *testing.T) {
+	var warned []int
+	config := DefaultManagerConfig()
+	config.PendingWarnThreshold = 2
+	config.OnPendingBacklog = func(pending int) { warned = append(warned, pending) }
+	m := newTestManager(t, &nonceAPI{}, &config)
+	ctx := context.Background()
+
+	send := func(nonce uint64) (*types.Transaction, error) {
+		return types.NewTx(&types.LegacyTx{Nonce: nonce}), nil
+	}
+	for i := 0; i < 4; i++ {
+		if _, _, err := m.sendWithNonceRetry(ctx, send); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := m.PendingTransactionCount(); got != 4 {
+		t.Errorf("PendingTransactionCount() = %d, want 4", got)
+	}
+	if len(warned) != 2 || warned[0] != 3 || warned[1] != 4 {
+		t.Errorf("OnPendingBacklog calls = %v, want [3 4]", warned)
+	}
+
+	m.nonceManager.MarkConfirmed(0)
+	if got := m.PendingTransactionCount(); got != 3 {
+		t.Errorf("PendingTransactionCount() after confirm = %d, want 3", got)
+	}
+}
+
+// deleteAPI mines every raw transaction immediately with status, answers
+// dataSetLive with live, and reverts replays of deleteDataSet with "not
+// owner".
+type deleteAPI struct {
+	nonceAPI
+	status uint64
+	live   bool
+	sent   int
+	// data is the calldata of the last transaction sent.
+	data []byte
+}
+
+func (a *deleteAPI) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1000))
+}
+
+func (a *deleteAPI) GetBlockByNumber(number string, full bool) *types.Header {
+	return &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(0)}
+}
+
+func (a *deleteAPI) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	a.sent++
+	a.data = tx.Data()
+	return tx.Hash(), nil
+}
+
+func (a *deleteAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return &types.Receipt{
+		Status:      a.status,
+		TxHash:      hash,
+		BlockNumber: big.NewInt(101),
+		Logs:        []*types.Log{},
+	}
+}
+
+func (a *deleteAPI) Call(args struct {
+	Input hexutil.Bytes `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := verifier.MethodById(args.Input)
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "dataSetLive" {
+		return method.Outputs.Pack(a.live)
+	}
+	return nil, errors.New("execution reverted: not owner")
+}
+
+func TestManager_DeleteProofSet(t *testing.T) {
+	newManager := func(t *testing.T, api *deleteAPI) *Manager {
+		config := DefaultManagerConfig()
+		config.DefaultGasLimit = 1000000
+		config.VerifyDeletion = true
+		return newTestManager(t, api, &config)
+	}
+	ctx := context.Background()
+
+	t.Run("reverted", func(t *testing.T) {
+		m := newManager(t, &deleteAPI{status: types.ReceiptStatusFailed})
+		err := m.DeleteProofSet(ctx, big.NewInt(7), nil)
+		if !errors.Is(err, ErrTransactionReverted) || !strings.Contains(err.Error(), "not owner") {
+			t.Errorf("DeleteProofSet() error = %v, want ErrTransactionReverted with the revert reason", err)
+		}
+		if got := m.PendingTransactionCount(); got != 0 {
+			t.Errorf("PendingTransactionCount() = %d, want 0 once the revert is mined", got)
+		}
+	})
+
+	t.Run("still live", func(t *testing.T) {
+		m := newManager(t, &deleteAPI{status: types.ReceiptStatusSuccessful, live: true})
+		if err := m.DeleteProofSet(ctx, big.NewInt(7), nil); !errors.Is(err, ErrProofSetStillLive) {
+			t.Errorf("DeleteProofSet() error = %v, want ErrProofSetStillLive", err)
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		api := &deleteAPI{status: types.ReceiptStatusSuccessful}
+		m := newManager(t, api)
+		if err := m.DeleteProofSet(ctx, big.NewInt(7), nil); err != nil {
+			t.Errorf("DeleteProofSet() error = %v", err)
+		}
+		if api.sent != 1 {
+			t.Errorf("sent %d transactions, want 1", api.sent)
+		}
+	})
+}
+
+// listSet is a data set served by listAPI.
+type listSet struct {
+	live  bool
+	owner common.Address
+}
+
+// listEvent is a DataSetCreated (from zero) or StorageProviderChanged log.
+type listEvent struct {
+	block uint64
+	id    int64
+	from  common.Address
+	to    common.Address
+}
+
+// listAPI serves a verifier whose data sets are sets, with events as its
+// logs. Multicall3 is deployed only if multicall is set.
+type listAPI struct {
+	chainIDAPI
+	multicall bool
+	sets      map[int64]listSet
+	events    []listEvent
+	calls     int
+	queries   [][2]uint64
+}
+
+func (a *listAPI) BlockNumber() hexutil.Uint64 {
+	return 500
+}
+
+func (a *listAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	if a.multicall {
+		return hexutil.Bytes{0x60, 0x80}
+	}
+	return nil
+}
+
+func (a *listAPI) GetLogs(filter struct {
+	FromBlock hexutil.Uint64  `json:"fromBlock"`
+	ToBlock   hexutil.Uint64  `json:"toBlock"`
+	Topics    [][]common.Hash `json:"topics"`
+}) []types.Log {
+	from, to := uint64(filter.FromBlock), uint64(filter.ToBlock)
+	a.queries = append(a.queries, [2]uint64{from, to})
+	created := crypto.Keccak256Hash([]byte("DataSetCreated(uint256,address)"))
+
+	var logs []types.Log
+	for _, e := range a.events {
+		if e.block < from || e.block > to {
+			continue
+		}
+		idTopic := common.BigToHash(big.NewInt(e.id))
+		var topics []common.Hash
+		if e.from == (common.Address{}) {
+			topics = []common.Hash{created, idTopic, common.BytesToHash(e.to.Bytes())}
+		} else {
+			topics = []common.Hash{
+				crypto.Keccak256Hash([]byte("StorageProviderChanged(uint256,address,address)")),
+				idTopic,
+				common.BytesToHash(e.from.Bytes()),
+				common.BytesToHash(e.to.Bytes()),
+			}
+		}
+		if topics[0] != filter.Topics[0][0] || len(topics) != len(filter.Topics) {
+			continue
+		}
+		want := filter.Topics[len(filter.Topics)-1]
+		if len(want) > 0 && topics[len(topics)-1] != want[0] {
+			continue
+		}
+		logs = append(logs, types.Log{BlockNumber: e.block, Topics: topics, Data: []byte{}})
+	}
+	return logs
+}
+
+func (a *listAPI) Call(args struct {
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	a.calls++
+	if block != "0x1f4" {
+		return nil, errors.New("call not pinned to the listing block")
+	}
+	if args.To != constants.Multicall3Addresses[constants.NetworkCalibration] {
+		return a.view(args.Input)
+	}
+
+	mc, err := abi.JSON(strings.NewReader(aggregate3TestABI))
+	if err != nil {
+		return nil, err
+	}
+	in, err := mc.Methods["aggregate3"].Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := in[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	out := make([]result, len(calls))
+	for i, c := range calls {
+		ret, err := a.view(c.CallData)
+		out[i] = result{Success: err == nil, ReturnData: ret}
+	}
+	return mc.Methods["aggregate3"].Outputs.Pack(out)
+}
+
+func (a *listAPI) view(input []byte) ([]byte, error) {
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := verifier.MethodById(input)
+	if err != nil {
+		return nil, err
+	}
+	v, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	id := v[0].(*big.Int)
+	set, ok := a.sets[id.Int64()]
+	if method.Name == "dataSetLive" {
+		return method.Outputs.Pack(ok && set.live)
+	}
+	if !ok || !set.live {
+		return nil, errors.New("execution reverted")
+	}
+	switch method.Name {
+	case "getDataSetStorageProvider":
+		return method.Outputs.Pack(set.owner, common.Address{})
+	case "getDataSetListener":
+		return method.Outputs.Pack(common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f"))
+	case "getDataSetLeafCount":
+		return method.Outputs.Pack(new(big.Int).Mul(id, big.NewInt(10)))
+	case "getActivePieceCount":
+		return method.Outputs.Pack(id)
+	case "getNextPieceId":
+		return method.Outputs.Pack(new(big.Int).Add(id, big.NewInt(1)))
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestManager_ListProofSets(t *testing.T) {
+	owner := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	newAPI := func(multicall bool) *listAPI {
+		return &listAPI{
+			multicall: multicall,
+			sets: map[int64]listSet{
+				1: {live: true, owner: owner},
+				3: {live: true, owner: owner},
+				4: {live: true, owner: other},
+				5: {live: true, owner: other},
+			},
+			events: []listEvent{
+				{block: 10, id: 1, to: owner},
+				{block: 120, id: 2, to: owner}, // since deleted
+				{block: 250, id: 3, to: other},
+				{block: 260, id: 3, from: other, to: owner},
+				{block: 300, id: 4, to: owner},
+				{block: 480, id: 4, from: owner, to: other},
+				{block: 490, id: 5, to: other},
+			},
+		}
+	}
+	check := func(t *testing.T, proofSets []*ProofSet) {
+		t.Helper()
+		if len(proofSets) != 2 || proofSets[0].ID.Int64() != 1 || proofSets[1].ID.Int64() != 3 {
+			t.Fatalf("ListProofSets() = %v, want proof sets 1 and 3", proofSets)
+		}
+		ps := proofSets[1]
+		if !ps.Live || ps.StorageProvider != owner || ps.LeafCount != 30 || ps.ActivePieces != 3 || ps.NextPieceID != 4 {
+			t.Errorf("proof set 3 = %+v", ps)
+		}
+	}
+
+	t.Run("multicall", func(t *testing.T) {
+		api := newAPI(true)
+		proofSets, err := newTestManager(t, api, nil).ListProofSets(context.Background(), owner)
+		if err != nil {
+			t.Fatalf("ListProofSets() error = %v", err)
+		}
+		check(t, proofSets)
+		if api.calls != 2 {
+			t.Errorf("made %d eth_calls, want 2 batches", api.calls)
+		}
+		if len(api.queries) != 2 || api.queries[0] != [2]uint64{0, 500} {
+			t.Errorf("log queries = %v, want one per event over 0-500", api.queries)
+		}
+	})
+
+	t.Run("without multicall in block ranges", func(t *testing.T) {
+		api := newAPI(false)
+		config := DefaultManagerConfig()
+		config.EventsFromBlock = 5
+		config.LogBlockRange = 200
+		proofSets, err := newTestManager(t, api, &config).ListProofSets(context.Background(), owner)
+		if err != nil {
+			t.Fatalf("ListProofSets() error = %v", err)
+		}
+		check(t, proofSets)
+		// dataSetLive for 1-4, then five detail calls for each of 1, 3 and 4
+		if api.calls != 4+3*5 {
+			t.Errorf("made %d eth_calls, want %d", api.calls, 4+3*5)
+		}
+		want := [][2]uint64{{5, 204}, {5, 204}, {205, 404}, {205, 404}, {405, 500}, {405, 500}}
+		if len(api.queries) != len(want) {
+			t.Fatalf("log queries = %v, want %v", api.queries, want)
+		}
+		for i := range want {
+			if api.queries[i] != want[i] {
+				t.Errorf("log queries = %v, want %v", api.queries, want)
+				break
+			}
+		}
+	})
+}
+
+// noMulticallAPI serves a chain with no contract code anywhere, answering
+// direct dataSetLive calls like liveAPI.
+type noMulticallAPI struct {
+	chainIDAPI
+	calls int
+}
+
+func (a *noMulticallAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return nil
+}
+
+func (a *noMulticallAPI) Call(args struct {
+	Input hexutil.Bytes `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	a.calls++
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	v, err := verifier.Methods["dataSetLive"].Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Methods["dataSetLive"].Outputs.Pack(v[0].(*big.Int).Bit(0) == 0)
+}
+
+func TestManager_DataSetsLiveWithoutMulticall(t *testing.T) {
+	api := &noMulticallAPI{}
+	var unavailable []common.Address
+	config := DefaultManagerConfig()
+	config.OnMulticall3Unavailable = func(addr common.Address) { unavailable = append(unavailable, addr) }
+	m := newTestManager(t, api, &config)
+
+	for i := 0; i < 2; i++ {
+		live, err := m.DataSetsLive(context.Background(), []*big.Int{big.NewInt(1), big.NewInt(2)})
+		if err != nil {
+			t.Fatalf("DataSetsLive() error = %v", err)
+		}
+		if live["1"] || !live["2"] {
+			t.Errorf("DataSetsLive() = %v, want 2 live and 1 not", live)
+		}
+	}
+	if api.calls != 4 {
+		t.Errorf("made %d eth_calls, want one per proof set", api.calls)
+	}
+	if len(unavailable) != 1 || unavailable[0] != constants.Multicall3Addresses[constants.NetworkCalibration] {
+		t.Errorf("OnMulticall3Unavailable calls = %v, want one for the network Multicall3", unavailable)
+	}
+}
+
+type finderFunc func(ctx context.Context, payer common.Address, clientDataSetID *big.Int) (*big.Int, error)
+
+func (f finderFunc) FindDataSetByClientID(ctx context.Context, payer common.Address, clientDataSetID *big.Int) (*big.Int, error) {
+	return f(ctx, payer, clientDataSetID)
+}
+
+func TestManager_CreateProofSetIdempotent(t *testing.T) {
+	payer := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	newManager := func(t *testing.T, api *offlineAPI, finder DataSetFinder) *Manager {
+		config := DefaultManagerConfig()
+		config.DefaultGasLimit = 1000000
+		config.DataSetFinder = finder
+		return newTestManager(t, api, &config)
+	}
+	// client data set 5 of payer is proof set 12
+	finder := finderFunc(func(ctx context.Context, p common.Address, clientDataSetID *big.Int) (*big.Int, error) {
+		if p == payer && clientDataSetID.Int64() == 5 {
+			return big.NewInt(12), nil
+		}
+		return nil, nil
+	})
+	ctx := context.Background()
+
+	t.Run("existing", func(t *testing.T) {
+		api := &offlineAPI{deleteAPI{status: types.ReceiptStatusSuccessful, live: true}}
+		m := newManager(t, api, finder)
+		result, err := m.CreateProofSet(ctx, CreateProofSetOptions{ClientDataSetID: big.NewInt(5), Payer: payer})
+		if err != nil {
+			t.Fatalf("CreateProofSet() error = %v", err)
+		}
+		if !result.Existing || result.ProofSetID.Int64() != 12 || api.sent != 0 {
+			t.Errorf("CreateProofSet() = %+v after %d sends, want existing proof set 12 and no send", result, api.sent)
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		api := &offlineAPI{deleteAPI{status: types.ReceiptStatusSuccessful}}
+		m := newManager(t, api, finder)
+		if _, err := m.CreateProofSet(ctx, CreateProofSetOptions{ClientDataSetID: big.NewInt(5), Payer: payer}); err == nil || api.sent != 0 {
+			t.Errorf("CreateProofSet() error = %v after %d sends, want an error and no send", err, api.sent)
+		}
+	})
+
+	t.Run("new", func(t *testing.T) {
+		api := &offlineAPI{deleteAPI{status: types.ReceiptStatusSuccessful}}
+		m := newManager(t, api, finder)
+		result, err := m.CreateProofSet(ctx, CreateProofSetOptions{ClientDataSetID: big.NewInt(6), Payer: payer})
+		if err != nil {
+			t.Fatalf("CreateProofSet() error = %v", err)
+		}
+		if result.Existing || result.ProofSetID.Int64() != 42 || api.sent != 1 {
+			t.Errorf("CreateProofSet() = %+v after %d sends, want new proof set 42", result, api.sent)
+		}
+	})
+
+	t.Run("no finder", func(t *testing.T) {
+		m := newManager(t, &offlineAPI{}, nil)
+		if _, err := m.CreateProofSet(ctx, CreateProofSetOptions{ClientDataSetID: big.NewInt(5), Payer: payer}); err == nil {
+			t.Error("CreateProofSet() expected error without a DataSetFinder")
+		}
+	})
+}
+
+// challengeEpochAPI answers getNextChallengeEpoch with epoch.
+type challengeEpochAPI struct {
+	chainIDAPI
+	epoch uint64
+}
+
+func (a *challengeEpochAPI) Call(args struct {
+	Input hexutil.Bytes `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	verifier, err := contracts.PDPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Methods["getNextChallengeEpoch"].Outputs.Pack(new(big.Int).SetUint64(a.epoch))
+}
+
+func TestManager_WaitForChallengeEpochAdvance(t *testing.T) {
+	m := newTestManager(t, &challengeEpochAPI{epoch: 1200}, nil)
+
+	epoch, err := m.WaitForChallengeEpochAdvance(context.Background(), big.NewInt(3), 1000, time.Second)
+	if err != nil || epoch != 1200 {
+		t.Errorf("WaitForChallengeEpochAdvance() = %d, %v; want 1200", epoch, err)
+	}
+
+	if _, err := m.WaitForChallengeEpochAdvance(context.Background(), big.NewInt(3), 1200, 50*time.Millisecond); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitForChallengeEpochAdvance() error = %v, want ErrWaitTimeout", err)
+	}
+}
@@ -0,0 +1,48 @@
+package pdp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Metadata limits enforced by the FilecoinWarmStorageService contract.
+const (
+	MaxMetadataKeyLength   = 32
+	MaxMetadataValueLength = 128
+	MaxDataSetMetadataKeys = 10
+	MaxPieceMetadataKeys   = 5
+)
+
+// ErrInvalidMetadata is returned when metadata would be rejected on-chain.
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// ValidateMetadata checks that every key is non-empty and unique and that keys
+// and values fit the contract's byte-length limits.
+func ValidateMetadata(entries []MetadataEntry) error {
+	seen := make(map[string]bool, len(entries))
+	for i, m := range entries {
+		if m.Key == "" {
+			return fmt.Errorf("%w: entry %d has an empty key", ErrInvalidMetadata, i)
+		}
+		if len(m.Key) > MaxMetadataKeyLength {
+			return fmt.Errorf("%w: key %q is %d bytes, max %d", ErrInvalidMetadata, m.Key, len(m.Key), MaxMetadataKeyLength)
+		}
+		if len(m.Value) > MaxMetadataValueLength {
+			return fmt.Errorf("%w: value for key %q is %d bytes, max %d", ErrInvalidMetadata, m.Key, len(m.Value), MaxMetadataValueLength)
+		}
+		if seen[m.Key] {
+			return fmt.Errorf("%w: duplicate key %q", ErrInvalidMetadata, m.Key)
+		}
+		seen[m.Key] = true
+	}
+	return nil
+}
+
+// validateMetadataCount applies ValidateMetadata and the per-set or per-piece
+// limit on the number of keys.
+func validateMetadataCount(entries []MetadataEntry, maxKeys int) error {
+	if len(entries) > maxKeys {
+		return fmt.Errorf("%w: %d keys, max %d", ErrInvalidMetadata, len(entries), maxKeys)
+	}
+	return ValidateMetadata(entries)
+}
@@ -0,0 +1,58 @@
+package pdp
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ipfs/go-cid"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []MetadataEntry
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", []MetadataEntry{{Key: "title", Value: "x"}, {Key: "withIPFSIndexing", Value: ""}}, false},
+		{"max lengths", []MetadataEntry{{Key: strings.Repeat("k", MaxMetadataKeyLength), Value: strings.Repeat("v", MaxMetadataValueLength)}}, false},
+		{"empty key", []MetadataEntry{{Key: "", Value: "x"}}, true},
+		{"duplicate key", []MetadataEntry{{Key: "a", Value: "1"}, {Key: "a", Value: "2"}}, true},
+		{"key too long", []MetadataEntry{{Key: strings.Repeat("k", MaxMetadataKeyLength+1)}}, true},
+		{"value too long", []MetadataEntry{{Key: "a", Value: strings.Repeat("v", MaxMetadataValueLength+1)}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidMetadata) {
+				t.Errorf("error %v does not wrap ErrInvalidMetadata", err)
+			}
+		})
+	}
+}
+
+func TestSign_RejectsInvalidMetadata(t *testing.T) {
+	auth := testAuthHelper(t)
+	payee := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	tooMany := make([]MetadataEntry, MaxDataSetMetadataKeys+1)
+	for i := range tooMany {
+		tooMany[i] = MetadataEntry{Key: string(rune('a' + i))}
+	}
+	if _, err := auth.SignCreateDataSet(big.NewInt(1), payee, tooMany); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("SignCreateDataSet() error = %v, want ErrInvalidMetadata", err)
+	}
+
+	pieceCID := mustCID(t, testCIDA)
+	dup := [][]MetadataEntry{{{Key: "a"}, {Key: "a"}}}
+	if _, err := auth.SignAddPieces(big.NewInt(1), big.NewInt(1), []cid.Cid{pieceCID}, dup); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("SignAddPieces() error = %v, want ErrInvalidMetadata", err)
+	}
+}
@@ -0,0 +1,117 @@
+package pdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrSignedTxMismatch is returned by SubmitSignedTx for a transaction that
+// was not signed by the manager's address or is not addressed to its
+// PDPVerifier contract.
+var ErrSignedTxMismatch = errors.New("signed transaction does not match manager")
+
+// BuildCreateProofSetTx prepares the createDataSet transaction
+// CreateProofSet would send, without signing it, for signing on an offline
+// machine. Nonce, gas limit and fees are filled in for the manager's
+// address; the manager's signer is not used, so it only needs to report
+// that address. The nonce stays reserved until SubmitSignedTx is called
+// with the signed transaction, or ReleaseUnsignedTx if it is abandoned.
+func (m *Manager) BuildCreateProofSetTx(ctx context.Context, opts CreateProofSetOptions) (*types.Transaction, error) {
+	value := opts.Value
+	if value == nil {
+		value = SybilFee
+	}
+
+	extraData, err := createDataSetExtraData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := m.nonceManager.GetNonce(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	auth := &bind.TransactOpts{
+		From:  m.address,
+		Nonce: new(big.Int).SetUint64(nonce),
+		Value: value,
+		// leave the transaction unsigned
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+		GasLimit: m.config.DefaultGasLimit,
+		Context:  ctx,
+		NoSend:   true,
+	}
+	tx, err := m.createDataSet(auth, opts.Listener, extraData)
+	if err != nil {
+		m.nonceManager.MarkFailed(nonce)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ReleaseUnsignedTx gives back the nonce of a transaction from
+// BuildCreateProofSetTx that will not be submitted.
+func (m *Manager) ReleaseUnsignedTx(tx *types.Transaction) {
+	m.nonceManager.MarkFailed(tx.Nonce())
+}
+
+// SubmitSignedTx broadcasts a transaction from BuildCreateProofSetTx that
+// has been signed externally, waits for it to be mined and returns the new
+// proof set. It fails with ErrSignedTxMismatch if the signature is not from
+// the manager's address or the transaction is not addressed to its
+// PDPVerifier, and with ErrTransactionReverted if it is mined but reverts.
+func (m *Manager) SubmitSignedTx(ctx context.Context, signed *types.Transaction) (*ProofSetResult, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(m.chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignedTxMismatch, err)
+	}
+	if from != m.address {
+		return nil, fmt.Errorf("%w: signed by %s, want %s", ErrSignedTxMismatch, from.Hex(), m.address.Hex())
+	}
+	if to := signed.To(); to == nil || *to != m.contractAddr {
+		return nil, fmt.Errorf("%w: not addressed to PDPVerifier %s", ErrSignedTxMismatch, m.contractAddr.Hex())
+	}
+
+	if err := m.client.SendTransaction(ctx, signed); err != nil {
+		// rejected before it reached the mempool; the nonce is free again
+		m.nonceManager.MarkFailed(signed.Nonce())
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	receipt, err := txutil.WaitForReceipt(ctx, m.client, signed.Hash(), defaultReceiptTimeout)
+	if receipt == nil {
+		// Error waiting for receipt - transaction may be pending, don't release nonce
+		return nil, fmt.Errorf("failed to wait for receipt: %w", err)
+	}
+
+	m.nonceManager.MarkConfirmed(signed.Nonce())
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		reason, _ := txutil.RevertReason(ctx, m.client, signed, receipt.BlockNumber)
+		if reason == "" {
+			reason = "unknown reason"
+		}
+		return nil, fmt.Errorf("%w: %s: %s", ErrTransactionReverted, signed.Hash().Hex(), reason)
+	}
+
+	proofSetID, err := m.extractProofSetIDFromReceipt(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract proof set ID: %w", err)
+	}
+
+	return &ProofSetResult{
+		ProofSetID:      proofSetID,
+		TransactionHash: signed.Hash(),
+		Receipt:         receipt,
+	}, nil
+}
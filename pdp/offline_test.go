@@ -0,0 +1,98 @@
+package pdp
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// offlineAPI is deleteAPI whose receipts carry a DataSetCreated event.
+type offlineAPI struct {
+	deleteAPI
+}
+
+func (a *offlineAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	receipt := a.deleteAPI.GetTransactionReceipt(hash)
+	receipt.Logs = []*types.Log{{
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("DataSetCreated(uint256,address)")),
+			common.BigToHash(big.NewInt(42)),
+			{},
+		},
+	}}
+	return receipt
+}
+
+func TestManager_BuildCreateProofSetTx(t *testing.T) {
+	api := &offlineAPI{deleteAPI{status: types.ReceiptStatusSuccessful}}
+	api.pending = 3
+	config := DefaultManagerConfig()
+	config.DefaultGasLimit = 1000000
+	// the manager's signer only supplies the address
+	m := newTestManager(t, api, &config)
+	ctx := context.Background()
+
+	sign := func(t *testing.T, tx *types.Transaction, s Signer) *types.Transaction {
+		t.Helper()
+		opts, err := s.Transactor(big.NewInt(constants.ChainIDCalibration))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := opts.Signer(opts.From, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tx, err := m.BuildCreateProofSetTx(ctx, CreateProofSetOptions{})
+	if err != nil {
+		t.Fatalf("BuildCreateProofSetTx() error = %v", err)
+	}
+	if tx.Nonce() != 3 || tx.Gas() != 1000000 || tx.Value().Cmp(SybilFee) != 0 || *tx.To() != m.contractAddr {
+		t.Errorf("unsigned tx = nonce %d, gas %d, value %s, to %s", tx.Nonce(), tx.Gas(), tx.Value(), tx.To().Hex())
+	}
+	if v, r, s := tx.RawSignatureValues(); v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		t.Error("BuildCreateProofSetTx() returned a signed transaction")
+	}
+	if got := m.PendingTransactionCount(); got != 1 {
+		t.Errorf("PendingTransactionCount() = %d, want the nonce reserved", got)
+	}
+
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SubmitSignedTx(ctx, sign(t, tx, NewPrivateKeySigner(other))); !errors.Is(err, ErrSignedTxMismatch) {
+		t.Errorf("SubmitSignedTx() error = %v, want ErrSignedTxMismatch for a foreign signature", err)
+	}
+	if api.sent != 0 {
+		t.Fatalf("sent %d transactions, want none", api.sent)
+	}
+
+	result, err := m.SubmitSignedTx(ctx, sign(t, tx, m.signer))
+	if err != nil {
+		t.Fatalf("SubmitSignedTx() error = %v", err)
+	}
+	if result.ProofSetID.Int64() != 42 || api.sent != 1 {
+		t.Errorf("SubmitSignedTx() = proof set %s after %d sends, want 42 after 1", result.ProofSetID, api.sent)
+	}
+	if got := m.PendingTransactionCount(); got != 0 {
+		t.Errorf("PendingTransactionCount() = %d, want 0 once mined", got)
+	}
+
+	abandoned, err := m.BuildCreateProofSetTx(ctx, CreateProofSetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ReleaseUnsignedTx(abandoned)
+	if got := m.PendingTransactionCount(); got != 0 {
+		t.Errorf("PendingTransactionCount() = %d after release, want 0", got)
+	}
+}
@@ -0,0 +1,464 @@
+// Package pdptest provides an in-memory implementation of Curio's /pdp/*
+// HTTP API for tests. It stores uploaded pieces, assigns data set and piece
+// IDs, and reports every transaction as confirmed immediately, so flows that
+// poll for status (WaitForDataSetCreation, WaitForPieceAddition,
+// WaitForPiece) complete on their first request.
+package pdptest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/filecoin-project/go-commp-utils/v2/writer"
+	"github.com/ipfs/go-cid"
+)
+
+// Server is a mock PDP provider backed by an httptest.Server.
+type Server struct {
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	txCounter     uint64
+	nextDataSetID int
+	pieces        map[string][]byte
+	uploads       map[string][]byte
+	dataSets      map[int]*dataSet
+	creations     map[string]int
+	additions     map[string]*pdp.PieceAdditionStatus
+}
+
+type dataSet struct {
+	id          int
+	nextPieceID int
+	pieces      []pdp.PieceInfo
+}
+
+// NewServer starts a mock PDP server that is shut down when the test ends.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+	s := New()
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// New starts a mock PDP server. Callers must Close it.
+func New() *Server {
+	s := &Server{
+		nextDataSetID: 1,
+		pieces:        make(map[string][]byte),
+		uploads:       make(map[string][]byte),
+		dataSets:      make(map[int]*dataSet),
+		creations:     make(map[string]int),
+		additions:     make(map[string]*pdp.PieceAdditionStatus),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.route))
+	return s
+}
+
+// URL is the base URL to pass to pdp.NewServer.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client returns a pdp.Server pointed at the mock.
+func (s *Server) Client() *pdp.Server {
+	return pdp.NewServer(s.srv.URL)
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Piece returns the stored bytes for a piece.
+func (s *Server) Piece(pieceCID cid.Cid) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.pieces[pieceCID.String()]
+	return data, ok
+}
+
+// DataSet returns the current state of a data set.
+func (s *Server) DataSet(id int) (*pdp.DataSetData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ds, ok := s.dataSets[id]
+	if !ok {
+		return nil, false
+	}
+	return ds.data(), true
+}
+
+func (ds *dataSet) data() *pdp.DataSetData {
+	return &pdp.DataSetData{
+		ID:     ds.id,
+		Pieces: append([]pdp.PieceInfo(nil), ds.pieces...),
+	}
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/pdp")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && path == "/ping":
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodPost && path == "/data-sets":
+		s.handleCreateDataSet(w, r, false)
+	case r.Method == http.MethodPost && path == "/data-sets/create-and-add":
+		s.handleCreateDataSet(w, r, true)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "data-sets" && parts[1] == "created":
+		s.handleCreationStatus(w, parts[2])
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "data-sets":
+		s.handleGetDataSet(w, parts[1])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "data-sets" && parts[2] == "pieces":
+		s.handleAddPieces(w, r, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 5 && parts[0] == "data-sets" && parts[2] == "pieces" && parts[3] == "added":
+		s.handleAdditionStatus(w, parts[4])
+
+	case r.Method == http.MethodPost && path == "/piece/uploads":
+		s.handleCreateUpload(w)
+	case r.Method == http.MethodPut && len(parts) == 3 && parts[0] == "piece" && parts[1] == "uploads":
+		s.handleUpload(w, r, parts[2])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "piece" && parts[1] == "uploads":
+		s.handleFinalize(w, r, parts[2])
+	case r.Method == http.MethodGet && path == "/piece":
+		s.handleFindPiece(w, r.URL.Query().Get("pieceCid"))
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && len(parts) == 2 && parts[0] == "piece":
+		s.handleDownload(w, r, parts[1])
+	case r.Method == http.MethodPost && path == "/piece/pull":
+		s.handlePull(w, r)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleCreateDataSet(w http.ResponseWriter, r *http.Request, withPieces bool) {
+	var req pdp.CreateAndAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if withPieces {
+		if err := s.checkPiecesLocked(req.Pieces); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ds := &dataSet{id: s.nextDataSetID}
+	s.nextDataSetID++
+	s.dataSets[ds.id] = ds
+
+	txHash := s.nextTxHashLocked()
+	s.creations[txHash] = ds.id
+	if withPieces {
+		s.addPiecesLocked(ds, req.Pieces, txHash)
+	}
+
+	w.Header().Set("Location", "/pdp/data-sets/created/"+txHash)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleCreationStatus(w http.ResponseWriter, txHash string) {
+	s.mu.Lock()
+	id, ok := s.creations[txHash]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	confirmed := true
+	writeJSON(w, pdp.DataSetCreationStatus{
+		CreateMessageHash: txHash,
+		DataSetCreated:    true,
+		Service:           "pdptest",
+		TxStatus:          "confirmed",
+		OK:                &confirmed,
+		DataSetID:         &id,
+	})
+}
+
+func (s *Server) handleGetDataSet(w http.ResponseWriter, idStr string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "invalid data set ID", http.StatusBadRequest)
+		return
+	}
+
+	data, ok := s.DataSet(id)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, data)
+}
+
+func (s *Server) handleAddPieces(w http.ResponseWriter, r *http.Request, idStr string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "invalid data set ID", http.StatusBadRequest)
+		return
+	}
+
+	var req pdp.AddPiecesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, ok := s.dataSets[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := s.checkPiecesLocked(req.Pieces); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	txHash := s.nextTxHashLocked()
+	s.addPiecesLocked(ds, req.Pieces, txHash)
+
+	w.Header().Set("Location", fmt.Sprintf("/pdp/data-sets/%d/pieces/added/%s", id, txHash))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleAdditionStatus(w http.ResponseWriter, txHash string) {
+	s.mu.Lock()
+	status, ok := s.additions[txHash]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, status)
+}
+
+func (s *Server) handleCreateUpload(w http.ResponseWriter) {
+	id := newUUID()
+
+	s.mu.Lock()
+	s.uploads[id] = nil
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/pdp/piece/uploads/"+id)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request, id string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.uploads[id]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.uploads[id] = data
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleFinalize(w http.ResponseWriter, r *http.Request, id string) {
+	var req struct {
+		PieceCID string `json:"pieceCid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.uploads[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	computed, err := pieceCIDOf(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if computed.String() != req.PieceCID {
+		http.Error(w, fmt.Sprintf("piece CID mismatch: computed %s, got %s", computed, req.PieceCID), http.StatusBadRequest)
+		return
+	}
+
+	delete(s.uploads, id)
+	s.pieces[req.PieceCID] = data
+	writeJSON(w, map[string]string{"pieceCid": req.PieceCID})
+}
+
+func (s *Server) handleFindPiece(w http.ResponseWriter, pieceCID string) {
+	s.mu.Lock()
+	_, ok := s.pieces[pieceCID]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]string{"pieceCid": pieceCID})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request, pieceCID string) {
+	s.mu.Lock()
+	data, ok := s.pieces[pieceCID]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	// ServeContent answers Range requests with 206 like Curio does
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// handlePull fetches each source URL synchronously and reports the result,
+// so a single PullPieces call reaches a terminal status.
+func (s *Server) handlePull(w http.ResponseWriter, r *http.Request) {
+	var req pdp.PullPiecesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := pdp.PullPiecesResponse{Status: pdp.PullStatusComplete}
+	for _, piece := range req.Pieces {
+		status := pdp.PullStatusComplete
+		s.mu.Lock()
+		_, ok := s.pieces[piece.PieceCID]
+		s.mu.Unlock()
+		if !ok {
+			if err := s.fetchPiece(piece); err != nil {
+				status = pdp.PullStatusFailed
+				resp.Status = pdp.PullStatusFailed
+			}
+		}
+		resp.Pieces = append(resp.Pieces, pdp.PullPieceStatus{PieceCID: piece.PieceCID, Status: status})
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) fetchPiece(piece pdp.PullPieceInput) error {
+	resp, err := http.Get(piece.SourceURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("source returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	computed, err := pieceCIDOf(data)
+	if err != nil {
+		return err
+	}
+	if computed.String() != piece.PieceCID {
+		return fmt.Errorf("piece CID mismatch: computed %s, want %s", computed, piece.PieceCID)
+	}
+
+	s.mu.Lock()
+	s.pieces[piece.PieceCID] = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) checkPiecesLocked(pieces []pdp.PieceData) error {
+	for _, p := range pieces {
+		if _, ok := s.pieces[p.PieceCID]; !ok {
+			return fmt.Errorf("piece %s has not been uploaded", p.PieceCID)
+		}
+	}
+	return nil
+}
+
+func (s *Server) addPiecesLocked(ds *dataSet, pieces []pdp.PieceData, txHash string) {
+	confirmed := true
+	status := &pdp.PieceAdditionStatus{
+		TxHash:       txHash,
+		TxStatus:     "confirmed",
+		DataSetID:    ds.id,
+		PieceCount:   len(pieces),
+		AddMessageOK: &confirmed,
+	}
+	for _, p := range pieces {
+		c, err := cid.Parse(p.PieceCID)
+		if err != nil {
+			continue
+		}
+		ds.pieces = append(ds.pieces, pdp.PieceInfo{
+			PieceID:     ds.nextPieceID,
+			PieceCID:    c,
+			SubPieceCID: c,
+		})
+		status.ConfirmedPieceIDs = append(status.ConfirmedPieceIDs, ds.nextPieceID)
+		ds.nextPieceID++
+	}
+	s.additions[txHash] = status
+}
+
+// nextTxHashLocked returns a deterministic, unique transaction hash.
+func (s *Server) nextTxHashLocked() string {
+	s.txCounter++
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], s.txCounter)
+	return crypto.Keccak256Hash([]byte("pdptest"), buf[:]).Hex()
+}
+
+func pieceCIDOf(data []byte) (cid.Cid, error) {
+	w := &writer.Writer{}
+	if _, err := w.Write(data); err != nil {
+		return cid.Undef, err
+	}
+	sum, err := w.Sum()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return sum.PieceCID, nil
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
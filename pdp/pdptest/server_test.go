@@ -0,0 +1,162 @@
+package pdptest
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/data-preservation-programs/go-synapse/storage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-cid"
+)
+
+func TestServer_StorageUploadRoundTrip(t *testing.T) {
+	mock := NewServer(t)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warmStorage := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	auth := pdp.NewAuthHelperFromKey(key, warmStorage, big.NewInt(31337))
+
+	m := storage.NewManager(auth.Address(), warmStorage, auth, mock.Client(), 0)
+
+	data := bytes.Repeat([]byte("pdptest"), 100)
+	result, err := m.UploadBytes(ctx, data, nil)
+	if err != nil {
+		t.Fatalf("UploadBytes() error = %v", err)
+	}
+	if result.DataSetID != 1 {
+		t.Errorf("DataSetID = %d, want 1", result.DataSetID)
+	}
+	if result.PieceID != 0 {
+		t.Errorf("PieceID = %d, want 0", result.PieceID)
+	}
+	if result.TxHash == "" || result.DataSetCreationTxHash == "" || result.TxHash == result.DataSetCreationTxHash {
+		t.Errorf("tx hashes = add %q, creation %q; want two distinct hashes", result.TxHash, result.DataSetCreationTxHash)
+	}
+
+	got, err := m.Download(ctx, result.PieceCID, nil)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded data does not match upload")
+	}
+
+	stat, err := m.StatPiece(ctx, result.PieceCID)
+	if err != nil {
+		t.Fatalf("StatPiece() error = %v", err)
+	}
+	if !stat.Exists || stat.Size != int64(len(data)) || stat.Provider != mock.URL() {
+		t.Errorf("StatPiece() = %+v, want exists with size %d", stat, len(data))
+	}
+
+	part, err := mock.Client().DownloadPieceRange(ctx, result.PieceCID, 7, 14)
+	if err != nil {
+		t.Fatalf("DownloadPieceRange() error = %v", err)
+	}
+	if !bytes.Equal(part, data[7:21]) {
+		t.Errorf("DownloadPieceRange() = %q, want %q", part, data[7:21])
+	}
+
+	ds, ok := mock.DataSet(result.DataSetID)
+	if !ok {
+		t.Fatal("data set not found on mock")
+	}
+	if len(ds.Pieces) != 1 || ds.Pieces[0].PieceCID != result.PieceCID {
+		t.Errorf("data set pieces = %+v, want [%s]", ds.Pieces, result.PieceCID)
+	}
+
+	// a second upload reuses the data set and gets the next piece ID
+	second, err := m.UploadBytes(ctx, append(data, 'x'), nil)
+	if err != nil {
+		t.Fatalf("second UploadBytes() error = %v", err)
+	}
+	if second.DataSetID != result.DataSetID || second.PieceID != 1 {
+		t.Errorf("second upload = data set %d piece %d, want data set %d piece 1", second.DataSetID, second.PieceID, result.DataSetID)
+	}
+	if second.DataSetCreationTxHash != "" || second.TxHash == "" || second.TxHash == result.TxHash {
+		t.Errorf("second upload tx hashes = add %q, creation %q; want a new add hash and no creation", second.TxHash, second.DataSetCreationTxHash)
+	}
+}
+
+func TestServer_StorageUploadPrecomputedPieceCID(t *testing.T) {
+	mock := NewServer(t)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warmStorage := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	auth := pdp.NewAuthHelperFromKey(key, warmStorage, big.NewInt(31337))
+	m := storage.NewManager(auth.Address(), warmStorage, auth, mock.Client(), 0)
+
+	data := bytes.Repeat([]byte("precomputed"), 100)
+	pieceCID, err := storage.CalculatePieceCID(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &storage.UploadOptions{PieceCID: pieceCID, Size: int64(len(data)), Verify: true}
+	result, err := m.Upload(ctx, bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.PieceCID != pieceCID || result.Size != int64(len(data)) {
+		t.Errorf("Upload() = %s/%d, want %s/%d", result.PieceCID, result.Size, pieceCID, len(data))
+	}
+	if stored, ok := mock.Piece(pieceCID); !ok || !bytes.Equal(stored, data) {
+		t.Error("mock does not hold the uploaded piece")
+	}
+}
+
+func TestServer_RejectsMismatchedPieceCID(t *testing.T) {
+	mock := NewServer(t)
+	ctx := context.Background()
+
+	wrong, err := storage.CalculatePieceCID([]byte("something else entirely, long enough"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("the actual upload body, also long enough")
+	if _, err := mock.Client().UploadPiece(ctx, bytes.NewReader(data), int64(len(data)), wrong); err == nil {
+		t.Error("UploadPiece() expected error for mismatched piece CID")
+	}
+	if _, ok := mock.Piece(wrong); ok {
+		t.Error("mismatched piece should not be stored")
+	}
+	if _, exists, err := mock.Client().HeadPiece(ctx, wrong); err != nil || exists {
+		t.Errorf("HeadPiece() = exists %v, err %v; want missing", exists, err)
+	}
+}
+
+func TestServer_AddPiecesRequiresUpload(t *testing.T) {
+	mock := NewServer(t)
+	ctx := context.Background()
+	client := mock.Client()
+
+	created, err := client.CreateDataSet(ctx, "0x0", "0x")
+	if err != nil {
+		t.Fatalf("CreateDataSet() error = %v", err)
+	}
+	status, err := client.GetDataSetCreationStatus(ctx, created.TxHash)
+	if err != nil {
+		t.Fatalf("GetDataSetCreationStatus() error = %v", err)
+	}
+
+	missing, err := storage.CalculatePieceCID([]byte("never uploaded to the mock server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AddPieces(ctx, *status.DataSetID, []cid.Cid{missing}, "0x"); err == nil {
+		t.Error("AddPieces() expected error for piece that was never uploaded")
+	}
+}
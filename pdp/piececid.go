@@ -0,0 +1,67 @@
+package pdp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	commcid "github.com/filecoin-project/go-fil-commcid"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// ErrInvalidPieceCID is returned for a CID that is not a piece commitment.
+var ErrInvalidPieceCID = errors.New("not a piece CID")
+
+// fr32Sha256Trunc254Padbintree is the multihash of a PieceCIDv2 (FRC-0069),
+// whose digest carries the padding and tree height ahead of the commP root.
+const fr32Sha256Trunc254Padbintree = 0x1011
+
+// ParsePieceCID parses a piece CID string in either the v1 form
+// (baga6ea4sea..., fil-commitment-unsealed) or the v2 form (bafkzcib...,
+// raw with the fr32 padbintree multihash) and returns it in the v1 form the
+// PDP contracts and providers use. Any other CID fails with
+// ErrInvalidPieceCID.
+func ParsePieceCID(s string) (cid.Cid, error) {
+	c, err := cid.Decode(strings.TrimSpace(s))
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %q: %v", ErrInvalidPieceCID, s, err)
+	}
+	return NormalizePieceCID(c)
+}
+
+// NormalizePieceCID validates that c is a piece commitment and converts a
+// v2 piece CID to v1. A v1 piece CID is returned unchanged.
+func NormalizePieceCID(c cid.Cid) (cid.Cid, error) {
+	if !c.Defined() {
+		return cid.Undef, fmt.Errorf("%w: undefined CID", ErrInvalidPieceCID)
+	}
+	prefix := c.Prefix()
+
+	switch {
+	case prefix.Codec == cid.FilCommitmentUnsealed:
+		if _, err := commcid.CIDToPieceCommitmentV1(c); err != nil {
+			return cid.Undef, fmt.Errorf("%w: %s: %v", ErrInvalidPieceCID, c, err)
+		}
+		return c, nil
+
+	case prefix.Codec == cid.Raw && prefix.MhType == fr32Sha256Trunc254Padbintree:
+		decoded, err := multihash.Decode(c.Hash())
+		if err != nil {
+			return cid.Undef, fmt.Errorf("%w: %s: %v", ErrInvalidPieceCID, c, err)
+		}
+		// digest: uvarint padding, one byte tree height, 32-byte root
+		_, n := binary.Uvarint(decoded.Digest)
+		if n <= 0 || len(decoded.Digest) != n+1+32 {
+			return cid.Undef, fmt.Errorf("%w: %s: malformed v2 digest", ErrInvalidPieceCID, c)
+		}
+		v1, err := commcid.PieceCommitmentV1ToCID(decoded.Digest[n+1:])
+		if err != nil {
+			return cid.Undef, fmt.Errorf("%w: %s: %v", ErrInvalidPieceCID, c, err)
+		}
+		return v1, nil
+	}
+
+	return cid.Undef, fmt.Errorf("%w: %s has codec 0x%x and multihash 0x%x", ErrInvalidPieceCID, c, prefix.Codec, prefix.MhType)
+}
@@ -0,0 +1,62 @@
+package pdp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	commcid "github.com/filecoin-project/go-fil-commcid"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestParsePieceCID(t *testing.T) {
+	root := bytes.Repeat([]byte{0x11}, 32)
+	v1, err := commcid.PieceCommitmentV1ToCID(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// padding 0, height 5, then the root
+	digest := append([]byte{0x00, 0x05}, root...)
+	mh, err := multihash.Encode(digest, fr32Sha256Trunc254Padbintree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := cid.NewCidV1(cid.Raw, mh)
+
+	notPiece, err := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: multihash.SHA2_256, MhLength: -1}.Sum([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    cid.Cid
+		wantErr bool
+	}{
+		{name: "v1", in: v1.String(), want: v1},
+		{name: "v2", in: v2.String(), want: v1},
+		{name: "surrounding whitespace", in: " " + v1.String() + "\n", want: v1},
+		{name: "raw data CID", in: notPiece.String(), wantErr: true},
+		{name: "garbage", in: "not-a-cid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePieceCID(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPieceCID) {
+					t.Errorf("ParsePieceCID() error = %v, want ErrInvalidPieceCID", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePieceCID() error = %v", err)
+			}
+			if !got.Equals(tt.want) {
+				t.Errorf("ParsePieceCID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
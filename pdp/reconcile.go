@@ -0,0 +1,123 @@
+package pdp
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+)
+
+// reconcilePageSize is the number of roots requested per GetRoots call when
+// reading a data set's full on-chain piece list.
+const reconcilePageSize = 100
+
+// Roots returns the data set's pieces as Roots, one per piece ID in the order
+// the provider listed them. Sub-piece entries sharing a piece ID collapse into
+// a single Root.
+func (d *DataSetData) Roots() []Root {
+	roots := make([]Root, 0, len(d.Pieces))
+	seen := make(map[int]bool, len(d.Pieces))
+	for _, p := range d.Pieces {
+		if seen[p.PieceID] {
+			continue
+		}
+		seen[p.PieceID] = true
+		roots = append(roots, Root{PieceCID: p.PieceCID, PieceID: uint64(p.PieceID)})
+	}
+	return roots
+}
+
+// RootMismatch is a piece ID for which the provider and the chain disagree on
+// the piece CID.
+type RootMismatch struct {
+	PieceID     uint64
+	ProviderCID cid.Cid
+	ChainCID    cid.Cid
+}
+
+// RootsDiff lists the discrepancies between a provider's view of a data set
+// and the roots active on-chain. Each list is sorted by piece ID.
+type RootsDiff struct {
+	// ProviderOnly are pieces the provider reports that are not active on-chain.
+	ProviderOnly []Root
+	// ChainOnly are pieces active on-chain that the provider does not report.
+	ChainOnly []Root
+	// Mismatched are piece IDs present on both sides with different CIDs.
+	Mismatched []RootMismatch
+}
+
+// Consistent reports whether the provider and the chain agree.
+func (d *RootsDiff) Consistent() bool {
+	return len(d.ProviderOnly) == 0 && len(d.ChainOnly) == 0 && len(d.Mismatched) == 0
+}
+
+// CompareRoots matches provider and on-chain roots by piece ID.
+func CompareRoots(provider, chain []Root) *RootsDiff {
+	onChain := make(map[uint64]Root, len(chain))
+	for _, r := range chain {
+		onChain[r.PieceID] = r
+	}
+
+	diff := &RootsDiff{}
+	claimed := make(map[uint64]bool, len(provider))
+	for _, r := range provider {
+		claimed[r.PieceID] = true
+		c, ok := onChain[r.PieceID]
+		switch {
+		case !ok:
+			diff.ProviderOnly = append(diff.ProviderOnly, r)
+		case !c.PieceCID.Equals(r.PieceCID):
+			diff.Mismatched = append(diff.Mismatched, RootMismatch{
+				PieceID:     r.PieceID,
+				ProviderCID: r.PieceCID,
+				ChainCID:    c.PieceCID,
+			})
+		}
+	}
+	for _, r := range chain {
+		if !claimed[r.PieceID] {
+			diff.ChainOnly = append(diff.ChainOnly, r)
+		}
+	}
+
+	sort.Slice(diff.ProviderOnly, func(i, j int) bool { return diff.ProviderOnly[i].PieceID < diff.ProviderOnly[j].PieceID })
+	sort.Slice(diff.ChainOnly, func(i, j int) bool { return diff.ChainOnly[i].PieceID < diff.ChainOnly[j].PieceID })
+	sort.Slice(diff.Mismatched, func(i, j int) bool { return diff.Mismatched[i].PieceID < diff.Mismatched[j].PieceID })
+	return diff
+}
+
+// CheckDataSetConsistency fetches a data set's piece list from the provider
+// and its active roots from the chain and reports where they disagree.
+func CheckDataSetConsistency(ctx context.Context, server *Server, manager ProofSetManager, dataSetID int) (*RootsDiff, error) {
+	data, err := server.GetDataSet(ctx, dataSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data set from provider: %w", err)
+	}
+
+	chain, err := allRoots(ctx, manager, big.NewInt(int64(dataSetID)))
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareRoots(data.Roots(), chain), nil
+}
+
+func allRoots(ctx context.Context, manager ProofSetManager, proofSetID *big.Int) ([]Root, error) {
+	var roots []Root
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		offset := uint64(len(roots))
+		page, hasMore, err := manager.GetRoots(ctx, proofSetID, offset, reconcilePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get on-chain roots at offset %d: %w", offset, err)
+		}
+		roots = append(roots, page...)
+		if !hasMore || len(page) == 0 {
+			return roots, nil
+		}
+	}
+}
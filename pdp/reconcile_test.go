@@ -0,0 +1,115 @@
+package pdp
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+)
+
+const (
+	testCIDA = "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq"
+	testCIDB = "baga6ea4seaqdomn3tgwgrh3g532zopskstnbrd2n3sxfqbze7rxt7vqn7veigmy"
+)
+
+// rootsManager serves GetRoots from a fixed list, pageSize roots at a time.
+type rootsManager struct {
+	ProofSetManager
+	roots    []Root
+	pageSize int
+	calls    int
+}
+
+func (m *rootsManager) GetRoots(ctx context.Context, proofSetID *big.Int, offset, limit uint64) ([]Root, bool, error) {
+	m.calls++
+	end := offset + uint64(m.pageSize)
+	if end > uint64(len(m.roots)) {
+		end = uint64(len(m.roots))
+	}
+	return m.roots[offset:end], end < uint64(len(m.roots)), nil
+}
+
+func TestDataSetData_Roots(t *testing.T) {
+	a, b := mustCID(t, testCIDA), mustCID(t, testCIDB)
+	data := &DataSetData{Pieces: []PieceInfo{
+		{PieceID: 3, PieceCID: a, SubPieceCID: a},
+		{PieceID: 3, PieceCID: a, SubPieceCID: b, SubPieceOffset: 128},
+		{PieceID: 1, PieceCID: b, SubPieceCID: b},
+	}}
+
+	roots := data.Roots()
+	want := []Root{{PieceCID: a, PieceID: 3}, {PieceCID: b, PieceID: 1}}
+	if len(roots) != len(want) {
+		t.Fatalf("Roots() = %v, want %v", roots, want)
+	}
+	for i := range want {
+		if roots[i].PieceID != want[i].PieceID || !roots[i].PieceCID.Equals(want[i].PieceCID) {
+			t.Errorf("Roots()[%d] = %v, want %v", i, roots[i], want[i])
+		}
+	}
+}
+
+func TestCompareRoots(t *testing.T) {
+	a, b := mustCID(t, testCIDA), mustCID(t, testCIDB)
+
+	diff := CompareRoots(
+		[]Root{{PieceCID: a, PieceID: 0}, {PieceCID: a, PieceID: 1}, {PieceCID: b, PieceID: 4}},
+		[]Root{{PieceCID: a, PieceID: 0}, {PieceCID: b, PieceID: 1}, {PieceCID: b, PieceID: 2}},
+	)
+	if diff.Consistent() {
+		t.Fatal("expected discrepancies")
+	}
+	if len(diff.ProviderOnly) != 1 || diff.ProviderOnly[0].PieceID != 4 {
+		t.Errorf("ProviderOnly = %v, want piece 4", diff.ProviderOnly)
+	}
+	if len(diff.ChainOnly) != 1 || diff.ChainOnly[0].PieceID != 2 {
+		t.Errorf("ChainOnly = %v, want piece 2", diff.ChainOnly)
+	}
+	if len(diff.Mismatched) != 1 {
+		t.Fatalf("Mismatched = %v, want one entry", diff.Mismatched)
+	}
+	m := diff.Mismatched[0]
+	if m.PieceID != 1 || !m.ProviderCID.Equals(a) || !m.ChainCID.Equals(b) {
+		t.Errorf("Mismatched[0] = %+v", m)
+	}
+
+	if !CompareRoots([]Root{{PieceCID: a, PieceID: 7}}, []Root{{PieceCID: a, PieceID: 7}}).Consistent() {
+		t.Error("identical roots should be consistent")
+	}
+}
+
+func TestCheckDataSetConsistency(t *testing.T) {
+	a, b := mustCID(t, testCIDA), mustCID(t, testCIDB)
+
+	server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pdp/data-sets/9" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(DataSetData{ID: 9, Pieces: []PieceInfo{
+			{PieceID: 0, PieceCID: a},
+			{PieceID: 1, PieceCID: b},
+			{PieceID: 2, PieceCID: a},
+		}})
+	}))
+
+	manager := &rootsManager{
+		roots:    []Root{{PieceCID: a, PieceID: 0}, {PieceCID: b, PieceID: 1}, {PieceCID: a, PieceID: 2}, {PieceCID: b, PieceID: 3}},
+		pageSize: 3,
+	}
+
+	diff, err := CheckDataSetConsistency(context.Background(), server, manager, 9)
+	if err != nil {
+		t.Fatalf("CheckDataSetConsistency failed: %v", err)
+	}
+	if manager.calls != 2 {
+		t.Errorf("GetRoots called %d times, want 2", manager.calls)
+	}
+	if len(diff.ChainOnly) != 1 || diff.ChainOnly[0].PieceID != 3 {
+		t.Errorf("ChainOnly = %v, want piece 3", diff.ChainOnly)
+	}
+	if len(diff.ProviderOnly) != 0 || len(diff.Mismatched) != 0 {
+		t.Errorf("unexpected discrepancies: %+v", diff)
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/data-preservation-programs/go-synapse/internal/retry"
@@ -21,38 +22,157 @@ const (
 	defaultTimeout = 5 * time.Minute
 )
 
+// ErrRangeNotSupported is returned by DownloadPieceRange when the provider
+// ignores the Range header and responds with the whole piece.
+var ErrRangeNotSupported = errors.New("provider does not support range requests")
+
+// ErrWaitTimeout is returned (wrapped) by the WaitFor* methods when their
+// timeout elapses before the operation completes; it also matches
+// context.DeadlineExceeded. If the caller's context is cancelled or expires
+// first, its ctx.Err() is returned instead, and a failing status call
+// returns that call's error, so callers can retry on timeout but abort on
+// cancellation.
+var ErrWaitTimeout = retry.ErrTimeout
+
 // Server is a thin HTTP client for Curio's /pdp/* endpoints. It does not
 // hold an EIP-712 signer: extraData blobs (build via AuthHelper +
 // EncodeDataSetCreateData / EncodeAddPiecesExtraData and friends) are
 // passed in pre-signed by callers, and Curio verifies them server-side via
 // eth_call against PDPVerifier. There is no HTTP-level auth required by
 // default Curio deployments (NullAuth); operators can opt into JWTAuth,
-// but wiring that in is out of scope for this client.
+// but wiring that in is out of scope for this client. Requests rejected
+// with 429 Too Many Requests are retried after the provider's Retry-After
+// delay, a bounded number of times and never past the context deadline.
 type Server struct {
 	baseURL         string
 	httpClient      *http.Client
-	uploadClientMu  sync.Mutex
-	uploadClientVal *http.Client
+	transferClient  *http.Client
+	transferTimeout time.Duration
+}
+
+// ServerOptions tunes the timeouts of a Server. Zero fields take their
+// defaults. Timeouts are applied per operation, so one Server can poll
+// status with a tight RequestTimeout while uploading large pieces under a
+// much longer TransferTimeout; a deadline on the caller's ctx applies on top
+// of both.
+type ServerOptions struct {
+	// DialTimeout bounds establishing a TCP connection to the provider.
+	// Defaults to 30s.
+	DialTimeout time.Duration
+
+	// ResponseHeaderTimeout bounds the wait for response headers once the
+	// request (including any upload body) has been written. Zero means no
+	// limit beyond the operation's own timeout.
+	ResponseHeaderTimeout time.Duration
+
+	// RequestTimeout bounds each API call other than piece transfers,
+	// including reading its response. Defaults to 5 minutes.
+	RequestTimeout time.Duration
+
+	// TransferTimeout bounds the upload in UploadPiece and the whole of a
+	// DownloadPieceStream read. Zero means transfers are bounded only by ctx.
+	TransferTimeout time.Duration
+
+	// UserAgent is sent with every request. Defaults to DefaultUserAgent.
+	UserAgent string
+
+	// RequestIDs adds an X-Request-ID header with a random UUID to every
+	// request, kept across 429 retries, so a call can be matched up with
+	// the provider's logs.
+	RequestIDs bool
+}
+
+const defaultDialTimeout = 30 * time.Second
+
+// NewServerValidated is NewServer for untrusted input such as environment
+// variables: it rejects base URLs that are not absolute http(s) URLs with a
+// host, e.g. "provider.example.com" without a scheme, which would otherwise
+// fail later with an obscure error on every request.
+func NewServerValidated(baseURL string) (*Server, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider URL %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid provider URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid provider URL %q: missing host", baseURL)
+	}
+	return NewServer(baseURL), nil
+}
+
+// PingURL validates serviceURL as NewServerValidated does and pings the
+// provider there. Its signature fits spregistry.WithEndpointCheck.
+func PingURL(ctx context.Context, serviceURL string) error {
+	server, err := NewServerValidated(serviceURL)
+	if err != nil {
+		return err
+	}
+	return server.Ping(ctx)
 }
 
+// NewServer returns a client for the provider at baseURL with the default
+// ServerOptions. The URL is not validated; see NewServerValidated.
 func NewServer(baseURL string) *Server {
+	return NewServerWithOptions(baseURL, ServerOptions{})
+}
+
+// NewServerWithOptions is NewServer with custom timeouts and headers.
+func NewServerWithOptions(baseURL string, opts ServerOptions) *Server {
 	baseURL = strings.TrimSuffix(baseURL, "/")
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = defaultTimeout
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+
+	// both clients share one transport, and so one connection pool
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	rt := &headerTransport{
+		base:       newRetryAfterTransport(transport),
+		userAgent:  opts.UserAgent,
+		requestIDs: opts.RequestIDs,
+	}
 
 	return &Server{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: defaultTimeout,
+			Timeout:   opts.RequestTimeout,
+			Transport: rt,
 		},
+		transferClient:  &http.Client{Transport: rt},
+		transferTimeout: opts.TransferTimeout,
 	}
 }
 
-func (s *Server) uploadClient() *http.Client {
-	s.uploadClientMu.Lock()
-	defer s.uploadClientMu.Unlock()
-	if s.uploadClientVal == nil {
-		s.uploadClientVal = &http.Client{}
+// transferContext applies TransferTimeout, if any, to ctx.
+func (s *Server) transferContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.transferTimeout <= 0 {
+		return ctx, func() {}
 	}
-	return s.uploadClientVal
+	return context.WithTimeout(ctx, s.transferTimeout)
+}
+
+// cancelOnClose releases a transfer context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
 }
 
 func (s *Server) BaseURL() string {
@@ -173,6 +293,22 @@ func (s *Server) CreateDataSetAndAddPieces(ctx context.Context, recordKeeper str
 	}, nil
 }
 
+// CreateDataSetAndAddPiecesWithAuth signs the combined create-and-add
+// authorization with auth, including per-piece metadata, and submits it via
+// CreateDataSetAndAddPieces.
+func (s *Server) CreateDataSetAndAddPiecesWithAuth(ctx context.Context, auth *AuthHelper, opts CreateDataSetAndAddPiecesOptions) (*CreateDataSetResponse, error) {
+	if opts.Nonce == nil {
+		return nil, fmt.Errorf("nonce is required")
+	}
+
+	extraData, err := auth.SignCreateDataSetAndAddPieces(opts.ClientDataSetID, opts.Payee, opts.Nonce, opts.Pieces, opts.DataSetMetadata, opts.PieceMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign create-and-add: %w", err)
+	}
+
+	return s.CreateDataSetAndAddPieces(ctx, opts.RecordKeeper, opts.Pieces, extraData)
+}
+
 func (s *Server) GetDataSetCreationStatus(ctx context.Context, txHash string) (*DataSetCreationStatus, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/pdp/data-sets/created/"+txHash, nil)
 	if err != nil {
@@ -204,11 +340,8 @@ func (s *Server) GetDataSetCreationStatus(ctx context.Context, txHash string) (*
 }
 
 func (s *Server) WaitForDataSetCreation(ctx context.Context, txHash string, timeout time.Duration) (*DataSetCreationStatus, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
 	var status *DataSetCreationStatus
-	err := retry.Poll(ctx, 4*time.Second, timeout, func() (bool, error) {
+	err := retry.Poll(ctx, 4*time.Second, timeout, func(ctx context.Context) (bool, error) {
 		var err error
 		status, err = s.GetDataSetCreationStatus(ctx, txHash)
 		if err != nil {
@@ -311,11 +444,8 @@ func (s *Server) GetPieceAdditionStatus(ctx context.Context, dataSetID int, txHa
 }
 
 func (s *Server) WaitForPieceAddition(ctx context.Context, dataSetID int, txHash string, timeout time.Duration) (*PieceAdditionStatus, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
 	var status *PieceAdditionStatus
-	err := retry.Poll(ctx, time.Second, timeout, func() (bool, error) {
+	err := retry.Poll(ctx, time.Second, timeout, func(ctx context.Context) (bool, error) {
 		var err error
 		status, err = s.GetPieceAdditionStatus(ctx, dataSetID, txHash)
 		if err != nil {
@@ -358,7 +488,9 @@ func (s *Server) UploadPiece(ctx context.Context, data io.Reader, size int64, pi
 	}
 	uploadUUID := matches[1]
 
-	uploadReq, err := http.NewRequestWithContext(ctx, "PUT", s.baseURL+"/pdp/piece/uploads/"+uploadUUID, data)
+	uploadCtx, cancel := s.transferContext(ctx)
+	defer cancel()
+	uploadReq, err := http.NewRequestWithContext(uploadCtx, "PUT", s.baseURL+"/pdp/piece/uploads/"+uploadUUID, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create upload request: %w", err)
 	}
@@ -367,7 +499,7 @@ func (s *Server) UploadPiece(ctx context.Context, data io.Reader, size int64, pi
 		uploadReq.ContentLength = size
 	}
 
-	uploadResp, err := s.uploadClient().Do(uploadReq)
+	uploadResp, err := s.transferClient.Do(uploadReq)
 	if err != nil {
 		return nil, fmt.Errorf("upload failed: %w", err)
 	}
@@ -402,9 +534,20 @@ func (s *Server) UploadPiece(ctx context.Context, data io.Reader, size int64, pi
 		return nil, fmt.Errorf("finalize failed: status %d: %s", finalizeResp.StatusCode, string(respBody))
 	}
 
+	// the upload is done; a missing or unparseable body only loses detail
+	var complete *UploadCompleteResponse
+	if respBody, err := io.ReadAll(finalizeResp.Body); err == nil && len(bytes.TrimSpace(respBody)) > 0 {
+		var parsed UploadCompleteResponse
+		if json.Unmarshal(respBody, &parsed) == nil {
+			complete = &parsed
+		}
+	}
+
 	return &UploadPieceResponse{
-		PieceCID: pieceCID,
-		Size:     size,
+		PieceCID:   pieceCID,
+		Size:       size,
+		UploadUUID: uploadUUID,
+		Complete:   complete,
 	}, nil
 }
 
@@ -436,8 +579,45 @@ func (s *Server) FindPiece(ctx context.Context, pieceCID cid.Cid) error {
 	return nil
 }
 
+// HeadPiece checks whether the provider has pieceCID using a HEAD request on
+// the download endpoint, without transferring the piece. It returns the piece
+// size in bytes, or -1 if the provider did not report one. Providers that do
+// not allow HEAD are checked with FindPiece instead, in which case the size
+// is always -1.
+func (s *Server) HeadPiece(ctx context.Context, pieceCID cid.Cid) (size int64, exists bool, err error) {
+	reqURL := fmt.Sprintf("%s/pdp/piece/%s", s.baseURL, pieceCID.String())
+	req, err := http.NewRequestWithContext(ctx, "HEAD", reqURL, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.ContentLength, true, nil
+	case http.StatusNotFound:
+		return 0, false, nil
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		err := s.FindPiece(ctx, pieceCID)
+		if err != nil {
+			if strings.Contains(err.Error(), "piece not found") {
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		return -1, true, nil
+	default:
+		return 0, false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
 func (s *Server) WaitForPiece(ctx context.Context, pieceCID cid.Cid, timeout time.Duration) error {
-	return retry.Poll(ctx, 5*time.Second, timeout, func() (bool, error) {
+	return retry.Poll(ctx, 5*time.Second, timeout, func(ctx context.Context) (bool, error) {
 		err := s.FindPiece(ctx, pieceCID)
 		if err != nil {
 			if strings.Contains(err.Error(), "piece not found") {
@@ -449,6 +629,57 @@ func (s *Server) WaitForPiece(ctx context.Context, pieceCID cid.Cid, timeout tim
 	})
 }
 
+// WaitForPieceRetrievable is WaitForPiece that also waits until the
+// provider actually serves the piece's bytes, which can lag FindPiece while
+// the piece is still being parked. Retrievability is checked by reading the
+// first byte of the piece; the provider answering 404 or a 5xx status is
+// treated as not yet retrievable.
+func (s *Server) WaitForPieceRetrievable(ctx context.Context, pieceCID cid.Cid, timeout time.Duration) error {
+	found := false
+	return retry.Poll(ctx, 5*time.Second, timeout, func(ctx context.Context) (bool, error) {
+		if !found {
+			err := s.FindPiece(ctx, pieceCID)
+			if err != nil {
+				if strings.Contains(err.Error(), "piece not found") {
+					return false, nil
+				}
+				return false, err
+			}
+			found = true
+		}
+		return s.pieceRetrievable(ctx, pieceCID)
+	})
+}
+
+// pieceRetrievable requests the first byte of pieceCID and reports whether
+// the provider served it.
+func (s *Server) pieceRetrievable(ctx context.Context, pieceCID cid.Cid) (bool, error) {
+	reqURL := fmt.Sprintf("%s/pdp/piece/%s", s.baseURL, pieceCID.String())
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent, resp.StatusCode == http.StatusOK:
+		// a provider ignoring Range streams the whole piece; one byte is enough
+		n, _ := io.ReadFull(resp.Body, make([]byte, 1))
+		return n == 1, nil
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode >= 500:
+		return false, nil
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+}
+
 func (s *Server) DownloadPiece(ctx context.Context, pieceCID cid.Cid) ([]byte, error) {
 	reqURL := fmt.Sprintf("%s/pdp/piece/%s", s.baseURL, pieceCID.String())
 	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
@@ -474,6 +705,97 @@ func (s *Server) DownloadPiece(ctx context.Context, pieceCID cid.Cid) ([]byte, e
 	return io.ReadAll(resp.Body)
 }
 
+// DownloadPieceStream opens the piece for reading without buffering it. The
+// caller must close the returned reader. Unlike DownloadPiece it is not
+// subject to RequestTimeout, so large pieces are bounded only by ctx and
+// TransferTimeout.
+func (s *Server) DownloadPieceStream(ctx context.Context, pieceCID cid.Cid) (io.ReadCloser, error) {
+	return s.DownloadPieceStreamFrom(ctx, pieceCID, 0)
+}
+
+// DownloadPieceStreamFrom is DownloadPieceStream starting offset bytes into
+// the piece, requested with "Range: bytes=offset-", for resuming a transfer
+// that was cut off. It fails with ErrRangeNotSupported if the provider
+// ignores the Range header.
+func (s *Server) DownloadPieceStreamFrom(ctx context.Context, pieceCID cid.Cid, offset int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d", offset)
+	}
+
+	ctx, cancel := s.transferContext(ctx)
+	reqURL := fmt.Sprintf("%s/pdp/piece/%s", s.baseURL, pieceCID.String())
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := s.transferClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && offset == 0,
+		resp.StatusCode == http.StatusPartialContent && offset > 0:
+		return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil, ErrRangeNotSupported
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("piece not found: %s", pieceCID.String())
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, fmt.Errorf("range starting at %d is beyond the end of piece %s", offset, pieceCID.String())
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+}
+
+// DownloadPieceRange fetches length bytes of the piece starting at offset
+// using an HTTP Range request. The result may be shorter than length if the
+// range extends past the end of the piece.
+func (s *Server) DownloadPieceRange(ctx context.Context, pieceCID cid.Cid, offset, length int64) ([]byte, error) {
+	if offset < 0 || length <= 0 {
+		return nil, fmt.Errorf("invalid range: offset %d, length %d", offset, length)
+	}
+
+	reqURL := fmt.Sprintf("%s/pdp/piece/%s", s.baseURL, pieceCID.String())
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	case http.StatusOK:
+		return nil, ErrRangeNotSupported
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("piece not found: %s", pieceCID.String())
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, fmt.Errorf("range starting at %d is beyond the end of piece %s", offset, pieceCID.String())
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+}
+
 func (s *Server) GetDataSet(ctx context.Context, dataSetID int) (*DataSetData, error) {
 	reqURL := fmt.Sprintf("%s/pdp/data-sets/%d", s.baseURL, dataSetID)
 	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
@@ -555,11 +877,8 @@ func (s *Server) PullPieces(ctx context.Context, opts PullPiecesOptions) (*PullP
 // WaitForPullPieces re-POSTs the same pull request (idempotent) until the
 // aggregate status is complete or failed, or the timeout elapses.
 func (s *Server) WaitForPullPieces(ctx context.Context, opts PullPiecesOptions, timeout time.Duration) (*PullPiecesResponse, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
 	var last *PullPiecesResponse
-	err := retry.Poll(ctx, 4*time.Second, timeout, func() (bool, error) {
+	err := retry.Poll(ctx, 4*time.Second, timeout, func(ctx context.Context) (bool, error) {
 		resp, err := s.PullPieces(ctx, opts)
 		if err != nil {
 			return false, err
@@ -578,6 +897,18 @@ func (s *Server) WaitForPullPieces(ctx context.Context, opts PullPiecesOptions,
 	return last, nil
 }
 
+// Health pings the provider and reports how long it took to answer.
+// Curio only exposes /pdp/ping as a status endpoint, so version, capacity
+// and upload availability are not available over HTTP. An unreachable
+// provider returns an error.
+func (s *Server) Health(ctx context.Context) (*ProviderHealth, error) {
+	start := time.Now()
+	if err := s.Ping(ctx); err != nil {
+		return nil, err
+	}
+	return &ProviderHealth{Reachable: true, Latency: time.Since(start)}, nil
+}
+
 func (s *Server) Ping(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/pdp/ping", nil)
 	if err != nil {
@@ -1,19 +1,24 @@
 package pdp
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
 )
 
@@ -65,6 +70,46 @@ func TestServer_NewServer(t *testing.T) {
 	}
 }
 
+func TestPingURL(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pdp/ping" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	if err := PingURL(context.Background(), mockServer.URL); err != nil {
+		t.Errorf("PingURL() error = %v", err)
+	}
+	if err := PingURL(context.Background(), "provider.example.com"); err == nil {
+		t.Error("PingURL() expected error for URL without scheme")
+	}
+}
+
+func TestNewServerValidated(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		wantErr bool
+	}{
+		{"https://provider.example.com", false},
+		{"http://localhost:4702/", false},
+		{"provider.example.com", true},
+		{"ftp://provider.example.com", true},
+		{"https://", true},
+		{"://bad", true},
+	}
+
+	for _, tt := range tests {
+		server, err := NewServerValidated(tt.baseURL)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewServerValidated(%q) error = %v, wantErr %v", tt.baseURL, err, tt.wantErr)
+		}
+		if err == nil && server.BaseURL() != strings.TrimSuffix(tt.baseURL, "/") {
+			t.Errorf("BaseURL() = %q", server.BaseURL())
+		}
+	}
+}
+
 func TestServer_Ping(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -105,6 +150,34 @@ func TestServer_Ping(t *testing.T) {
 	}
 }
 
+func TestServer_Health(t *testing.T) {
+	t.Run("reachable", func(t *testing.T) {
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/pdp/ping" {
+				t.Errorf("unexpected request to %s", r.URL.Path)
+			}
+		}))
+
+		health, err := server.Health(context.Background())
+		if err != nil {
+			t.Fatalf("Health() error = %v", err)
+		}
+		if !health.Reachable || health.Latency < 0 {
+			t.Errorf("Health() = %+v, want reachable with latency", health)
+		}
+	})
+
+	t.Run("down", func(t *testing.T) {
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		if _, err := server.Health(context.Background()); err == nil {
+			t.Error("Health() expected error for unavailable provider")
+		}
+	})
+}
+
 func TestServer_CreateDataSet(t *testing.T) {
 	t.Run("successful creation", func(t *testing.T) {
 		expectedTxHash := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
@@ -219,6 +292,44 @@ func TestServer_CreateDataSetAndAddPieces(t *testing.T) {
 		}
 	})
 
+	t.Run("signed with piece metadata", func(t *testing.T) {
+		auth := testAuthHelper(t)
+		opts := CreateDataSetAndAddPiecesOptions{
+			RecordKeeper:    recordKeeper,
+			ClientDataSetID: big.NewInt(7),
+			Payee:           common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+			Nonce:           big.NewInt(42),
+			Pieces:          []cid.Cid{pieceCID},
+			DataSetMetadata: []MetadataEntry{{Key: "source", Value: "test"}},
+			PieceMetadata:   [][]MetadataEntry{{{Key: "name", Value: "piece-0"}}},
+		}
+		want, err := auth.SignCreateDataSetAndAddPieces(opts.ClientDataSetID, opts.Payee, opts.Nonce, opts.Pieces, opts.DataSetMetadata, opts.PieceMetadata)
+		if err != nil {
+			t.Fatalf("SignCreateDataSetAndAddPieces failed: %v", err)
+		}
+
+		var seen CreateAndAddRequest
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&seen); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			w.Header().Set("Location", "/pdp/data-sets/created/0xabc")
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		if _, err := server.CreateDataSetAndAddPiecesWithAuth(context.Background(), auth, opts); err != nil {
+			t.Fatalf("CreateDataSetAndAddPiecesWithAuth failed: %v", err)
+		}
+		if seen.ExtraData != want {
+			t.Errorf("ExtraData = %s, want %s", seen.ExtraData, want)
+		}
+
+		opts.Nonce = nil
+		if _, err := server.CreateDataSetAndAddPiecesWithAuth(context.Background(), auth, opts); err == nil {
+			t.Error("Expected error for missing nonce, got nil")
+		}
+	})
+
 	t.Run("missing Location header", func(t *testing.T) {
 		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusCreated)
@@ -508,3 +619,363 @@ func TestServer_WaitForPullPieces(t *testing.T) {
 		}
 	})
 }
+
+func TestServer_WaitForDataSetCreationTimeout(t *testing.T) {
+	server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"createMessageHash":"0xabc","dataSetCreated":false,"txStatus":"pending"}`))
+	}))
+
+	_, err := server.WaitForDataSetCreation(context.Background(), "0xabc", 50*time.Millisecond)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("WaitForDataSetCreation() error = %v, want ErrWaitTimeout", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = server.WaitForDataSetCreation(ctx, "0xabc", time.Minute)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("WaitForDataSetCreation() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestServer_DownloadPieceRange(t *testing.T) {
+	pieceCID, err := cid.Decode("baga6ea4seaqdomn3tgwgrh3g532zopskstnbrd2n3sxfqbze7rxt7vqn7veigmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("0123456789abcdef")
+
+	t.Run("partial content", func(t *testing.T) {
+		var gotRange string
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotRange = r.Header.Get("Range")
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		}))
+
+		data, err := server.DownloadPieceRange(context.Background(), pieceCID, 4, 6)
+		if err != nil {
+			t.Fatalf("DownloadPieceRange failed: %v", err)
+		}
+		if gotRange != "bytes=4-9" {
+			t.Errorf("Range header = %q, want bytes=4-9", gotRange)
+		}
+		if string(data) != "456789" {
+			t.Errorf("data = %q, want %q", data, "456789")
+		}
+
+		data, err = server.DownloadPieceRange(context.Background(), pieceCID, 12, 10)
+		if err != nil {
+			t.Fatalf("DownloadPieceRange past end failed: %v", err)
+		}
+		if string(data) != "cdef" {
+			t.Errorf("data = %q, want %q", data, "cdef")
+		}
+
+		if _, err := server.DownloadPieceRange(context.Background(), pieceCID, 100, 1); err == nil {
+			t.Error("expected error for unsatisfiable range")
+		}
+	})
+
+	t.Run("range ignored", func(t *testing.T) {
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		}))
+
+		_, err := server.DownloadPieceRange(context.Background(), pieceCID, 4, 6)
+		if !errors.Is(err, ErrRangeNotSupported) {
+			t.Errorf("error = %v, want ErrRangeNotSupported", err)
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		server := NewServer("http://localhost")
+		if _, err := server.DownloadPieceRange(context.Background(), pieceCID, -1, 6); err == nil {
+			t.Error("expected error for negative offset")
+		}
+		if _, err := server.DownloadPieceRange(context.Background(), pieceCID, 0, 0); err == nil {
+			t.Error("expected error for zero length")
+		}
+	})
+}
+
+func TestServer_HeadPiece(t *testing.T) {
+	pieceCID, err := cid.Decode("baga6ea4seaqdomn3tgwgrh3g532zopskstnbrd2n3sxfqbze7rxt7vqn7veigmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantSize   int64
+		wantExists bool
+	}{
+		{
+			name: "found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodHead {
+					t.Errorf("method = %s, want HEAD", r.Method)
+				}
+				w.Header().Set("Content-Length", "2048")
+			},
+			wantSize:   2048,
+			wantExists: true,
+		},
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name: "falls back to find piece",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				if r.URL.Path != "/pdp/piece" {
+					t.Errorf("fallback path = %s, want /pdp/piece", r.URL.Path)
+				}
+				_, _ = w.Write([]byte(`{}`))
+			},
+			wantSize:   -1,
+			wantExists: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := setupMockServer(t, tt.handler)
+			size, exists, err := server.HeadPiece(context.Background(), pieceCID)
+			if err != nil {
+				t.Fatalf("HeadPiece failed: %v", err)
+			}
+			if size != tt.wantSize || exists != tt.wantExists {
+				t.Errorf("HeadPiece = (%d, %v), want (%d, %v)", size, exists, tt.wantSize, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestNewServerWithOptions(t *testing.T) {
+	pieceCID := mustCID(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pdp/ping":
+			<-release
+		case "/pdp/piece/" + pieceCID.String():
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-release
+		}
+	}))
+	t.Cleanup(mockServer.Close)
+	t.Cleanup(func() { close(release) })
+
+	server := NewServerWithOptions(mockServer.URL, ServerOptions{
+		RequestTimeout:  50 * time.Millisecond,
+		TransferTimeout: 200 * time.Millisecond,
+	})
+
+	if err := server.Ping(context.Background()); err == nil {
+		t.Error("Ping() should fail once RequestTimeout elapses")
+	}
+
+	// the stream outlives RequestTimeout but not TransferTimeout
+	body, err := server.DownloadPieceStream(context.Background(), pieceCID)
+	if err != nil {
+		t.Fatalf("DownloadPieceStream() error = %v", err)
+	}
+	defer body.Close()
+	start := time.Now()
+	if _, err := io.ReadAll(body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("read error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("stream ended after %v, before TransferTimeout", elapsed)
+	}
+}
+
+// headerRecorder is a provider that records the User-Agent and X-Request-ID
+// of each request, rejecting the first with 429 when limitFirst is set.
+type headerRecorder struct {
+	limitFirst bool
+	mu         sync.Mutex
+	userAgents []string
+	requestIDs []string
+}
+
+func (h *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.userAgents = append(h.userAgents, r.Header.Get("User-Agent"))
+	h.requestIDs = append(h.requestIDs, r.Header.Get("X-Request-ID"))
+	if h.limitFirst && len(h.requestIDs) == 1 {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+func TestServer_Headers(t *testing.T) {
+	rec := &headerRecorder{}
+	mockServer := httptest.NewServer(rec)
+	t.Cleanup(mockServer.Close)
+
+	if err := NewServer(mockServer.URL).Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if !strings.HasPrefix(DefaultUserAgent, "go-synapse/") || rec.userAgents[0] != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", rec.userAgents[0], DefaultUserAgent)
+	}
+	if rec.requestIDs[0] != "" {
+		t.Errorf("X-Request-ID = %q without RequestIDs", rec.requestIDs[0])
+	}
+
+	rec = &headerRecorder{}
+	mockServer.Config.Handler = rec
+	server := NewServerWithOptions(mockServer.URL, ServerOptions{UserAgent: "my-app/1.0", RequestIDs: true})
+	for i := 0; i < 2; i++ {
+		if err := server.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	for _, ua := range rec.userAgents {
+		if ua != "my-app/1.0" {
+			t.Errorf("User-Agent = %q, want my-app/1.0", ua)
+		}
+	}
+	if _, err := uuid.Parse(rec.requestIDs[0]); err != nil {
+		t.Errorf("X-Request-ID %q is not a UUID: %v", rec.requestIDs[0], err)
+	}
+	if rec.requestIDs[0] == rec.requestIDs[1] {
+		t.Error("separate requests share an X-Request-ID")
+	}
+
+	// a 429 retry is the same request and keeps its ID
+	rec = &headerRecorder{limitFirst: true}
+	mockServer.Config.Handler = rec
+	if err := server.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if len(rec.requestIDs) != 2 || rec.requestIDs[0] == "" || rec.requestIDs[0] != rec.requestIDs[1] {
+		t.Errorf("X-Request-IDs across retry = %q, want one ID sent twice", rec.requestIDs)
+	}
+}
+
+func TestServer_DownloadPieceStreamFrom(t *testing.T) {
+	pieceCID := mustCID(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	data := []byte("0123456789")
+	newServer := func(rangeSupport bool) *Server {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !rangeSupport {
+				_, _ = w.Write(data)
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		}))
+		t.Cleanup(mockServer.Close)
+		return NewServer(mockServer.URL)
+	}
+
+	body, err := newServer(true).DownloadPieceStreamFrom(context.Background(), pieceCID, 4)
+	if err != nil {
+		t.Fatalf("DownloadPieceStreamFrom() error = %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(got) != "456789" {
+		t.Errorf("DownloadPieceStreamFrom() read %q, %v; want \"456789\"", got, err)
+	}
+
+	if _, err := newServer(false).DownloadPieceStreamFrom(context.Background(), pieceCID, 4); !errors.Is(err, ErrRangeNotSupported) {
+		t.Errorf("DownloadPieceStreamFrom() error = %v, want ErrRangeNotSupported", err)
+	}
+}
+
+func TestServer_WaitForPieceRetrievable(t *testing.T) {
+	pieceCID := mustCID(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	newServer := func(t *testing.T, download http.HandlerFunc) (*Server, *atomic.Int32) {
+		var downloads atomic.Int32
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/pdp/piece" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			downloads.Add(1)
+			if got := r.Header.Get("Range"); got != "bytes=0-0" {
+				t.Errorf("Range = %q, want bytes=0-0", got)
+			}
+			download(w, r)
+		}))
+		return server, &downloads
+	}
+
+	t.Run("served", func(t *testing.T) {
+		server, downloads := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte{0x01})
+		})
+		if err := server.WaitForPieceRetrievable(context.Background(), pieceCID, time.Second); err != nil {
+			t.Errorf("WaitForPieceRetrievable() error = %v", err)
+		}
+		if downloads.Load() != 1 {
+			t.Errorf("made %d download requests, want 1", downloads.Load())
+		}
+	})
+
+	t.Run("found but not served", func(t *testing.T) {
+		server, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		err := server.WaitForPieceRetrievable(context.Background(), pieceCID, 100*time.Millisecond)
+		if !errors.Is(err, ErrWaitTimeout) {
+			t.Errorf("WaitForPieceRetrievable() error = %v, want ErrWaitTimeout", err)
+		}
+	})
+}
+
+func TestServer_UploadPiece(t *testing.T) {
+	pieceCID := mustCID(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	const uploadUUID = "0b5e8d1c-3f7a-4c2e-9d6b-1a2b3c4d5e6f"
+	newServer := func(t *testing.T, finalizeBody string) *Server {
+		server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/pdp/piece/uploads":
+				w.Header().Set("Location", "/pdp/piece/uploads/"+uploadUUID)
+				w.WriteHeader(http.StatusCreated)
+			case r.Method == http.MethodPut:
+				_, _ = io.Copy(io.Discard, r.Body)
+				w.WriteHeader(http.StatusNoContent)
+			case r.Method == http.MethodPost:
+				_, _ = w.Write([]byte(finalizeBody))
+			}
+		}))
+		return server
+	}
+	data := []byte("piece data")
+
+	server := newServer(t, `{"pieceCid":"bafkzcibcaapao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq","size":10}`)
+	resp, err := server.UploadPiece(context.Background(), bytes.NewReader(data), int64(len(data)), pieceCID)
+	if err != nil {
+		t.Fatalf("UploadPiece() error = %v", err)
+	}
+	if resp.UploadUUID != uploadUUID {
+		t.Errorf("UploadUUID = %q, want %q", resp.UploadUUID, uploadUUID)
+	}
+	if resp.Complete == nil || resp.Complete.PieceCID != "bafkzcibcaapao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq" || resp.Complete.Size != 10 {
+		t.Errorf("Complete = %+v, want the finalize response", resp.Complete)
+	}
+
+	server = newServer(t, "")
+	resp, err = server.UploadPiece(context.Background(), bytes.NewReader(data), int64(len(data)), pieceCID)
+	if err != nil {
+		t.Fatalf("UploadPiece() error = %v", err)
+	}
+	if resp.UploadUUID != uploadUUID || resp.Complete != nil {
+		t.Errorf("UploadPiece() = %+v, want the UUID and no Complete for an empty finalize body", resp)
+	}
+}
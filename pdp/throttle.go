@@ -0,0 +1,102 @@
+package pdp
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRateLimitAttempts bounds how many times a request is sent when the
+	// provider keeps answering 429 Too Many Requests.
+	maxRateLimitAttempts = 5
+
+	// maxRetryAfter is the longest Retry-After we will wait out; anything
+	// longer is returned to the caller as a 429 error.
+	maxRetryAfter = 2 * time.Minute
+
+	// defaultRetryAfter is used when a 429 carries no usable Retry-After.
+	defaultRetryAfter = time.Second
+)
+
+// retryAfterTransport retries requests rejected with 429 Too Many Requests,
+// waiting for the duration the provider asks for in Retry-After. Requests
+// whose body cannot be replayed (streamed uploads) are not retried.
+type retryAfterTransport struct {
+	base        http.RoundTripper
+	maxAttempts int
+	now         func() time.Time
+}
+
+func newRetryAfterTransport(base http.RoundTripper) *retryAfterTransport {
+	return &retryAfterTransport{base: base, maxAttempts: maxRateLimitAttempts, now: time.Now}
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || !replayable || attempt >= t.maxAttempts {
+			return resp, err
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.now())
+		if !ok {
+			wait = defaultRetryAfter
+		}
+		if wait > maxRetryAfter {
+			return resp, nil
+		}
+		ctx := req.Context()
+		if deadline, ok := ctx.Deadline(); ok && t.now().Add(wait).After(deadline) {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either as delay seconds
+// or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package pdp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestServer_RetriesTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			t.Error("retried request lost its body")
+		}
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Location", "/pdp/data-sets/created/0xabc")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	resp, err := server.CreateDataSet(context.Background(), "0x0000000000000000000000000000000000000001", "0x00")
+	if err != nil {
+		t.Fatalf("CreateDataSet() error = %v", err)
+	}
+	if resp.TxHash != "0xabc" {
+		t.Errorf("TxHash = %q, want 0xabc", resp.TxHash)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server saw %d requests, want 3", calls.Load())
+	}
+}
+
+func TestServer_TooManyRequestsGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	if err := server.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() expected error after repeated 429s")
+	}
+	if calls.Load() != maxRateLimitAttempts {
+		t.Errorf("server saw %d requests, want %d", calls.Load(), maxRateLimitAttempts)
+	}
+
+	// a Retry-After beyond the context deadline is not waited out
+	calls.Store(0)
+	slow, _ := setupMockServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := slow.FindPiece(ctx, mustCID(t, testCIDA)); err == nil {
+		t.Fatal("FindPiece() expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server saw %d requests, want 1", calls.Load())
+	}
+}
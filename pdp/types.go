@@ -1,6 +1,9 @@
 package pdp
 
 import (
+	"math/big"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ipfs/go-cid"
 )
@@ -69,6 +72,12 @@ type PieceAdditionStatus struct {
 type UploadPieceResponse struct {
 	PieceCID cid.Cid
 	Size     int64
+	// UploadUUID identifies the provider's upload session, for matching
+	// the upload against provider logs or status endpoints.
+	UploadUUID string
+	// Complete is what the provider returned on finalize, or nil if it
+	// returned no JSON body. Its PieceCID may be in the v2 form.
+	Complete *UploadCompleteResponse
 }
 
 type FindPieceResponse struct {
@@ -103,6 +112,26 @@ type CreateAndAddRequest struct {
 	ExtraData    string      `json:"extraData"`
 }
 
+// CreateDataSetAndAddPiecesOptions is the argument for
+// Server.CreateDataSetAndAddPiecesWithAuth.
+type CreateDataSetAndAddPiecesOptions struct {
+	// RecordKeeper is the FWSS contract address (hex). Required.
+	RecordKeeper string
+	// ClientDataSetID is the client's identifier for the new data set.
+	ClientDataSetID *big.Int
+	// Payee is the service provider's payment address.
+	Payee common.Address
+	// Nonce makes the AddPieces signature unique. Required.
+	Nonce *big.Int
+	// Pieces are the piece CIDs to add; they must already be on the provider.
+	Pieces []cid.Cid
+	// DataSetMetadata is attached to the new data set.
+	DataSetMetadata []MetadataEntry
+	// PieceMetadata is attached per piece, one entry per Pieces element, or
+	// nil for none.
+	PieceMetadata [][]MetadataEntry
+}
+
 type UploadStartResponse struct {
 	UploadUUID string `json:"uploadUuid"`
 }
@@ -172,6 +201,14 @@ type PullPiecesOptions struct {
 	DataSetID uint64
 }
 
+// ProviderHealth is the result of Server.Health.
+type ProviderHealth struct {
+	// Reachable is true if the provider answered the ping.
+	Reachable bool
+	// Latency is the round-trip time of the ping.
+	Latency time.Duration
+}
+
 // ManagerConfig holds configuration options for the Manager
 type ManagerConfig struct {
 	// GasBufferPercent is the percentage buffer to add to gas estimates (0-100)
@@ -186,6 +223,35 @@ type ManagerConfig struct {
 	// ContractAddress overrides the default PDPVerifier contract address for the network.
 	// Leave zero to use the network default.
 	ContractAddress common.Address
+	// Multicall3Address overrides the Multicall3 contract DataSetsLive
+	// batches through. Leave zero to use the network default.
+	Multicall3Address common.Address
+	// OnMulticall3Unavailable is called once if no contract is deployed at
+	// the Multicall3 address, after which DataSetsLive falls back to one
+	// call per proof set.
+	OnMulticall3Unavailable func(addr common.Address)
+	// PendingWarnThreshold, when non-zero, makes the manager call
+	// OnPendingBacklog after a send that leaves more than this many of its
+	// transactions unconfirmed, a sign of congestion or a stuck transaction.
+	PendingWarnThreshold int
+	// OnPendingBacklog receives the pending transaction count once it
+	// exceeds PendingWarnThreshold. It runs on the sending goroutine.
+	OnPendingBacklog func(pending int)
+	// DataSetFinder is consulted by CreateProofSet when
+	// CreateProofSetOptions.ClientDataSetID is set.
+	DataSetFinder DataSetFinder
+	// VerifyDeletion makes DeleteProofSet confirm with DataSetLive that the
+	// proof set is gone once the delete is mined. It costs one extra call.
+	VerifyDeletion bool
+	// EventsFromBlock is the first block ListProofSets searches for
+	// DataSetCreated and StorageProviderChanged events. Set it to the
+	// verifier's deployment block to skip older history; zero searches from
+	// genesis.
+	EventsFromBlock uint64
+	// LogBlockRange, when non-zero, splits ListProofSets' log queries into
+	// ranges of at most this many blocks, for RPC providers that cap
+	// eth_getLogs (Lotus defaults to 2880).
+	LogBlockRange uint64
 }
 
 // DefaultManagerConfig returns the default configuration for Manager
@@ -0,0 +1,30 @@
+// Package callopt holds options accepted by the read-only contract methods
+// across the SDK, such as pinning a call to a historical block.
+package callopt
+
+import "math/big"
+
+// Config is the resolved set of call options.
+type Config struct {
+	// BlockNumber pins the call to a block. Nil means the latest block.
+	BlockNumber *big.Int
+}
+
+type Option func(*Config)
+
+// WithBlock evaluates the call against the state at block n. The RPC node
+// must retain state for that block (archive node for old blocks).
+func WithBlock(n *big.Int) Option {
+	return func(c *Config) {
+		c.BlockNumber = n
+	}
+}
+
+// Apply resolves opts into a Config.
+func Apply(opts ...Option) Config {
+	var c Config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
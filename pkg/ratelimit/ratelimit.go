@@ -0,0 +1,119 @@
+// Package ratelimit throttles outgoing RPC requests on the client side so
+// bulk operations stay below a provider's rate limit (the public Glif
+// endpoints reject bursts with 429) instead of failing mid-way.
+//
+// Every SDK service talks to the chain through an *ethclient.Client, so the
+// limiter is attached at the HTTP transport of the underlying RPC client:
+//
+//	rpcClient, err := rpc.DialOptions(ctx, url,
+//		rpc.WithHTTPClient(ratelimit.NewHTTPClient(nil, 10, 20)))
+//	eth := ethclient.NewClient(rpcClient)
+//
+// synapse.Options.RPCRateLimit does this for the Synapse client.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket: it holds up to burst tokens and refills at rate
+// tokens per second. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter returns a full bucket allowing rate requests per second with
+// bursts of up to burst requests. burst values below 1 are treated as 1.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns 0, or returns how
+// long until the next token.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	if l.rate <= 0 {
+		// no refill; poll occasionally so a cancelled ctx is noticed
+		return time.Second
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Transport is an http.RoundTripper that waits on a Limiter before each
+// request.
+type Transport struct {
+	Base    http.RoundTripper
+	Limiter *Limiter
+}
+
+// NewTransport wraps base (http.DefaultTransport if nil) with a limiter of
+// rate requests per second and the given burst.
+func NewTransport(base http.RoundTripper, rate float64, burst int) *Transport {
+	return &Transport{Base: base, Limiter: NewLimiter(rate, burst)}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// NewHTTPClient returns an http.Client whose requests are rate limited, for
+// use with rpc.WithHTTPClient. base may be nil.
+func NewHTTPClient(base http.RoundTripper, rate float64, burst int) *http.Client {
+	return &http.Client{Transport: NewTransport(base, rate, burst)}
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Refill(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return clock }
+
+	for i := 0; i < 3; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("reserve() #%d = %v, want burst token", i, d)
+		}
+	}
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("reserve() on empty bucket = %v, want 500ms", d)
+	}
+
+	clock = clock.Add(500 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("reserve() after refill = %v, want 0", d)
+	}
+
+	// idle time never fills past burst
+	clock = clock.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		l.reserve()
+	}
+	if d := l.reserve(); d == 0 {
+		t.Error("bucket refilled past burst")
+	}
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	l := NewLimiter(0.001, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(nil, 50, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if hits.Load() != 3 {
+		t.Errorf("server saw %d requests, want 3", hits.Load())
+	}
+	// one burst token, then two refills at 50/s
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("3 requests took %v, expected throttling", elapsed)
+	}
+}
@@ -0,0 +1,89 @@
+package txutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// cancelGasBufferPercent is added to the estimated gas of a cancellation.
+// A plain transfer costs far more than Ethereum's 21000 on FEVM, so the
+// limit is estimated rather than fixed.
+const cancelGasBufferPercent = 10
+
+// TxSigner is the subset of signer.EVMSigner needed to sign transactions.
+type TxSigner interface {
+	EVMAddress() common.Address
+	Transactor(chainID *big.Int) (*bind.TransactOpts, error)
+}
+
+// CancelTransaction sends a zero-value transfer from the signer to itself at
+// nonce. Once mined it supersedes whatever transaction was stuck at that
+// nonce. feeCap and tipCap must exceed the stuck transaction's by at least
+// MinReplacementBumpPercent or the mempool will reject the replacement.
+func CancelTransaction(ctx context.Context, client *ethclient.Client, signer TxSigner, nonce uint64, feeCap, tipCap *big.Int) (common.Hash, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	opts, err := signer.Transactor(chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create transactor: %w", err)
+	}
+
+	self := signer.EVMAddress()
+	gasLimit, err := EstimateGasWithBuffer(ctx, client, ethereum.CallMsg{
+		From:  self,
+		To:    &self,
+		Value: big.NewInt(0),
+	}, cancelGasBufferPercent)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gasLimit,
+		To:        &self,
+		Value:     big.NewInt(0),
+	})
+
+	signedTx, err := opts.Signer(self, tx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign cancel transaction: %w", err)
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send cancel transaction: %w", err)
+	}
+
+	return signedTx.Hash(), nil
+}
+
+// CancelTransaction cancels the transaction at nonce via the package-level
+// CancelTransaction, keeping the nonce reserved as pending until the caller
+// calls MarkConfirmed once the cancellation is mined. If the cancellation
+// cannot be built or sent, the nonce is released with MarkFailed.
+func (nm *NonceManager) CancelTransaction(ctx context.Context, signer TxSigner, nonce uint64, feeCap, tipCap *big.Int) (common.Hash, error) {
+	if signer.EVMAddress() != nm.address {
+		return common.Hash{}, fmt.Errorf("signer %s does not match nonce manager address %s", signer.EVMAddress().Hex(), nm.address.Hex())
+	}
+
+	nm.ReserveSpecificNonce(nonce)
+	hash, err := CancelTransaction(ctx, nm.client, signer, nonce, feeCap, tipCap)
+	if err != nil {
+		nm.MarkFailed(nonce)
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
@@ -0,0 +1,109 @@
+package txutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// cancelAPI estimates gas as an FEVM node would for a plain transfer and
+// records the transaction sent, or rejects it when sendErr is set.
+type cancelAPI struct {
+	estimateFrom common.Address
+	sent         *types.Transaction
+	sendErr      error
+}
+
+func (a *cancelAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(314159))
+}
+
+func (a *cancelAPI) EstimateGas(args struct {
+	From common.Address `json:"from"`
+}) hexutil.Uint64 {
+	a.estimateFrom = args.From
+	return 1_500_000
+}
+
+func (a *cancelAPI) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	if a.sendErr != nil {
+		return common.Hash{}, a.sendErr
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	a.sent = tx
+	return tx.Hash(), nil
+}
+
+// keySigner is a TxSigner over a prebuilt keyed transactor.
+type keySigner struct {
+	opts *bind.TransactOpts
+}
+
+func (s keySigner) EVMAddress() common.Address { return s.opts.From }
+
+func (s keySigner) Transactor(chainID *big.Int) (*bind.TransactOpts, error) {
+	opts := *s.opts
+	return &opts, nil
+}
+
+func TestNonceManager_CancelTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(314159))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := keySigner{opts: opts}
+
+	api := &cancelAPI{}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	nm := NewNonceManager(ethclient.NewClient(rpc.DialInProc(server)), signer.EVMAddress())
+
+	feeCap, tipCap := big.NewInt(2000), big.NewInt(200)
+	hash, err := nm.CancelTransaction(context.Background(), signer, 4, feeCap, tipCap)
+	if err != nil {
+		t.Fatalf("CancelTransaction() error = %v", err)
+	}
+	if api.sent == nil || api.sent.Hash() != hash {
+		t.Fatal("cancel transaction was not sent")
+	}
+	if api.estimateFrom != signer.EVMAddress() {
+		t.Errorf("gas estimated from %s, want the signer", api.estimateFrom.Hex())
+	}
+	if got := api.sent.Gas(); got != 1_650_000 {
+		t.Errorf("gas limit = %d, want the estimate plus %d%%", got, cancelGasBufferPercent)
+	}
+	if api.sent.Nonce() != 4 || *api.sent.To() != signer.EVMAddress() || api.sent.Value().Sign() != 0 {
+		t.Errorf("cancel transaction = nonce %d to %s value %s", api.sent.Nonce(), api.sent.To().Hex(), api.sent.Value())
+	}
+	if n := nm.GetPendingCount(); n != 1 {
+		t.Errorf("pending count after cancel = %d, want 1", n)
+	}
+	nm.MarkConfirmed(4)
+
+	api.sendErr = errors.New("replacement transaction underpriced")
+	if _, err := nm.CancelTransaction(context.Background(), signer, 5, feeCap, tipCap); err == nil {
+		t.Fatal("CancelTransaction() expected error from rejected send")
+	}
+	if n := nm.GetPendingCount(); n != 0 {
+		t.Errorf("pending count after failed cancel = %d, want 0", n)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/data-preservation-programs/go-synapse/internal/retry"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -19,8 +22,12 @@ var (
 )
 
 type ReceiptWaitConfig struct {
-	Timeout              time.Duration
-	PollInterval         time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// MaxPollInterval enables backoff when greater than PollInterval: the
+	// wait between polls starts at PollInterval and grows with decorrelated
+	// jitter up to MaxPollInterval. Zero keeps a fixed PollInterval.
+	MaxPollInterval      time.Duration
 	MaxConsecutiveErrors int
 }
 
@@ -28,6 +35,7 @@ func DefaultReceiptWaitConfig() ReceiptWaitConfig {
 	return ReceiptWaitConfig{
 		Timeout:              5 * time.Minute,
 		PollInterval:         time.Second,
+		MaxPollInterval:      10 * time.Second,
 		MaxConsecutiveErrors: 5,
 	}
 }
@@ -55,8 +63,15 @@ func WaitForReceiptWithConfig(ctx context.Context, client *ethclient.Client, txH
 		maxErrors = 5
 	}
 
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+	nextWait := func(prev time.Duration) time.Duration {
+		if config.MaxPollInterval <= pollInterval {
+			return pollInterval
+		}
+		return retry.CalculateBackoff(prev, pollInterval, config.MaxPollInterval)
+	}
+	wait := pollInterval
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
 
 	consecutiveErrors := 0
 	pollCount := 0
@@ -69,7 +84,9 @@ func WaitForReceiptWithConfig(ctx context.Context, client *ethclient.Client, txH
 				return nil, fmt.Errorf("%w after %d polls: %v (last error: %v)", ErrReceiptTimeout, pollCount, ctx.Err(), lastErr)
 			}
 			return nil, fmt.Errorf("%w after %d polls: %v", ErrReceiptTimeout, pollCount, ctx.Err())
-		case <-ticker.C:
+		case <-timer.C:
+			wait = nextWait(wait)
+			timer.Reset(wait)
 			pollCount++
 			receipt, err := client.TransactionReceipt(ctx, txHash)
 			if err != nil {
@@ -78,7 +95,7 @@ func WaitForReceiptWithConfig(ctx context.Context, client *ethclient.Client, txH
 					consecutiveErrors = 0
 					continue
 				}
-				if !isRetryableError(err) {
+				if !IsRetryableError(err) {
 					return nil, fmt.Errorf("%w: non-retryable error: %v", ErrReceiptRPCFailure, err)
 				}
 				consecutiveErrors++
@@ -97,9 +114,112 @@ func WaitForReceiptWithConfig(ctx context.Context, client *ethclient.Client, txH
 	}
 }
 
-// isRetryableError returns true for transient RPC errors worth retrying.
+// WaitForConfirmation waits until txHash is mined and buried under enough
+// blocks that the inclusion block plus its descendants number confirmations;
+// 0 and 1 both return as soon as the receipt is available. The receipt is
+// re-fetched once the depth is reached, and if a reorg has moved or dropped
+// the transaction the wait starts over from its new receipt. config.Timeout
+// bounds the whole wait.
+func WaitForConfirmation(ctx context.Context, client *ethclient.Client, txHash common.Hash, confirmations uint64, config ReceiptWaitConfig) (*types.Receipt, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultReceiptWaitConfig().Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	pollInterval := config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = time.Second
+	}
+
+	for {
+		receipt, err := WaitForReceiptWithConfig(ctx, client, txHash, config)
+		if err != nil || confirmations <= 1 {
+			return receipt, err
+		}
+
+		target := new(big.Int).Add(receipt.BlockNumber, new(big.Int).SetUint64(confirmations-1))
+		if err := waitForBlock(ctx, client, target.Uint64(), pollInterval); err != nil {
+			return nil, fmt.Errorf("waiting for %d confirmations of %s: %w", confirmations, txHash.Hex(), err)
+		}
+
+		current, err := client.TransactionReceipt(ctx, txHash)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to re-fetch receipt: %w", err)
+		}
+		if err == nil && current.BlockHash == receipt.BlockHash {
+			return current, nil
+		}
+		// reorged out of its block: wait for the new inclusion instead
+	}
+}
+
+// waitForBlock polls the chain head until it reaches number. Transient RPC
+// errors are retried until ctx expires.
+func waitForBlock(ctx context.Context, client *ethclient.Client, number uint64, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		head, err := client.BlockNumber(ctx)
+		if err == nil && head >= number {
+			return nil
+		}
+		if err != nil && !IsRetryableError(err) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return fmt.Errorf("failed to get block number: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForReceipts waits for several transactions concurrently, each with
+// config. It returns once all are mined, or as soon as one fails, cancelling
+// the remaining waits. The map holds every receipt fetched so far, including
+// that of a reverted transaction.
+func WaitForReceipts(ctx context.Context, client *ethclient.Client, hashes []common.Hash, config ReceiptWaitConfig) (map[common.Hash]*types.Receipt, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		receipts = make(map[common.Hash]*types.Receipt, len(hashes))
+		firstErr error
+	)
+
+	for _, hash := range hashes {
+		wg.Add(1)
+		go func(hash common.Hash) {
+			defer wg.Done()
+			receipt, err := WaitForReceiptWithConfig(ctx, client, hash, config)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if receipt != nil {
+				receipts[hash] = receipt
+			}
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("transaction %s: %w", hash.Hex(), err)
+				cancel()
+			}
+		}(hash)
+	}
+	wg.Wait()
+
+	return receipts, firstErr
+}
+
+// IsRetryableError reports whether err is a transient RPC error worth retrying.
 // Matches by string fragment because go-ethereum surfaces these as plain errors.
-func isRetryableError(err error) bool {
+func IsRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
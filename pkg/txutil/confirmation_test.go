@@ -3,7 +3,16 @@ package txutil
 import (
 	"context"
 	"errors"
+	"math/big"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func TestIsRetryableError(t *testing.T) {
@@ -61,10 +70,84 @@ func TestIsRetryableError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isRetryableError(tt.err)
+			result := IsRetryableError(tt.err)
 			if result != tt.expected {
-				t.Errorf("isRetryableError() = %v, want %v", result, tt.expected)
+				t.Errorf("IsRetryableError() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
 }
+
+// confirmAPI serves a chain whose head advances one block per eth_blockNumber
+// call. The transaction is mined in block 10 until the head reaches reorgAt,
+// then in block 11 under a different hash.
+type confirmAPI struct {
+	mu      sync.Mutex
+	head    uint64
+	reorgAt uint64
+}
+
+func (a *confirmAPI) BlockNumber() hexutil.Uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.head++
+	return hexutil.Uint64(a.head)
+}
+
+func (a *confirmAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      hash,
+		BlockNumber: big.NewInt(10),
+		BlockHash:   common.HexToHash("0xaa"),
+		Logs:        []*types.Log{},
+	}
+	if a.reorgAt > 0 && a.head >= a.reorgAt {
+		receipt.BlockNumber = big.NewInt(11)
+		receipt.BlockHash = common.HexToHash("0xbb")
+	}
+	return receipt
+}
+
+func TestWaitForConfirmation(t *testing.T) {
+	config := ReceiptWaitConfig{Timeout: 5 * time.Second, PollInterval: time.Millisecond}
+	dial := func(api *confirmAPI) *ethclient.Client {
+		server := rpc.NewServer()
+		if err := server.RegisterName("eth", api); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(server.Stop)
+		return ethclient.NewClient(rpc.DialInProc(server))
+	}
+	txHash := common.HexToHash("0x01")
+
+	t.Run("waits for depth", func(t *testing.T) {
+		api := &confirmAPI{head: 9}
+		receipt, err := WaitForConfirmation(context.Background(), dial(api), txHash, 3, config)
+		if err != nil {
+			t.Fatalf("WaitForConfirmation() error = %v", err)
+		}
+		if receipt.BlockHash != common.HexToHash("0xaa") {
+			t.Errorf("BlockHash = %s, want 0xaa", receipt.BlockHash)
+		}
+		if api.head < 12 {
+			t.Errorf("returned at head %d, want >= 12", api.head)
+		}
+	})
+
+	t.Run("restarts after reorg", func(t *testing.T) {
+		api := &confirmAPI{head: 9, reorgAt: 12}
+		receipt, err := WaitForConfirmation(context.Background(), dial(api), txHash, 3, config)
+		if err != nil {
+			t.Fatalf("WaitForConfirmation() error = %v", err)
+		}
+		if receipt.BlockHash != common.HexToHash("0xbb") || receipt.BlockNumber.Int64() != 11 {
+			t.Errorf("receipt = block %s %s, want the post-reorg block 11", receipt.BlockNumber, receipt.BlockHash)
+		}
+		if api.head < 13 {
+			t.Errorf("returned at head %d, want >= 13", api.head)
+		}
+	})
+}
@@ -17,9 +17,6 @@ import (
 //   - client: Ethereum client connection
 //   - msg: Call message to estimate gas for
 //   - bufferPercent: Percentage buffer to add (0-100)
-//
-// Note: This function is not currently used internally by go-synapse but is provided
-// as a convenience for library consumers who need to estimate gas for custom transactions.
 func EstimateGasWithBuffer(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg, bufferPercent int) (uint64, error) {
 	if bufferPercent < 0 || bufferPercent > 100 {
 		return 0, fmt.Errorf("buffer percent must be between 0 and 100")
@@ -2,7 +2,10 @@ package txutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -47,6 +50,15 @@ func (nm *NonceManager) GetNonce(ctx context.Context) (uint64, error) {
 	return currentNonce, nil
 }
 
+// GetPendingCount returns how many allocated nonces have not been marked
+// confirmed or failed. A count that keeps growing means transactions are not
+// being mined.
+func (nm *NonceManager) GetPendingCount() int {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return len(nm.pendingTxs)
+}
+
 func (nm *NonceManager) MarkConfirmed(nonce uint64) {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
@@ -64,3 +76,123 @@ func (nm *NonceManager) MarkFailed(nonce uint64) {
 	delete(nm.pendingTxs, nonce)
 	nm.nonce = nil
 }
+
+// Reset discards the cached nonce and the pending set, so the next GetNonce
+// starts again from the node's pending nonce. Use it when the node rejects a
+// nonce as already used, typically because another process sends from the
+// same key. Nonces of transactions still in flight are no longer tracked.
+func (nm *NonceManager) Reset() {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.nonce = nil
+	nm.pendingTxs = make(map[uint64]bool)
+}
+
+// IsNonceError reports whether err is a node rejecting a transaction because
+// its nonce has already been used. Matches by string fragment because
+// go-ethereum and Lotus surface these as plain errors.
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	errStr := strings.ToLower(err.Error())
+	for _, fragment := range []string{
+		"nonce too low",
+		"nonce has already been used",
+	} {
+		if strings.Contains(errStr, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReserveSpecificNonce marks n as pending so it can be reused for a
+// replacement transaction (see BuildReplacement). If n is at or beyond the
+// next cached nonce, the cache is advanced past it.
+func (nm *NonceManager) ReserveSpecificNonce(n uint64) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	nm.pendingTxs[n] = true
+	if nm.nonce != nil && n >= *nm.nonce {
+		next := n + 1
+		nm.nonce = &next
+	}
+}
+
+// DetectGaps returns tracked pending nonces that the node no longer knows
+// about: at or above the account's mined nonce count, yet not covered by the
+// node's pending nonce. Such a nonce was dropped from the mempool and blocks
+// every higher nonce until it is reused.
+func (nm *NonceManager) DetectGaps(ctx context.Context) ([]uint64, error) {
+	mined, poolNext, err := nm.chainNonces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.gapsLocked(mined, poolNext), nil
+}
+
+// ResyncFromChain forgets pending nonces that have been mined and, if any
+// tracked nonce was dropped, rewinds the cached nonce so the next GetNonce
+// fills the lowest gap. It returns the dropped nonces that were reclaimed.
+// Callers must not have transactions in flight for the reclaimed nonces.
+func (nm *NonceManager) ResyncFromChain(ctx context.Context) ([]uint64, error) {
+	mined, poolNext, err := nm.chainNonces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.resyncLocked(mined, poolNext), nil
+}
+
+func (nm *NonceManager) chainNonces(ctx context.Context) (mined, poolNext uint64, err error) {
+	mined, err = nm.client.NonceAt(ctx, nm.address, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get confirmed nonce: %w", err)
+	}
+	poolNext, err = nm.client.PendingNonceAt(ctx, nm.address)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	return mined, poolNext, nil
+}
+
+func (nm *NonceManager) gapsLocked(mined, poolNext uint64) []uint64 {
+	var gaps []uint64
+	for n := range nm.pendingTxs {
+		if n >= mined && n >= poolNext {
+			gaps = append(gaps, n)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps
+}
+
+func (nm *NonceManager) resyncLocked(mined, poolNext uint64) []uint64 {
+	for n := range nm.pendingTxs {
+		if n < mined {
+			delete(nm.pendingTxs, n)
+		}
+	}
+
+	gaps := nm.gapsLocked(mined, poolNext)
+	if len(gaps) == 0 {
+		return nil
+	}
+	for _, n := range gaps {
+		delete(nm.pendingTxs, n)
+	}
+	next := poolNext
+	nm.nonce = &next
+	return gaps
+}
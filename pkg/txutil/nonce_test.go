@@ -1,6 +1,9 @@
 package txutil
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -104,3 +107,108 @@ func TestNonceManager_MarkConfirmed(t *testing.T) {
 		t.Error("nonce 12 should still be pending")
 	}
 }
+
+func TestNonceManager_Resync(t *testing.T) {
+	newManager := func(next uint64, pending ...uint64) *NonceManager {
+		nm := &NonceManager{pendingTxs: make(map[uint64]bool)}
+		nm.nonce = &next
+		for _, n := range pending {
+			nm.pendingTxs[n] = true
+		}
+		return nm
+	}
+
+	t.Run("no gaps when the pool covers every pending nonce", func(t *testing.T) {
+		nm := newManager(8, 5, 6, 7)
+		if gaps := nm.gapsLocked(5, 8); len(gaps) != 0 {
+			t.Errorf("gapsLocked() = %v, want none", gaps)
+		}
+	})
+
+	t.Run("dropped nonces are reported in order", func(t *testing.T) {
+		// 5 mined, 6 in the pool, 7 and 8 dropped
+		nm := newManager(9, 5, 6, 8, 7)
+		gaps := nm.gapsLocked(6, 7)
+		if len(gaps) != 2 || gaps[0] != 7 || gaps[1] != 8 {
+			t.Errorf("gapsLocked() = %v, want [7 8]", gaps)
+		}
+	})
+
+	t.Run("resync drops mined nonces and rewinds to the gap", func(t *testing.T) {
+		nm := newManager(9, 5, 6, 7, 8)
+		reclaimed := nm.resyncLocked(6, 7)
+		if len(reclaimed) != 2 || reclaimed[0] != 7 {
+			t.Errorf("resyncLocked() = %v, want [7 8]", reclaimed)
+		}
+		if len(nm.pendingTxs) != 1 || !nm.pendingTxs[6] {
+			t.Errorf("pendingTxs = %v, want only 6", nm.pendingTxs)
+		}
+		if nm.nonce == nil || *nm.nonce != 7 {
+			t.Errorf("cached nonce = %v, want 7", nm.nonce)
+		}
+	})
+
+	t.Run("resync without gaps keeps the cached nonce", func(t *testing.T) {
+		nm := newManager(9, 5, 6, 7, 8)
+		if reclaimed := nm.resyncLocked(7, 9); reclaimed != nil {
+			t.Errorf("resyncLocked() = %v, want nil", reclaimed)
+		}
+		if *nm.nonce != 9 {
+			t.Errorf("cached nonce = %d, want 9", *nm.nonce)
+		}
+		if len(nm.pendingTxs) != 2 {
+			t.Errorf("pendingTxs = %v, want 7 and 8", nm.pendingTxs)
+		}
+	})
+}
+
+func TestNonceManager_Reset(t *testing.T) {
+	cached := uint64(7)
+	nm := &NonceManager{nonce: &cached, pendingTxs: map[uint64]bool{5: true, 6: true}}
+
+	nm.Reset()
+
+	if nm.nonce != nil {
+		t.Errorf("expected cached nonce to be cleared, got %d", *nm.nonce)
+	}
+	if len(nm.pendingTxs) != 0 {
+		t.Errorf("expected no pending txs, got %d", len(nm.pendingTxs))
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("nonce too low"), true},
+		{fmt.Errorf("failed to send: %w", errors.New("Nonce Too Low: next nonce 5, tx nonce 4")), true},
+		{errors.New("message nonce has already been used"), true},
+		{errors.New("insufficient funds for gas * price + value"), false},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := IsNonceError(tt.err); got != tt.want {
+			t.Errorf("IsNonceError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNonceManager_GetPendingCount(t *testing.T) {
+	cached := uint64(3)
+	nm := &NonceManager{nonce: &cached, pendingTxs: make(map[uint64]bool)}
+	for i := 0; i < 3; i++ {
+		n := *nm.nonce
+		nm.pendingTxs[n] = true
+		*nm.nonce++
+	}
+	if got := nm.GetPendingCount(); got != 3 {
+		t.Errorf("GetPendingCount() = %d, want 3", got)
+	}
+	nm.MarkConfirmed(3)
+	nm.MarkFailed(5)
+	if got := nm.GetPendingCount(); got != 1 {
+		t.Errorf("GetPendingCount() = %d, want 1", got)
+	}
+}
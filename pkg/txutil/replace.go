@@ -0,0 +1,50 @@
+package txutil
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// MinReplacementBumpPercent is the smallest fee increase geth-compatible
+// mempools accept for a same-nonce replacement.
+const MinReplacementBumpPercent = 10.0
+
+// BuildReplacement returns an unsigned dynamic-fee transaction with the same
+// nonce, recipient, value, data and gas limit as old, and GasFeeCap and
+// GasTipCap raised by feeBumpPercent. Bumps below MinReplacementBumpPercent
+// are raised to it. EIP-155 legacy transactions are converted using their gas
+// price for both caps. To cancel instead of speeding up, send a replacement with
+// zero value and no data to the sender's own address.
+//
+// Sign the result and send it with the nonce reserved via
+// NonceManager.ReserveSpecificNonce.
+func BuildReplacement(old *types.Transaction, feeBumpPercent float64) *types.Transaction {
+	if feeBumpPercent < MinReplacementBumpPercent {
+		feeBumpPercent = MinReplacementBumpPercent
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    old.ChainId(),
+		Nonce:      old.Nonce(),
+		GasTipCap:  bumpFee(old.GasTipCap(), feeBumpPercent),
+		GasFeeCap:  bumpFee(old.GasFeeCap(), feeBumpPercent),
+		Gas:        old.Gas(),
+		To:         old.To(),
+		Value:      old.Value(),
+		Data:       old.Data(),
+		AccessList: old.AccessList(),
+	})
+}
+
+// bumpFee returns fee * (1 + percent/100), rounded up so small fees still
+// clear the mempool's minimum bump.
+func bumpFee(fee *big.Int, percent float64) *big.Int {
+	if fee == nil {
+		return nil
+	}
+	basisPoints := big.NewInt(10000 + int64(percent*100))
+	bumped := new(big.Int).Mul(fee, basisPoints)
+	bumped.Add(bumped, big.NewInt(9999))
+	return bumped.Div(bumped, big.NewInt(10000))
+}
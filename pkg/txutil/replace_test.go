@@ -0,0 +1,94 @@
+package txutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBuildReplacement(t *testing.T) {
+	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	chainID := big.NewInt(314159)
+
+	tests := []struct {
+		name        string
+		old         *types.Transaction
+		bump        float64
+		wantFeeCap  *big.Int
+		wantTipCap  *big.Int
+		wantChainID *big.Int
+	}{
+		{
+			name: "dynamic fee tx",
+			old: types.NewTx(&types.DynamicFeeTx{
+				ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000),
+				Gas: 21000, To: &to, Value: big.NewInt(5), Data: []byte{0x01},
+			}),
+			bump:        25,
+			wantFeeCap:  big.NewInt(1250),
+			wantTipCap:  big.NewInt(125),
+			wantChainID: chainID,
+		},
+		{
+			name: "bump below minimum is raised and rounded up",
+			old: types.NewTx(&types.DynamicFeeTx{
+				ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(15),
+				Gas: 21000, To: &to,
+			}),
+			bump:        1,
+			wantFeeCap:  big.NewInt(17),
+			wantTipCap:  big.NewInt(2),
+			wantChainID: chainID,
+		},
+		{
+			name: "legacy tx uses gas price for both caps",
+			old: types.NewTx(&types.LegacyTx{
+				Nonce: 7, GasPrice: big.NewInt(200), Gas: 21000, To: &to,
+			}),
+			bump:       50,
+			wantFeeCap: big.NewInt(300),
+			wantTipCap: big.NewInt(300),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildReplacement(tt.old, tt.bump)
+			if got.Type() != types.DynamicFeeTxType {
+				t.Errorf("Type() = %d, want %d", got.Type(), types.DynamicFeeTxType)
+			}
+			if got.Nonce() != tt.old.Nonce() {
+				t.Errorf("Nonce() = %d, want %d", got.Nonce(), tt.old.Nonce())
+			}
+			if *got.To() != *tt.old.To() || got.Gas() != tt.old.Gas() || got.Value().Cmp(tt.old.Value()) != 0 {
+				t.Errorf("replacement changed to/gas/value")
+			}
+			if got.GasFeeCap().Cmp(tt.wantFeeCap) != 0 {
+				t.Errorf("GasFeeCap() = %s, want %s", got.GasFeeCap(), tt.wantFeeCap)
+			}
+			if got.GasTipCap().Cmp(tt.wantTipCap) != 0 {
+				t.Errorf("GasTipCap() = %s, want %s", got.GasTipCap(), tt.wantTipCap)
+			}
+			if tt.wantChainID != nil && got.ChainId().Cmp(tt.wantChainID) != 0 {
+				t.Errorf("ChainId() = %s, want %s", got.ChainId(), tt.wantChainID)
+			}
+		})
+	}
+}
+
+func TestNonceManager_ReserveSpecificNonce(t *testing.T) {
+	next := uint64(10)
+	nm := &NonceManager{pendingTxs: make(map[uint64]bool), nonce: &next}
+
+	nm.ReserveSpecificNonce(8)
+	if !nm.pendingTxs[8] || *nm.nonce != 10 {
+		t.Errorf("after reserving 8: pending=%v nonce=%d, want 8 pending and nonce 10", nm.pendingTxs, *nm.nonce)
+	}
+
+	nm.ReserveSpecificNonce(10)
+	if *nm.nonce != 11 {
+		t.Errorf("after reserving 10: nonce=%d, want 11", *nm.nonce)
+	}
+}
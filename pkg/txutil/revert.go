@@ -0,0 +1,123 @@
+package txutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DecodeRevertReason decodes standard Solidity revert data: Error(string)
+// and Panic(uint256). Custom errors are returned as their hex selector plus
+// arguments since decoding them needs the contract ABI.
+func DecodeRevertReason(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	return "custom error " + hexutil.Encode(data)
+}
+
+// RevertReason replays a mined transaction as a call against the state of
+// the block before it was included and returns the decoded revert reason.
+// It returns "" if the replay does not revert, which can happen when the
+// failure depended on earlier transactions in the same block.
+func RevertReason(ctx context.Context, client *ethclient.Client, tx *types.Transaction, blockNumber *big.Int) (string, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover sender: %w", err)
+	}
+
+	var at *big.Int
+	if blockNumber != nil && blockNumber.Sign() > 0 {
+		at = new(big.Int).Sub(blockNumber, big.NewInt(1))
+	}
+
+	_, err = client.CallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, at)
+	if err == nil {
+		return "", nil
+	}
+	return revertReasonFromError(err), nil
+}
+
+// revertReasonFromError extracts the revert data attached to a JSON-RPC call
+// error, falling back to the error message when the node sends none.
+func revertReasonFromError(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if s, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(s); decodeErr == nil && len(data) > 0 {
+				return DecodeRevertReason(data)
+			}
+		}
+	}
+	return strings.TrimPrefix(err.Error(), "execution reverted: ")
+}
+
+// TxStatus summarises what happened to a submitted transaction.
+type TxStatus struct {
+	Hash         common.Hash
+	Pending      bool
+	Mined        bool
+	Success      bool
+	Reverted     bool
+	BlockNumber  *big.Int
+	GasUsed      uint64
+	RevertReason string
+}
+
+// GetTxStatus looks up txHash and reports whether it is pending, succeeded or
+// reverted. For reverted transactions it also tries to recover the revert
+// reason; failure to do so is not an error.
+func GetTxStatus(ctx context.Context, client *ethclient.Client, txHash common.Hash) (*TxStatus, error) {
+	status := &TxStatus{Hash: txHash}
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	if receipt == nil {
+		_, pending, err := client.TransactionByHash(ctx, txHash)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				return nil, fmt.Errorf("transaction %s not found", txHash.Hex())
+			}
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		status.Pending = pending
+		return status, nil
+	}
+
+	status.Mined = true
+	status.BlockNumber = receipt.BlockNumber
+	status.GasUsed = receipt.GasUsed
+	status.Success = receipt.Status == types.ReceiptStatusSuccessful
+	status.Reverted = !status.Success
+
+	if status.Reverted {
+		tx, _, err := client.TransactionByHash(ctx, txHash)
+		if err == nil {
+			status.RevertReason, _ = RevertReason(ctx, client, tx, receipt.BlockNumber)
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,45 @@
+package txutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	stringTy, _ := abi.NewType("string", "", nil)
+	uintTy, _ := abi.NewType("uint256", "", nil)
+
+	errorData, err := abi.Arguments{{Type: stringTy}}.Pack("insufficient funds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errorData = append(crypto.Keccak256([]byte("Error(string)"))[:4], errorData...)
+
+	panicData, err := abi.Arguments{{Type: uintTy}}.Pack(big.NewInt(0x11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	panicData = append(crypto.Keccak256([]byte("Panic(uint256)"))[:4], panicData...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "empty", data: nil, want: ""},
+		{name: "Error(string)", data: errorData, want: "insufficient funds"},
+		{name: "Panic(uint256)", data: panicData, want: "arithmetic underflow or overflow"},
+		{name: "custom error", data: []byte{0xde, 0xad, 0xbe, 0xef}, want: "custom error 0xdeadbeef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeRevertReason(tt.data); got != tt.want {
+				t.Errorf("DecodeRevertReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package txutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BaseTransactor holds a bind.TransactOpts whose signer is built once and
+// hands out copies of it for individual calls. It is safe for concurrent use.
+type BaseTransactor struct {
+	base bind.TransactOpts
+}
+
+// NewBaseTransactor builds the signer for key on chainID.
+func NewBaseTransactor(key *ecdsa.PrivateKey, chainID *big.Int) (*BaseTransactor, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	}
+	return &BaseTransactor{base: *opts}, nil
+}
+
+// From returns the address transactions are signed by.
+func (t *BaseTransactor) From() common.Address {
+	return t.base.From
+}
+
+// Opts returns a fresh TransactOpts for one call. Only From and Signer are
+// carried over; the caller sets Nonce, Value and the other per-call fields.
+func (t *BaseTransactor) Opts(ctx context.Context) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    t.base.From,
+		Signer:  t.base.Signer,
+		Context: ctx,
+	}
+}
@@ -0,0 +1,43 @@
+package txutil
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBaseTransactor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt, err := NewBaseTransactor(key, big.NewInt(314159))
+	if err != nil {
+		t.Fatalf("NewBaseTransactor: %v", err)
+	}
+	if want := crypto.PubkeyToAddress(key.PublicKey); bt.From() != want {
+		t.Fatalf("From = %s, want %s", bt.From().Hex(), want.Hex())
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, 1)
+	first := bt.Opts(ctx)
+	if first.Context != ctx || first.From != bt.From() || first.Signer == nil {
+		t.Fatalf("Opts did not carry context, From and Signer: %+v", first)
+	}
+
+	// per-call fields set on one copy must not leak into the next
+	first.Nonce = big.NewInt(7)
+	first.Value = big.NewInt(1)
+	first.NoSend = true
+	second := bt.Opts(context.Background())
+	if second.Nonce != nil || second.Value != nil || second.NoSend {
+		t.Fatalf("per-call fields leaked between copies: %+v", second)
+	}
+
+	if _, err := NewBaseTransactor(key, nil); err == nil {
+		t.Fatal("expected error for nil chain ID")
+	}
+}
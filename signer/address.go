@@ -0,0 +1,100 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+)
+
+// EAMActorID is the Ethereum Address Manager actor. f410 addresses are
+// delegated addresses in its namespace whose subaddress is the 20-byte EVM
+// address.
+const EAMActorID = 10
+
+// maskedIDPrefix marks an EVM address that encodes a Filecoin actor ID
+// (0xff followed by 11 zero bytes, then the ID as a big-endian uint64).
+var maskedIDPrefix = [12]byte{0xff}
+
+// EVMToFilecoin returns the Filecoin form of an EVM address: the f410
+// address for ordinary addresses, or the f0 ID address for masked ID
+// addresses (0xff0000...).
+func EVMToFilecoin(addr common.Address) (address.Address, error) {
+	if bytes.Equal(addr[:12], maskedIDPrefix[:]) {
+		return address.NewIDAddress(binary.BigEndian.Uint64(addr[12:]))
+	}
+	return address.NewDelegatedAddress(EAMActorID, addr.Bytes())
+}
+
+// FilecoinToEVM returns the EVM form of a Filecoin address. f410 addresses
+// map to their embedded EVM address and f0 ID addresses to the masked ID
+// form. f1 and f3 addresses have no EVM form until resolved to an ID.
+func FilecoinToEVM(addr address.Address) (common.Address, error) {
+	switch addr.Protocol() {
+	case address.ID:
+		id, err := address.IDFromAddress(addr)
+		if err != nil {
+			return common.Address{}, err
+		}
+		var out common.Address
+		copy(out[:], maskedIDPrefix[:])
+		binary.BigEndian.PutUint64(out[12:], id)
+		return out, nil
+	case address.Delegated:
+		namespace, subaddr, err := delegatedPayload(addr)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if namespace != EAMActorID {
+			return common.Address{}, fmt.Errorf("delegated address %s is in namespace %d, not the EAM (%d)", addr, namespace, EAMActorID)
+		}
+		if len(subaddr) != common.AddressLength {
+			return common.Address{}, fmt.Errorf("delegated address %s has a %d-byte subaddress, want %d", addr, len(subaddr), common.AddressLength)
+		}
+		return common.BytesToAddress(subaddr), nil
+	default:
+		return common.Address{}, fmt.Errorf("address %s (protocol %d) has no EVM equivalent; resolve it to an ID address first", addr, addr.Protocol())
+	}
+}
+
+// IsDelegatedAddress reports whether addr is an f410 address, i.e. a
+// delegated address that wraps an EVM address.
+func IsDelegatedAddress(addr address.Address) bool {
+	_, ok := DelegatedEVMAddress(addr)
+	return ok
+}
+
+// DelegatedEVMAddress returns the EVM address wrapped by an f410 address.
+// It returns false for any other kind of address.
+func DelegatedEVMAddress(addr address.Address) (common.Address, bool) {
+	if addr.Protocol() != address.Delegated {
+		return common.Address{}, false
+	}
+	namespace, subaddr, err := delegatedPayload(addr)
+	if err != nil || namespace != EAMActorID || len(subaddr) != common.AddressLength {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(subaddr), true
+}
+
+// SameAccount reports whether a Filecoin address and an EVM address refer to
+// the same account, e.g. an f410 address and the 0x address it wraps.
+// Addresses that can only be matched by resolving them on chain (f1, f3)
+// never match.
+func SameAccount(fil address.Address, evm common.Address) bool {
+	converted, err := FilecoinToEVM(fil)
+	return err == nil && converted == evm
+}
+
+// delegatedPayload splits a delegated address payload into its namespace
+// actor ID and subaddress.
+func delegatedPayload(addr address.Address) (uint64, []byte, error) {
+	payload := addr.Payload()
+	namespace, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("invalid delegated address payload")
+	}
+	return namespace, payload[n:], nil
+}
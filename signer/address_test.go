@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+)
+
+func TestEVMFilecoinConversion(t *testing.T) {
+	tests := []struct {
+		name string
+		evm  string
+		fil  string
+	}{
+		{"f410", "0xd388ab098ed3e84c0d808776440b48f685198498", "f410f2oekwcmo2pueydmaq53eic2i62crtbeyuzx2gmy"},
+		{"masked id", "0xff00000000000000000000000000000000000400", "f01024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evm := common.HexToAddress(tt.evm)
+			fil, err := address.NewFromString(tt.fil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotFil, err := EVMToFilecoin(evm)
+			if err != nil {
+				t.Fatalf("EVMToFilecoin: %v", err)
+			}
+			if gotFil != fil {
+				t.Errorf("EVMToFilecoin(%s) = %s, want %s", evm, gotFil, fil)
+			}
+
+			gotEVM, err := FilecoinToEVM(fil)
+			if err != nil {
+				t.Fatalf("FilecoinToEVM: %v", err)
+			}
+			if gotEVM != evm {
+				t.Errorf("FilecoinToEVM(%s) = %s, want %s", fil, gotEVM, evm)
+			}
+		})
+	}
+}
+
+func TestFilecoinToEVM_Unsupported(t *testing.T) {
+	key := make([]byte, 32)
+	key[31] = 1
+	s, err := NewSecp256k1Signer(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FilecoinToEVM(s.FilecoinAddress()); err == nil {
+		t.Error("expected error for f1 address")
+	}
+
+	other, err := address.NewDelegatedAddress(32, make([]byte, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FilecoinToEVM(other); err == nil {
+		t.Error("expected error for non-EAM delegated address")
+	}
+}
+
+func TestDelegatedAddressHelpers(t *testing.T) {
+	evm := common.HexToAddress("0xd388ab098ed3e84c0d808776440b48f685198498")
+	f410, err := address.NewFromString("f410f2oekwcmo2pueydmaq53eic2i62crtbeyuzx2gmy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := address.NewIDAddress(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsDelegatedAddress(f410) {
+		t.Error("IsDelegatedAddress(f410) = false, want true")
+	}
+	if IsDelegatedAddress(id) {
+		t.Error("IsDelegatedAddress(f0) = true, want false")
+	}
+
+	got, ok := DelegatedEVMAddress(f410)
+	if !ok || got != evm {
+		t.Errorf("DelegatedEVMAddress(f410) = %s, %v; want %s, true", got, ok, evm)
+	}
+	if _, ok := DelegatedEVMAddress(id); ok {
+		t.Error("DelegatedEVMAddress(f0) ok = true, want false")
+	}
+
+	if !SameAccount(f410, evm) {
+		t.Error("SameAccount(f410, evm) = false, want true")
+	}
+	if SameAccount(f410, common.HexToAddress("0x01")) {
+		t.Error("SameAccount with a different address = true, want false")
+	}
+}
@@ -50,6 +50,11 @@ func NewBLSSignerFromLotusExport(exported string) (*BLSSigner, error) {
 	return NewBLSSigner(ki.PrivateKey)
 }
 
+// ExportLotus returns the key in the format produced by `lotus wallet export`.
+func (s *BLSSigner) ExportLotus() (string, error) {
+	return encodeLotusKey("bls", s.raw)
+}
+
 func (s *BLSSigner) FilecoinAddress() address.Address {
 	return s.filAddr
 }
@@ -24,6 +24,14 @@ func decodeLotusKey(exported string) (*lotusKeyInfo, error) {
 	return &ki, nil
 }
 
+func encodeLotusKey(keyType string, privateKey []byte) (string, error) {
+	j, err := json.Marshal(lotusKeyInfo{Type: keyType, PrivateKey: privateKey})
+	if err != nil {
+		return "", fmt.Errorf("marshaling key: %w", err)
+	}
+	return hex.EncodeToString(j), nil
+}
+
 // FromLotusExport creates a Signer from a lotus-exported private key string.
 // The key type (secp256k1 or bls) is detected automatically.
 // For secp256k1 keys, the returned Signer also implements EVMSigner.
@@ -89,6 +89,12 @@ func NewSecp256k1SignerFromLotusExport(exported string) (*Secp256k1Signer, error
 	return NewSecp256k1Signer(ki.PrivateKey)
 }
 
+// ExportLotus returns the key in the format produced by `lotus wallet export`
+// and accepted by `lotus wallet import`.
+func (s *Secp256k1Signer) ExportLotus() (string, error) {
+	return encodeLotusKey("secp256k1", s.raw)
+}
+
 func (s *Secp256k1Signer) FilecoinAddress() address.Address {
 	return s.filAddr
 }
@@ -128,3 +134,14 @@ func (s *Secp256k1Signer) SignDigest(digest []byte) ([]byte, error) {
 	}
 	return ethcrypto.Sign(digest, s.ecdsaKey)
 }
+
+// SignHash signs a 32-byte digest (e.g. an EIP-712 hash) and returns a
+// 65-byte [R || S || V] signature with V = 27 or 28.
+func (s *Secp256k1Signer) SignHash(digest [32]byte) ([]byte, error) {
+	sig, err := ethcrypto.Sign(digest[:], s.ecdsaKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
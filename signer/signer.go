@@ -32,9 +32,13 @@ type Signer interface {
 // go-ethereum crypto.Sign convention). Callers that need on-chain
 // ECDSA recovery (e.g. PDP extraData) must normalize V to 27/28
 // themselves; the digest-signer interface keeps the raw recovery ID.
+//
+// SignHash is the on-chain form: the same signature with V = 27 or 28,
+// ready to be passed to ecrecover or embedded in EIP-712 extraData.
 type EVMSigner interface {
 	Signer
 	EVMAddress() common.Address
 	Transactor(chainID *big.Int) (*bind.TransactOpts, error)
 	SignDigest(digest []byte) ([]byte, error)
+	SignHash(digest [32]byte) ([]byte, error)
 }
@@ -1,6 +1,7 @@
 package signer
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"math/big"
@@ -87,6 +88,20 @@ func TestSecp256k1Signer_DualProtocol(t *testing.T) {
 	if _, err := s.SignDigest([]byte("short")); err == nil {
 		t.Error("SignDigest should reject non-32-byte input")
 	}
+
+	// SignHash is SignDigest with V shifted to 27/28
+	var hash [32]byte
+	copy(hash[:], digest)
+	hashSig, err := s.SignHash(hash)
+	if err != nil {
+		t.Fatalf("SignHash: %v", err)
+	}
+	if len(hashSig) != 65 || (hashSig[64] != 27 && hashSig[64] != 28) {
+		t.Fatalf("SignHash = %d bytes with V = %d, want 65 bytes with V 27 or 28", len(hashSig), hashSig[64])
+	}
+	if !bytes.Equal(hashSig[:64], sigBytes[:64]) || hashSig[64] != sigBytes[64]+27 {
+		t.Error("SignHash does not match SignDigest with V + 27")
+	}
 }
 
 func TestSecp256k1Signer_FromLotusExport(t *testing.T) {
@@ -107,6 +122,36 @@ func TestSecp256k1Signer_FromLotusExport(t *testing.T) {
 	}
 }
 
+func TestSecp256k1Signer_ExportLotusRoundTrip(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSecp256k1SignerFromECDSA(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := s.ExportLotus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported != makeTestLotusExport("secp256k1", ethcrypto.FromECDSA(key)) {
+		t.Errorf("ExportLotus() = %s, want lotus wallet export format", exported)
+	}
+
+	imported, err := NewSecp256k1SignerFromLotusExport(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.FilecoinAddress() != s.FilecoinAddress() {
+		t.Errorf("FilecoinAddress() = %s, want %s", imported.FilecoinAddress(), s.FilecoinAddress())
+	}
+	if imported.EVMAddress() != s.EVMAddress() {
+		t.Errorf("EVMAddress() = %s, want %s", imported.EVMAddress(), s.EVMAddress())
+	}
+}
+
 func TestSecp256k1Signer_RejectsWrongType(t *testing.T) {
 	exported := makeTestLotusExport("bls", []byte("dummy"))
 	_, err := NewSecp256k1SignerFromLotusExport(exported)
@@ -264,6 +309,27 @@ func TestBLSSigner_FromLotusExport(t *testing.T) {
 	}
 }
 
+func TestBLSSigner_ExportLotusRoundTrip(t *testing.T) {
+	var ikm [32]byte
+	copy(ikm[:], []byte("test-bls-key-seed-for-unit-test!"))
+	s, err := NewBLSSigner(blst.KeyGen(ikm[:]).Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := s.ExportLotus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := FromLotusExport(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.FilecoinAddress() != s.FilecoinAddress() {
+		t.Errorf("FilecoinAddress() = %s, want %s", imported.FilecoinAddress(), s.FilecoinAddress())
+	}
+}
+
 func TestBLSSigner_RejectsWrongType(t *testing.T) {
 	exported := makeTestLotusExport("secp256k1", []byte("dummy"))
 	_, err := NewBLSSignerFromLotusExport(exported)
@@ -3,10 +3,14 @@ package spregistry
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 const (
@@ -19,14 +23,157 @@ const (
 	CapMinProvingPeriod = "minProvingPeriodInEpochs"
 	CapLocation         = "location"
 	CapPaymentToken     = "paymentTokenAddress"
+	CapVersion          = "capabilityVersion"
 )
 
+// CapabilityEncodingVersion identifies the capability value encoding below.
+// Version 1 matches the JS SDK:
+//
+//   - string:  raw UTF-8 bytes
+//   - uint:    minimal big-endian bytes, at most 32 bytes (uint256)
+//   - bool:    0x01 for true; an absent key, an empty value or all-zero bytes
+//     mean false
+//   - address: the 20 address bytes (a 32-byte left-padded word is tolerated)
+//
+// EncodePDPCapabilities records it under CapVersion; products registered
+// before the key existed are read as version 1. A later version may add keys
+// freely, but must bump the version if it changes how an existing key is
+// encoded, so older clients can detect it instead of misreading values.
+const CapabilityEncodingVersion = 1
+
+// CapabilityType is the value type expected for a capability key.
+type CapabilityType int
+
+const (
+	CapTypeBytes CapabilityType = iota
+	CapTypeString
+	CapTypeUint
+	CapTypeBool
+	CapTypeAddress
+)
+
+func (t CapabilityType) String() string {
+	switch t {
+	case CapTypeString:
+		return "string"
+	case CapTypeUint:
+		return "uint"
+	case CapTypeBool:
+		return "bool"
+	case CapTypeAddress:
+		return "address"
+	default:
+		return "bytes"
+	}
+}
+
+// PDPCapabilityTypes maps each well-known PDP capability key to its value
+// type. Keys not listed here are treated as opaque bytes.
+var PDPCapabilityTypes = map[string]CapabilityType{
+	CapServiceURL:       CapTypeString,
+	CapMinPieceSize:     CapTypeUint,
+	CapMaxPieceSize:     CapTypeUint,
+	CapIPNIPiece:        CapTypeBool,
+	CapIPNIIPFS:         CapTypeBool,
+	CapStoragePrice:     CapTypeUint,
+	CapMinProvingPeriod: CapTypeUint,
+	CapLocation:         CapTypeString,
+	CapPaymentToken:     CapTypeAddress,
+	CapVersion:          CapTypeUint,
+}
+
+// CapabilityTypeOf returns the expected value type for key.
+func CapabilityTypeOf(key string) CapabilityType {
+	if t, ok := PDPCapabilityTypes[key]; ok {
+		return t
+	}
+	return CapTypeBytes
+}
+
+// DecodeCapabilityUint decodes a uint capability value. Uint keys are always
+// big-endian, so bytes that happen to spell a decimal number (e.g. "1024")
+// are still read as binary. Values longer than 32 bytes are rejected.
+func DecodeCapabilityUint(v []byte) (*big.Int, error) {
+	if len(v) > 32 {
+		return nil, fmt.Errorf("uint value is %d bytes, max 32", len(v))
+	}
+	return new(big.Int).SetBytes(v), nil
+}
+
+// DecodeCapabilityAddress decodes an address capability value.
+func DecodeCapabilityAddress(v []byte) (common.Address, error) {
+	switch {
+	case len(v) == common.AddressLength:
+		return common.BytesToAddress(v), nil
+	case len(v) == 32 && new(big.Int).SetBytes(v[:12]).Sign() == 0:
+		return common.BytesToAddress(v[12:]), nil
+	default:
+		return common.Address{}, fmt.Errorf("address value is %d bytes, want 20", len(v))
+	}
+}
+
+// ValidateCapabilityValue checks that v is a well-formed encoding for key.
+func ValidateCapabilityValue(key string, v []byte) error {
+	var err error
+	switch CapabilityTypeOf(key) {
+	case CapTypeString:
+		if !utf8.Valid(v) {
+			err = fmt.Errorf("string value is not valid UTF-8")
+		}
+	case CapTypeUint:
+		_, err = DecodeCapabilityUint(v)
+	case CapTypeAddress:
+		_, err = DecodeCapabilityAddress(v)
+	}
+	if err != nil {
+		return fmt.Errorf("capability %q: %w", key, err)
+	}
+	return nil
+}
+
+// CapabilityVersionOf returns the capability encoding version recorded in
+// capabilities, or 1 if the product predates versioning. It returns 0 for an
+// unreadable version value.
+func CapabilityVersionOf(capabilities map[string][]byte) int {
+	v, ok := capabilities[CapVersion]
+	if !ok {
+		return 1
+	}
+	n, err := DecodeCapabilityUint(v)
+	if err != nil || n.Sign() <= 0 || !n.IsInt64() || n.Int64() > math.MaxInt32 {
+		return 0
+	}
+	return int(n.Int64())
+}
+
+// DecodePDPCapabilitiesStrict is like DecodePDPCapabilities but returns an
+// error if any well-known capability has a value of the wrong type, or if
+// the capabilities use an encoding version this client does not know.
+func DecodePDPCapabilitiesStrict(capabilities map[string][]byte) (*PDPOffering, error) {
+	if version := CapabilityVersionOf(capabilities); version < 1 || version > CapabilityEncodingVersion {
+		return nil, fmt.Errorf("unsupported capability encoding version %d (supported: 1-%d)", version, CapabilityEncodingVersion)
+	}
+	for key, v := range capabilities {
+		if err := ValidateCapabilityValue(key, v); err != nil {
+			return nil, err
+		}
+	}
+	return DecodePDPCapabilities(capabilities), nil
+}
+
+// DecodePDPCapabilities decodes a capability map into a PDPOffering, reading
+// each well-known key by its CapabilityType. It is lenient: malformed values
+// decode to their zero value. Use DecodePDPCapabilitiesStrict to reject them,
+// or an encoding version newer than CapabilityEncodingVersion, instead. The
+// offering's CapabilityVersion reports the encoding version the provider
+// wrote; keys added by newer versions stay available in the raw map.
 func DecodePDPCapabilities(capabilities map[string][]byte) *PDPOffering {
 	offering := &PDPOffering{
 		MinPieceSizeInBytes:      big.NewInt(0),
 		MaxPieceSizeInBytes:      big.NewInt(0),
 		StoragePricePerTiBPerDay: big.NewInt(0),
 		MinProvingPeriodInEpochs: big.NewInt(0),
+		CapabilityVersion:        CapabilityVersionOf(capabilities),
 	}
 
 	if v, ok := capabilities[CapServiceURL]; ok {
@@ -34,22 +181,22 @@ func DecodePDPCapabilities(capabilities map[string][]byte) *PDPOffering {
 	}
 
 	if v, ok := capabilities[CapMinPieceSize]; ok {
-		offering.MinPieceSizeInBytes = new(big.Int).SetBytes(v)
+		offering.MinPieceSizeInBytes = decodeUintLenient(v)
 	}
 
 	if v, ok := capabilities[CapMaxPieceSize]; ok {
-		offering.MaxPieceSizeInBytes = new(big.Int).SetBytes(v)
+		offering.MaxPieceSizeInBytes = decodeUintLenient(v)
 	}
 
-	_, offering.IPNIPiece = capabilities[CapIPNIPiece]
-	_, offering.IPNIIPFS = capabilities[CapIPNIIPFS]
+	offering.IPNIPiece = decodeCapabilityBool(capabilities[CapIPNIPiece])
+	offering.IPNIIPFS = decodeCapabilityBool(capabilities[CapIPNIIPFS])
 
 	if v, ok := capabilities[CapStoragePrice]; ok {
-		offering.StoragePricePerTiBPerDay = new(big.Int).SetBytes(v)
+		offering.StoragePricePerTiBPerDay = decodeUintLenient(v)
 	}
 
 	if v, ok := capabilities[CapMinProvingPeriod]; ok {
-		offering.MinProvingPeriodInEpochs = new(big.Int).SetBytes(v)
+		offering.MinProvingPeriodInEpochs = decodeUintLenient(v)
 	}
 
 	if v, ok := capabilities[CapLocation]; ok {
@@ -57,8 +204,8 @@ func DecodePDPCapabilities(capabilities map[string][]byte) *PDPOffering {
 	}
 
 	if v, ok := capabilities[CapPaymentToken]; ok {
-		if len(v) >= 20 {
-			offering.PaymentTokenAddress = common.BytesToAddress(v[len(v)-20:])
+		if len(v) >= common.AddressLength {
+			offering.PaymentTokenAddress = common.BytesToAddress(v[len(v)-common.AddressLength:])
 		}
 	}
 
@@ -100,9 +247,21 @@ func EncodePDPCapabilities(offering *PDPOffering, extraCapabilities map[string]s
 	keys = append(keys, CapPaymentToken)
 	values = append(values, offering.PaymentTokenAddress.Bytes())
 
+	keys = append(keys, CapVersion)
+	values = append(values, bigIntToBytes(big.NewInt(CapabilityEncodingVersion)))
+
 	for k, v := range extraCapabilities {
+		if k == CapVersion {
+			return nil, nil, fmt.Errorf("capability %q is set by the encoder and cannot be passed as an extra capability", k)
+		}
 		keys = append(keys, k)
-		if v == "" {
+		if t := CapabilityTypeOf(k); t != CapTypeBytes {
+			encoded, err := encodeTypedCapability(t, v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s value for capability %q: %w", t, k, err)
+			}
+			values = append(values, encoded)
+		} else if v == "" {
 			values = append(values, []byte{0x01})
 		} else if strings.HasPrefix(v, "0x") {
 			decoded, err := hex.DecodeString(v[2:])
@@ -118,6 +277,22 @@ func EncodePDPCapabilities(offering *PDPOffering, extraCapabilities map[string]s
 	return keys, values, nil
 }
 
+// ExtraCapabilities returns the capabilities that are not part of
+// PDPOffering, hex-encoded in the form EncodePDPCapabilities accepts, so a
+// product can be re-encoded without losing keys this client does not know:
+//
+//	keys, values, err := EncodePDPCapabilities(&info.Offering, ExtraCapabilities(info.Capabilities))
+func ExtraCapabilities(capabilities map[string][]byte) map[string]string {
+	extras := make(map[string]string)
+	for k, v := range capabilities {
+		if _, known := PDPCapabilityTypes[k]; known {
+			continue
+		}
+		extras[k] = hexutil.Encode(v)
+	}
+	return extras
+}
+
 func CapabilitiesListToMap(keys []string, values [][]byte) map[string][]byte {
 	result := make(map[string][]byte, len(keys))
 	for i := 0; i < len(keys) && i < len(values); i++ {
@@ -126,6 +301,56 @@ func CapabilitiesListToMap(keys []string, values [][]byte) map[string][]byte {
 	return result
 }
 
+// encodeTypedCapability encodes a user-supplied string for a well-known key
+// so that, e.g., "1024" for a uint key is stored as 0x0400 rather than ASCII.
+func encodeTypedCapability(t CapabilityType, v string) ([]byte, error) {
+	switch t {
+	case CapTypeUint:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+			return nil, fmt.Errorf("%q is not a uint256", v)
+		}
+		return bigIntToBytes(n), nil
+	case CapTypeBool:
+		if v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, err
+			}
+			if !b {
+				return []byte{0x00}, nil
+			}
+		}
+		return []byte{0x01}, nil
+	case CapTypeAddress:
+		if !common.IsHexAddress(v) {
+			return nil, fmt.Errorf("%q is not an address", v)
+		}
+		return common.HexToAddress(v).Bytes(), nil
+	default:
+		return []byte(v), nil
+	}
+}
+
+// decodeCapabilityBool treats a bool capability as true only if its value has
+// a nonzero byte, so an explicit 0x00 is not mistaken for true.
+func decodeCapabilityBool(v []byte) bool {
+	for _, b := range v {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeUintLenient(v []byte) *big.Int {
+	n, err := DecodeCapabilityUint(v)
+	if err != nil {
+		return big.NewInt(0)
+	}
+	return n
+}
+
 func bigIntToBytes(n *big.Int) []byte {
 	if n == nil {
 		return []byte{0}
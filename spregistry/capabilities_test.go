@@ -140,6 +140,7 @@ func TestEncodePDPCapabilities(t *testing.T) {
 		CapMinProvingPeriod,
 		CapLocation,
 		CapPaymentToken,
+		CapVersion,
 	}
 
 	if len(keys) != len(expectedKeys) {
@@ -196,7 +197,7 @@ func TestEncodePDPCapabilities_WithExtraCapabilities(t *testing.T) {
 		t.Fatalf("EncodePDPCapabilities failed: %v", err)
 	}
 
-	expectedBaseCount := 7 // no IPNIPiece or IPNIIPFS since both are false
+	expectedBaseCount := 8 // no IPNIPiece or IPNIIPFS since both are false
 	expectedTotal := expectedBaseCount + len(extras)
 
 	if len(keys) != expectedTotal {
@@ -219,6 +220,69 @@ func TestEncodePDPCapabilities_WithExtraCapabilities(t *testing.T) {
 	}
 }
 
+func TestCapabilityVersion(t *testing.T) {
+	offering := PDPOffering{ServiceURL: "https://provider.example.com", MinPieceSizeInBytes: big.NewInt(1024)}
+	keys, values, err := EncodePDPCapabilities(&offering, map[string]string{"region": "us"})
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+	caps := CapabilitiesListToMap(keys, values)
+	if v := CapabilityVersionOf(caps); v != CapabilityEncodingVersion {
+		t.Errorf("CapabilityVersionOf() = %d, want %d", v, CapabilityEncodingVersion)
+	}
+	if decoded := DecodePDPCapabilities(caps); decoded.CapabilityVersion != CapabilityEncodingVersion {
+		t.Errorf("decoded.CapabilityVersion = %d, want %d", decoded.CapabilityVersion, CapabilityEncodingVersion)
+	}
+
+	// products registered before versioning read as v1
+	legacy := map[string][]byte{CapServiceURL: []byte("https://legacy.example.com")}
+	if decoded := DecodePDPCapabilities(legacy); decoded.CapabilityVersion != 1 || decoded.ServiceURL != "https://legacy.example.com" {
+		t.Errorf("legacy decode = version %d, URL %q", decoded.CapabilityVersion, decoded.ServiceURL)
+	}
+	if _, err := DecodePDPCapabilitiesStrict(legacy); err != nil {
+		t.Errorf("DecodePDPCapabilitiesStrict(legacy) error = %v", err)
+	}
+
+	// a future version still decodes leniently but is rejected by strict mode
+	future := map[string][]byte{CapServiceURL: []byte("https://next.example.com"), CapVersion: {0x02}, "newKey": {0x07}}
+	if decoded := DecodePDPCapabilities(future); decoded.CapabilityVersion != 2 || decoded.ServiceURL != "https://next.example.com" {
+		t.Errorf("future decode = version %d, URL %q", decoded.CapabilityVersion, decoded.ServiceURL)
+	}
+	if _, err := DecodePDPCapabilitiesStrict(future); err == nil {
+		t.Error("DecodePDPCapabilitiesStrict(future) expected error")
+	}
+
+	if _, _, err := EncodePDPCapabilities(&offering, map[string]string{CapVersion: "5"}); err == nil {
+		t.Error("EncodePDPCapabilities with capabilityVersion extra expected error")
+	}
+}
+
+func TestExtraCapabilities_RoundTrip(t *testing.T) {
+	offering := PDPOffering{ServiceURL: "https://provider.example.com", MinPieceSizeInBytes: big.NewInt(1024)}
+	keys, values, err := EncodePDPCapabilities(&offering, map[string]string{"region": "us", "flag": ""})
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+	caps := CapabilitiesListToMap(keys, values)
+	caps["futureKey"] = []byte{0x00, 0x01}
+
+	extras := ExtraCapabilities(caps)
+	if _, ok := extras[CapServiceURL]; ok {
+		t.Error("ExtraCapabilities should skip offering keys")
+	}
+	if _, ok := extras[CapVersion]; ok {
+		t.Error("ExtraCapabilities should skip the version key")
+	}
+
+	keys, values, err = EncodePDPCapabilities(DecodePDPCapabilities(caps), extras)
+	if err != nil {
+		t.Fatalf("re-encode failed: %v", err)
+	}
+	if changes := diffCapabilities(caps, CapabilitiesListToMap(keys, values)); len(changes) != 0 {
+		t.Errorf("re-encode changed capabilities: %+v", changes)
+	}
+}
+
 func TestEncodePDPCapabilities_InvalidHex(t *testing.T) {
 	offering := PDPOffering{
 		ServiceURL:               "https://provider.example.com",
@@ -266,3 +330,168 @@ func TestCapabilitiesListToMap_MismatchedLengths(t *testing.T) {
 		t.Errorf("len(result) = %d, want 2", len(result))
 	}
 }
+
+func TestDecodeCapabilityUint(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   []byte
+		want    *big.Int
+		wantErr bool
+	}{
+		{name: "big-endian", value: big.NewInt(1024).Bytes(), want: big.NewInt(1024)},
+		{name: "single digit byte is binary", value: []byte{'5'}, want: big.NewInt(0x35)},
+		{name: "empty", value: []byte{}, want: big.NewInt(0)},
+		{name: "digit bytes are binary", value: []byte("1024"), want: big.NewInt(0x31303234)},
+		{name: "too long", value: make([]byte, 33), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCapabilityUint(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeCapabilityUint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Cmp(tt.want) != 0 {
+				t.Errorf("DecodeCapabilityUint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePDPCapabilities_DigitBytesAreBinary(t *testing.T) {
+	// 0x3132 is 12594 in big-endian, and also the ASCII string "12"
+	capabilities := map[string][]byte{
+		CapMinPieceSize: big.NewInt(0x3132).Bytes(),
+		CapMaxPieceSize: big.NewInt(1073741824).Bytes(),
+	}
+
+	offering := DecodePDPCapabilities(capabilities)
+	if offering.MinPieceSizeInBytes.Cmp(big.NewInt(0x3132)) != 0 {
+		t.Errorf("MinPieceSizeInBytes = %s, want %d", offering.MinPieceSizeInBytes, 0x3132)
+	}
+
+	strict, err := DecodePDPCapabilitiesStrict(capabilities)
+	if err != nil {
+		t.Fatalf("DecodePDPCapabilitiesStrict() error = %v", err)
+	}
+	if strict.MinPieceSizeInBytes.Cmp(big.NewInt(0x3132)) != 0 {
+		t.Errorf("strict MinPieceSizeInBytes = %s, want %d", strict.MinPieceSizeInBytes, 0x3132)
+	}
+}
+
+func TestDecodePDPCapabilitiesStrict(t *testing.T) {
+	offering := PDPOffering{
+		ServiceURL:               "https://provider.example.com",
+		MinPieceSizeInBytes:      big.NewInt(1024),
+		MaxPieceSizeInBytes:      big.NewInt(1073741824),
+		IPNIPiece:                true,
+		StoragePricePerTiBPerDay: big.NewInt(1000000),
+		MinProvingPeriodInEpochs: big.NewInt(2880),
+		Location:                 "US-EAST",
+		PaymentTokenAddress:      common.HexToAddress("0xb3042734b608a1B16e9e86B374A3f3e389B4cDf0"),
+	}
+
+	keys, values, err := EncodePDPCapabilities(&offering, nil)
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+
+	got, err := DecodePDPCapabilitiesStrict(CapabilitiesListToMap(keys, values))
+	if err != nil {
+		t.Fatalf("DecodePDPCapabilitiesStrict failed: %v", err)
+	}
+	if got.MinPieceSizeInBytes.Cmp(offering.MinPieceSizeInBytes) != 0 {
+		t.Errorf("MinPieceSizeInBytes = %s, want %s", got.MinPieceSizeInBytes, offering.MinPieceSizeInBytes)
+	}
+	if got.PaymentTokenAddress != offering.PaymentTokenAddress {
+		t.Errorf("PaymentTokenAddress = %s, want %s", got.PaymentTokenAddress, offering.PaymentTokenAddress)
+	}
+
+	bad := CapabilitiesListToMap(keys, values)
+	bad[CapPaymentToken] = []byte{0x01, 0x02}
+	if _, err := DecodePDPCapabilitiesStrict(bad); err == nil {
+		t.Error("expected error for short address")
+	}
+}
+
+func TestEncodePDPCapabilities_TypedExtras(t *testing.T) {
+	extras := map[string]string{
+		CapMinPieceSize: "1024",
+		CapIPNIIPFS:     "true",
+	}
+
+	keys, values, err := EncodePDPCapabilities(&PDPOffering{}, extras)
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+
+	// extras are appended after the offering, so they win in the map
+	capMap := CapabilitiesListToMap(keys, values)
+	if got := new(big.Int).SetBytes(capMap[CapMinPieceSize]); got.Cmp(big.NewInt(1024)) != 0 {
+		t.Errorf("minPieceSizeInBytes = %s, want 1024", got)
+	}
+	if string(capMap[CapIPNIIPFS]) != "\x01" {
+		t.Errorf("ipniIpfs = %x, want 01", capMap[CapIPNIIPFS])
+	}
+
+	for _, bad := range []map[string]string{
+		{CapMinPieceSize: "-1"},
+		{CapMinPieceSize: "lots"},
+		{CapPaymentToken: "not-an-address"},
+	} {
+		if _, _, err := EncodePDPCapabilities(&PDPOffering{}, bad); err == nil {
+			t.Errorf("EncodePDPCapabilities(%v) expected error", bad)
+		}
+	}
+}
+
+func TestDecodePDPCapabilities_ExplicitFalseFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+		want  bool
+	}{
+		{name: "one", value: []byte{0x01}, want: true},
+		{name: "explicit zero", value: []byte{0x00}, want: false},
+		{name: "padded zero", value: make([]byte, 32), want: false},
+		{name: "empty", value: []byte{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offering := DecodePDPCapabilities(map[string][]byte{
+				CapIPNIPiece: tt.value,
+				CapIPNIIPFS:  tt.value,
+			})
+			if offering.IPNIPiece != tt.want {
+				t.Errorf("IPNIPiece = %v, want %v", offering.IPNIPiece, tt.want)
+			}
+			if offering.IPNIIPFS != tt.want {
+				t.Errorf("IPNIIPFS = %v, want %v", offering.IPNIIPFS, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodePDPCapabilities_FalseFlagRoundTrip(t *testing.T) {
+	keys, values, err := EncodePDPCapabilities(&PDPOffering{}, map[string]string{
+		CapIPNIPiece: "false",
+		CapIPNIIPFS:  "true",
+	})
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+
+	capMap := CapabilitiesListToMap(keys, values)
+	if string(capMap[CapIPNIPiece]) != "\x00" {
+		t.Errorf("ipniPiece = %x, want 00", capMap[CapIPNIPiece])
+	}
+
+	offering := DecodePDPCapabilities(capMap)
+	if offering.IPNIPiece {
+		t.Error("IPNIPiece = true, want false")
+	}
+	if !offering.IPNIIPFS {
+		t.Error("IPNIIPFS = false, want true")
+	}
+}
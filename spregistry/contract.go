@@ -7,7 +7,9 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/data-preservation-programs/go-synapse/contracts"
 	"github.com/data-preservation-programs/go-synapse/pkg/abix"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -275,14 +277,31 @@ const SPRegistryABIJSON = `[
 		"inputs": [{"name": "productType", "type": "uint8"}],
 		"outputs": [],
 		"stateMutability": "nonpayable"
-	}
+	},
+	{
+		"type": "event",
+		"name": "ProviderRegistered",
+		"inputs": [
+			{"name": "providerId", "type": "uint256", "indexed": true},
+			{"name": "serviceProvider", "type": "address", "indexed": true},
+			{"name": "payee", "type": "address", "indexed": true}
+		],
+		"anonymous": false
+	},` + contracts.UpgradeableErrorsABIJSON + `
 ]`
 
+// DefaultBaseFeeMultiplier is the factor applied to the latest block's base
+// fee when computing the fee cap for registry transactions.
+const DefaultBaseFeeMultiplier = 2.0
+
 type Contract struct {
 	address common.Address
 	abi     abi.ABI
 	client  *ethclient.Client
 
+	feeMu             sync.Mutex // guards baseFeeMultiplier
+	baseFeeMultiplier float64
+
 	nonceMu     sync.Mutex
 	nonce       uint64
 	nonceLoaded bool
@@ -295,12 +314,34 @@ func NewContract(address common.Address, client *ethclient.Client) (*Contract, e
 	}
 
 	return &Contract{
-		address: address,
-		abi:     parsedABI,
-		client:  client,
+		address:           address,
+		abi:               parsedABI,
+		client:            client,
+		baseFeeMultiplier: DefaultBaseFeeMultiplier,
 	}, nil
 }
 
+// SetBaseFeeMultiplier changes the factor applied to the base fee when
+// computing the fee cap, e.g. 3 to improve inclusion during congestion.
+// Values below 1 are rejected since the fee cap would not cover the base fee.
+// It is safe to call while transactions are being sent.
+func (c *Contract) SetBaseFeeMultiplier(multiplier float64) error {
+	if multiplier < 1 {
+		return fmt.Errorf("base fee multiplier must be at least 1, got %v", multiplier)
+	}
+	c.feeMu.Lock()
+	c.baseFeeMultiplier = multiplier
+	c.feeMu.Unlock()
+	return nil
+}
+
+// feeMultiplier returns the current base fee multiplier.
+func (c *Contract) feeMultiplier() float64 {
+	c.feeMu.Lock()
+	defer c.feeMu.Unlock()
+	return c.baseFeeMultiplier
+}
+
 func (c *Contract) Address() common.Address {
 	return c.address
 }
@@ -316,7 +357,7 @@ func (c *Contract) RegistrationFee(ctx context.Context) (*big.Int, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("REGISTRATION_FEE call failed: %w", err)
+		return nil, fmt.Errorf("REGISTRATION_FEE call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("REGISTRATION_FEE", result)
@@ -347,7 +388,7 @@ func (c *Contract) GetProvider(ctx context.Context, providerID *big.Int) (*GetPr
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("getProvider call failed: %w", err)
+		return nil, fmt.Errorf("getProvider call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	var res getProviderByAddressOutput
@@ -378,7 +419,7 @@ func (c *Contract) GetProviderByAddress(ctx context.Context, addr common.Address
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("getProviderByAddress call failed: %w", err)
+		return nil, fmt.Errorf("getProviderByAddress call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	var res getProviderByAddressOutput
@@ -425,7 +466,7 @@ func (c *Contract) GetProviderIDByAddress(ctx context.Context, addr common.Addre
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("getProviderIdByAddress call failed: %w", err)
+		return nil, fmt.Errorf("getProviderIdByAddress call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("getProviderIdByAddress", result)
@@ -447,7 +488,7 @@ type GetProviderWithProductResult struct {
 	ProductCapabilityValues [][]byte
 }
 
-func (c *Contract) GetProviderWithProduct(ctx context.Context, providerID *big.Int, productType uint8) (*GetProviderWithProductResult, error) {
+func (c *Contract) GetProviderWithProduct(ctx context.Context, providerID *big.Int, productType uint8, opts ...callopt.Option) (*GetProviderWithProductResult, error) {
 	data, err := c.abi.Pack("getProviderWithProduct", providerID, productType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack getProviderWithProduct call: %w", err)
@@ -456,9 +497,9 @@ func (c *Contract) GetProviderWithProduct(ctx context.Context, providerID *big.I
 	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
 		To:   &c.address,
 		Data: data,
-	}, nil)
+	}, callopt.Apply(opts...).BlockNumber)
 	if err != nil {
-		return nil, fmt.Errorf("getProviderWithProduct call failed: %w", err)
+		return nil, fmt.Errorf("getProviderWithProduct call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	var res getProviderWithProductOutput
@@ -512,7 +553,7 @@ func (c *Contract) GetAllActiveProviders(ctx context.Context, offset, limit *big
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, false, fmt.Errorf("getAllActiveProviders call failed: %w", err)
+		return nil, false, fmt.Errorf("getAllActiveProviders call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("getAllActiveProviders", result)
@@ -543,7 +584,7 @@ func (c *Contract) IsProviderActive(ctx context.Context, providerID *big.Int) (b
 		Data: data,
 	}, nil)
 	if err != nil {
-		return false, fmt.Errorf("isProviderActive call failed: %w", err)
+		return false, fmt.Errorf("isProviderActive call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("isProviderActive", result)
@@ -569,7 +610,7 @@ func (c *Contract) IsRegisteredProvider(ctx context.Context, addr common.Address
 		Data: data,
 	}, nil)
 	if err != nil {
-		return false, fmt.Errorf("isRegisteredProvider call failed: %w", err)
+		return false, fmt.Errorf("isRegisteredProvider call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("isRegisteredProvider", result)
@@ -595,7 +636,7 @@ func (c *Contract) GetProviderCount(ctx context.Context) (*big.Int, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("getProviderCount call failed: %w", err)
+		return nil, fmt.Errorf("getProviderCount call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("getProviderCount", result)
@@ -621,7 +662,7 @@ func (c *Contract) ActiveProviderCount(ctx context.Context) (*big.Int, error) {
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("activeProviderCount call failed: %w", err)
+		return nil, fmt.Errorf("activeProviderCount call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("activeProviderCount", result)
@@ -647,7 +688,7 @@ func (c *Contract) ProviderHasProduct(ctx context.Context, providerID *big.Int,
 		Data: data,
 	}, nil)
 	if err != nil {
-		return false, fmt.Errorf("providerHasProduct call failed: %w", err)
+		return false, fmt.Errorf("providerHasProduct call failed: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("providerHasProduct", result)
@@ -671,6 +712,47 @@ func (c *Contract) RegisterProvider(opts *bind.TransactOpts, payee common.Addres
 	return c.transact(opts, data)
 }
 
+// EstimateRegisterProviderGasCost returns the worst-case gas cost, gas limit
+// times fee cap, of a registerProvider call from sender paying fee.
+func (c *Contract) EstimateRegisterProviderGasCost(ctx context.Context, from common.Address, fee *big.Int, payee common.Address, name, description string, productType uint8, capabilityKeys []string, capabilityValues [][]byte) (*big.Int, error) {
+	data, err := c.abi.Pack("registerProvider", payee, name, description, productType, capabilityKeys, capabilityValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack registerProvider call: %w", err)
+	}
+
+	gasTipCap, gasFeeCap, err := c.gasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := c.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:      from,
+		To:        &c.address,
+		Value:     fee,
+		Data:      data,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	return new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasFeeCap), nil
+}
+
+// ParseProviderRegistered returns the provider ID from a ProviderRegistered
+// log emitted by this registry.
+func (c *Contract) ParseProviderRegistered(log types.Log) (*big.Int, error) {
+	event := c.abi.Events["ProviderRegistered"]
+	if log.Address != c.address {
+		return nil, fmt.Errorf("log emitted by %s, not registry %s", log.Address.Hex(), c.address.Hex())
+	}
+	if len(log.Topics) < 2 || log.Topics[0] != event.ID {
+		return nil, fmt.Errorf("log is not a ProviderRegistered event")
+	}
+	return new(big.Int).SetBytes(log.Topics[1].Bytes()), nil
+}
+
 func (c *Contract) UpdateProviderInfo(opts *bind.TransactOpts, name, description string) (*types.Transaction, error) {
 	data, err := c.abi.Pack("updateProviderInfo", name, description)
 	if err != nil {
@@ -716,35 +798,40 @@ func (c *Contract) RemoveProduct(opts *bind.TransactOpts, productType uint8) (*t
 	return c.transact(opts, data)
 }
 
-func (c *Contract) transact(opts *bind.TransactOpts, data []byte) (*types.Transaction, error) {
-	nonce, err := c.getNextNonce(opts.Context, opts.From)
+// gasFees returns the tip and fee cap used for registry transactions.
+func (c *Contract) gasFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	gasTipCap, err := c.client.SuggestGasTipCap(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, nil, fmt.Errorf("failed to get gas tip cap: %w", err)
 	}
 
-	chainID, err := c.client.ChainID(opts.Context)
+	header, err := c.client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+		return nil, nil, fmt.Errorf("failed to get latest block header: %w", err)
 	}
 
-	gasTipCap, err := c.client.SuggestGasTipCap(opts.Context)
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = big.NewInt(0)
+	}
+	return gasTipCap, computeGasFeeCap(baseFee, gasTipCap, c.feeMultiplier()), nil
+}
+
+func (c *Contract) transact(opts *bind.TransactOpts, data []byte) (*types.Transaction, error) {
+	nonce, err := c.getNextNonce(opts.Context, opts.From)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas tip cap: %w", err)
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
 	}
 
-	header, err := c.client.HeaderByNumber(opts.Context, nil)
+	chainID, err := c.client.ChainID(opts.Context)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest block header: %w", err)
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	baseFee := header.BaseFee
-	if baseFee == nil {
-		baseFee = big.NewInt(0)
+	gasTipCap, gasFeeCap, err := c.gasFees(opts.Context)
+	if err != nil {
+		return nil, err
 	}
-	gasFeeCap := new(big.Int).Add(
-		new(big.Int).Mul(baseFee, big.NewInt(2)),
-		gasTipCap,
-	)
 
 	value := opts.Value
 	if value == nil {
@@ -806,3 +893,14 @@ func (c *Contract) getNextNonce(ctx context.Context, from common.Address) (uint6
 	c.nonce++
 	return nonce, nil
 }
+
+// computeGasFeeCap returns baseFee*multiplier + tip, rounding the scaled base
+// fee up so a fractional multiplier never undershoots.
+func computeGasFeeCap(baseFee, tip *big.Int, multiplier float64) *big.Int {
+	scaled := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(multiplier))
+	feeCap, acc := scaled.Int(nil)
+	if acc == big.Below {
+		feeCap.Add(feeCap, big.NewInt(1))
+	}
+	return feeCap.Add(feeCap, tip)
+}
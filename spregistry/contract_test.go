@@ -3,11 +3,15 @@ package spregistry
 import (
 	"math/big"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/data-preservation-programs/go-synapse/contracts"
 	"github.com/data-preservation-programs/go-synapse/pkg/abix"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // TestUnpackSingleTuple_GetProviderByAddress exercises the unpack path
@@ -159,3 +163,126 @@ func TestUnpackSingleTuple_GetProviderWithProduct(t *testing.T) {
 		t.Errorf("ProductCapabilityValues[0] = %q, want %q", string(got.ProductCapabilityValues[0]), string(want.ProductCapabilityValues[0]))
 	}
 }
+
+func TestParseProviderRegistered(t *testing.T) {
+	registry := common.HexToAddress("0x839e5c9988e4e9977d40708d0094103c0839Ac9D")
+	c, err := NewContract(registry, nil)
+	if err != nil {
+		t.Fatalf("NewContract: %v", err)
+	}
+
+	sig := crypto.Keccak256Hash([]byte("ProviderRegistered(uint256,address,address)"))
+	sp := common.HexToAddress("0xE3e842B9D89ed2Ee3976b9b8916827302618c29e")
+	log := types.Log{
+		Address: registry,
+		Topics: []common.Hash{
+			sig,
+			common.BigToHash(big.NewInt(42)),
+			common.BytesToHash(sp.Bytes()),
+			common.BytesToHash(sp.Bytes()),
+		},
+	}
+
+	id, err := c.ParseProviderRegistered(log)
+	if err != nil {
+		t.Fatalf("ParseProviderRegistered: %v", err)
+	}
+	if id.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("provider ID = %v, want 42", id)
+	}
+
+	other := log
+	other.Topics = append([]common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))}, log.Topics[1:]...)
+	if _, err := c.ParseProviderRegistered(other); err == nil {
+		t.Error("expected error for unrelated event")
+	}
+
+	foreign := log
+	foreign.Address = sp
+	if _, err := c.ParseProviderRegistered(foreign); err == nil {
+		t.Error("expected error for log from another contract")
+	}
+}
+
+func TestComputeGasFeeCap(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseFee    int64
+		tip        int64
+		multiplier float64
+		want       int64
+	}{
+		{"default", 100, 7, DefaultBaseFeeMultiplier, 207},
+		{"congested", 100, 7, 3, 307},
+		{"fractional rounds up", 101, 0, 1.5, 152},
+		{"zero base fee", 0, 5, 2, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeGasFeeCap(big.NewInt(tt.baseFee), big.NewInt(tt.tip), tt.multiplier)
+			if got.Int64() != tt.want {
+				t.Errorf("computeGasFeeCap() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetBaseFeeMultiplier(t *testing.T) {
+	c, err := NewContract(common.Address{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.feeMultiplier(); got != DefaultBaseFeeMultiplier {
+		t.Fatalf("default multiplier = %v, want %v", got, DefaultBaseFeeMultiplier)
+	}
+	if err := c.SetBaseFeeMultiplier(0.5); err == nil {
+		t.Error("expected error for multiplier below 1")
+	}
+	if err := c.SetBaseFeeMultiplier(3); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.feeMultiplier(); got != 3 {
+		t.Errorf("multiplier = %v, want 3", got)
+	}
+
+	// the setter may race with fee computation for in-flight transactions
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if i%2 == 0 {
+					_ = c.SetBaseFeeMultiplier(float64(1 + j%3))
+				} else if m := c.feeMultiplier(); m < 1 {
+					t.Errorf("multiplier = %v, want >= 1", m)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRegistryABIDecodesCustomErrors(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(SPRegistryABIJSON))
+	if err != nil {
+		t.Fatalf("parse ABI: %v", err)
+	}
+	abiErr, ok := parsedABI.Errors["OwnableUnauthorizedAccount"]
+	if !ok {
+		t.Fatal("OwnableUnauthorizedAccount not found in ABI")
+	}
+	account := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	args, err := abiErr.Inputs.Pack(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reason, err := contracts.DecodeError(parsedABI, append(abiErr.ID[:4:4], args...))
+	if err != nil {
+		t.Fatalf("DecodeError() error = %v", err)
+	}
+	if want := "OwnableUnauthorizedAccount(account=" + account.Hex() + ")"; reason != want {
+		t.Errorf("DecodeError() = %q, want %q", reason, want)
+	}
+}
@@ -0,0 +1,205 @@
+package spregistry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CapabilityChange describes one capability key whose encoded value differs
+// between the on-chain product and a proposed offering. Old is nil for keys
+// being added and New is nil for keys that would be removed.
+type CapabilityChange struct {
+	Key string
+	Old []byte
+	New []byte
+}
+
+// OfferingDiff is the result of comparing a proposed PDP offering against
+// the provider's current on-chain product.
+type OfferingDiff struct {
+	ProviderID int
+	Changes    []CapabilityChange
+}
+
+// HasChanges reports whether updating the product would change anything.
+func (d *OfferingDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// DiffPDPOffering fetches the provider's current PDP product and reports the
+// capabilities that differ from newOffering. Since updateProduct replaces the
+// whole capability set, keys present on-chain but absent from newOffering are
+// reported as removals.
+func (s *Service) DiffPDPOffering(ctx context.Context, providerID int, newOffering PDPOffering) (*OfferingDiff, error) {
+	return s.diffPDPOffering(ctx, providerID, newOffering, nil)
+}
+
+// UpdatePDPProductIfChanged is like UpdatePDPProduct but skips the transaction
+// when the encoded capabilities already match what is on-chain. The returned
+// hash is zero when no transaction was sent.
+func (s *Service) UpdatePDPProductIfChanged(ctx context.Context, offering PDPOffering, capabilities map[string]string) (common.Hash, *OfferingDiff, error) {
+	if s.privateKey == nil {
+		return common.Hash{}, nil, fmt.Errorf("private key required for write operations")
+	}
+
+	providerID, err := s.GetProviderIDByAddress(ctx, s.address)
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("failed to look up provider ID: %w", err)
+	}
+	if providerID == 0 {
+		return common.Hash{}, nil, fmt.Errorf("address %s is not a registered provider", s.address.Hex())
+	}
+
+	diff, err := s.diffPDPOffering(ctx, providerID, offering, capabilities)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	if !diff.HasChanges() {
+		return common.Hash{}, diff, nil
+	}
+
+	hash, err := s.UpdatePDPProduct(ctx, offering, capabilities)
+	if err != nil {
+		return common.Hash{}, diff, err
+	}
+	return hash, diff, nil
+}
+
+// SetCapability changes a single capability of the caller's product, leaving
+// every other key as it is on-chain. A nil value removes the key. The registry
+// only supports replacing a product's whole capability set, so this reads the
+// current set, applies the change and submits it with updateProduct. The
+// returned hash is zero when the value is already set and nothing was sent.
+func (s *Service) SetCapability(ctx context.Context, productType ProductType, key string, value []byte) (common.Hash, error) {
+	if s.privateKey == nil {
+		return common.Hash{}, fmt.Errorf("private key required for write operations")
+	}
+	if value != nil {
+		if err := ValidateCapabilityValue(key, value); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	providerID, err := s.GetProviderIDByAddress(ctx, s.address)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to look up provider ID: %w", err)
+	}
+	if providerID == 0 {
+		return common.Hash{}, fmt.Errorf("address %s is not a registered provider", s.address.Hex())
+	}
+
+	hasProduct, err := s.ProviderHasProduct(ctx, providerID, productType)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to check product: %w", err)
+	}
+	if !hasProduct {
+		return common.Hash{}, fmt.Errorf("provider %d has no product of type %d", providerID, productType)
+	}
+
+	result, err := s.contract.GetProviderWithProduct(ctx, big.NewInt(int64(providerID)), uint8(productType))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get current product: %w", err)
+	}
+
+	keys, values, changed := setCapability(result.Product.CapabilityKeys, result.ProductCapabilityValues, key, value)
+	if !changed {
+		return common.Hash{}, nil
+	}
+
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	tx, err := s.contract.UpdateProduct(opts, uint8(productType), keys, values)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return tx.Hash(), nil
+}
+
+// setCapability returns copies of keys and values with key set to value (or
+// removed if value is nil), keeping the existing key order and appending new
+// keys at the end.
+func setCapability(keys []string, values [][]byte, key string, value []byte) ([]string, [][]byte, bool) {
+	newKeys := make([]string, 0, len(keys)+1)
+	newValues := make([][]byte, 0, len(keys)+1)
+	found, changed := false, false
+
+	for i := 0; i < len(keys) && i < len(values); i++ {
+		if keys[i] != key {
+			newKeys = append(newKeys, keys[i])
+			newValues = append(newValues, values[i])
+			continue
+		}
+		found = true
+		if value == nil {
+			changed = true
+			continue
+		}
+		if !bytes.Equal(values[i], value) {
+			changed = true
+		}
+		newKeys = append(newKeys, key)
+		newValues = append(newValues, value)
+	}
+
+	if !found && value != nil {
+		newKeys = append(newKeys, key)
+		newValues = append(newValues, value)
+		changed = true
+	}
+
+	return newKeys, newValues, changed
+}
+
+func (s *Service) diffPDPOffering(ctx context.Context, providerID int, offering PDPOffering, capabilities map[string]string) (*OfferingDiff, error) {
+	current, err := s.GetPDPService(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current PDP offering: %w", err)
+	}
+	if current == nil {
+		return nil, fmt.Errorf("provider %d has no active PDP product", providerID)
+	}
+
+	keys, values, err := EncodePDPCapabilities(&offering, capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+
+	return &OfferingDiff{
+		ProviderID: providerID,
+		Changes:    diffCapabilities(current.Capabilities, CapabilitiesListToMap(keys, values)),
+	}, nil
+}
+
+// diffCapabilities compares two encoded capability maps and returns the
+// changes sorted by key.
+func diffCapabilities(current, proposed map[string][]byte) []CapabilityChange {
+	var changes []CapabilityChange
+	for key, newValue := range proposed {
+		oldValue, ok := current[key]
+		if !ok {
+			changes = append(changes, CapabilityChange{Key: key, New: newValue})
+			continue
+		}
+		if !bytes.Equal(oldValue, newValue) {
+			changes = append(changes, CapabilityChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	for key, oldValue := range current {
+		if _, ok := proposed[key]; !ok {
+			changes = append(changes, CapabilityChange{Key: key, Old: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
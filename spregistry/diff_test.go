@@ -0,0 +1,106 @@
+package spregistry
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDiffCapabilities(t *testing.T) {
+	offering := PDPOffering{
+		ServiceURL:               "https://provider.example.com",
+		MinPieceSizeInBytes:      big.NewInt(1024),
+		MaxPieceSizeInBytes:      big.NewInt(1073741824),
+		StoragePricePerTiBPerDay: big.NewInt(1000000),
+		MinProvingPeriodInEpochs: big.NewInt(2880),
+		Location:                 "US-EAST",
+		PaymentTokenAddress:      common.HexToAddress("0xb3042734b608a1B16e9e86B374A3f3e389B4cDf0"),
+	}
+	keys, values, err := EncodePDPCapabilities(&offering, map[string]string{"region": "us"})
+	if err != nil {
+		t.Fatalf("EncodePDPCapabilities failed: %v", err)
+	}
+	current := CapabilitiesListToMap(keys, values)
+
+	t.Run("unchanged", func(t *testing.T) {
+		keys, values, _ := EncodePDPCapabilities(&offering, map[string]string{"region": "us"})
+		if changes := diffCapabilities(current, CapabilitiesListToMap(keys, values)); len(changes) != 0 {
+			t.Errorf("diffCapabilities() = %v, want no changes", changes)
+		}
+	})
+
+	t.Run("price change, flag added, extra removed", func(t *testing.T) {
+		updated := offering
+		updated.StoragePricePerTiBPerDay = big.NewInt(2000000)
+		updated.IPNIPiece = true
+		keys, values, _ := EncodePDPCapabilities(&updated, nil)
+
+		changes := diffCapabilities(current, CapabilitiesListToMap(keys, values))
+		if len(changes) != 3 {
+			t.Fatalf("len(changes) = %d, want 3: %v", len(changes), changes)
+		}
+
+		// sorted by key
+		if changes[0].Key != CapIPNIPiece || changes[0].Old != nil {
+			t.Errorf("changes[0] = %+v, want added %s", changes[0], CapIPNIPiece)
+		}
+		if changes[1].Key != "region" || changes[1].New != nil {
+			t.Errorf("changes[1] = %+v, want removed region", changes[1])
+		}
+		if changes[2].Key != CapStoragePrice {
+			t.Errorf("changes[2].Key = %s, want %s", changes[2].Key, CapStoragePrice)
+		}
+		if got := new(big.Int).SetBytes(changes[2].New); got.Cmp(big.NewInt(2000000)) != 0 {
+			t.Errorf("changes[2].New = %s, want 2000000", got)
+		}
+	})
+}
+
+func TestSetCapability(t *testing.T) {
+	keys := []string{CapServiceURL, CapStoragePrice, CapLocation}
+	values := [][]byte{[]byte("https://sp"), {0x05}, []byte("eu")}
+
+	tests := []struct {
+		name        string
+		key         string
+		value       []byte
+		wantKeys    []string
+		wantChanged bool
+	}{
+		{"update in place", CapStoragePrice, []byte{0x06}, keys, true},
+		{"unchanged", CapStoragePrice, []byte{0x05}, keys, false},
+		{"add", CapIPNIPiece, []byte{0x01}, append(append([]string{}, keys...), CapIPNIPiece), true},
+		{"remove", CapStoragePrice, nil, []string{CapServiceURL, CapLocation}, true},
+		{"remove missing", CapIPNIPiece, nil, keys, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKeys, gotValues, changed := setCapability(keys, values, tt.key, tt.value)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if len(gotKeys) != len(tt.wantKeys) || len(gotValues) != len(gotKeys) {
+				t.Fatalf("keys = %v (%d values), want %v", gotKeys, len(gotValues), tt.wantKeys)
+			}
+			for i := range gotKeys {
+				if gotKeys[i] != tt.wantKeys[i] {
+					t.Errorf("keys = %v, want %v", gotKeys, tt.wantKeys)
+					break
+				}
+			}
+			if tt.value != nil {
+				got := CapabilitiesListToMap(gotKeys, gotValues)[tt.key]
+				if string(got) != string(tt.value) {
+					t.Errorf("%s = %x, want %x", tt.key, got, tt.value)
+				}
+			}
+		})
+	}
+
+	// the input slices must not be modified
+	if string(values[1]) != string([]byte{0x05}) || len(keys) != 3 {
+		t.Error("setCapability modified its input")
+	}
+}
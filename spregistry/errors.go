@@ -0,0 +1,44 @@
+package spregistry
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrInsufficientFunds means the wallet cannot cover the registration
+	// fee plus gas.
+	ErrInsufficientFunds = errors.New("insufficient FIL balance")
+	// ErrRegistrationFeeTooHigh means the registry's current fee exceeds the
+	// MaxFee the caller agreed to pay.
+	ErrRegistrationFeeTooHigh = errors.New("registration fee exceeds max fee")
+	// ErrInvalidProviderRef means a provider reference passed to Resolve is
+	// neither a decimal provider ID nor a 0x address.
+	ErrInvalidProviderRef = errors.New("invalid provider reference")
+	// ErrIntOverflow means a provider ID or count read from the registry is
+	// too large for int on this platform.
+	ErrIntOverflow = errors.New("value overflows int")
+)
+
+// AmountError carries the amounts behind ErrInsufficientFunds or
+// ErrRegistrationFeeTooHigh, in attoFIL. It matches the sentinel with
+// errors.Is.
+type AmountError struct {
+	Err  error
+	Have *big.Int
+	Want *big.Int
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("%v: have %s, want %s", e.Err, e.Have, e.Want)
+}
+
+func (e *AmountError) Unwrap() error {
+	return e.Err
+}
+
+// Shortfall returns Want - Have.
+func (e *AmountError) Shortfall() *big.Int {
+	return new(big.Int).Sub(e.Want, e.Have)
+}
@@ -0,0 +1,36 @@
+package spregistry
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestAmountError(t *testing.T) {
+	err := fmt.Errorf("registration preflight: %w", &AmountError{
+		Err:  ErrInsufficientFunds,
+		Have: big.NewInt(3),
+		Want: big.NewInt(5),
+	})
+
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Error("errors.Is(err, ErrInsufficientFunds) = false, want true")
+	}
+	if errors.Is(err, ErrRegistrationFeeTooHigh) {
+		t.Error("errors.Is(err, ErrRegistrationFeeTooHigh) = true, want false")
+	}
+
+	var amountErr *AmountError
+	if !errors.As(err, &amountErr) {
+		t.Fatal("errors.As(err, *AmountError) = false, want true")
+	}
+	if got := amountErr.Shortfall(); got.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("Shortfall() = %s, want 2", got)
+	}
+
+	want := "registration preflight: insufficient FIL balance: have 3, want 5"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
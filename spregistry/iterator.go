@@ -0,0 +1,86 @@
+package spregistry
+
+import (
+	"context"
+	"math/big"
+)
+
+// ProviderIterator streams the registry's active providers, fetching one
+// page of provider IDs at a time. Providers are visited in registry order;
+// because positions are offsets into the active set, providers activated or
+// deactivated during a long walk can be skipped or seen twice.
+type ProviderIterator struct {
+	s   *Service
+	ctx context.Context
+
+	offset  uint64     // position of page[0] in the active set
+	page    []*big.Int // current page of provider IDs
+	next    int        // index in page of the next provider to return
+	hasMore bool       // whether pages follow the current one
+	fetched bool       // whether page has been loaded
+}
+
+// ActiveProvidersIterator returns an iterator over all active providers,
+// starting from the first. Unlike GetAllActiveProviders it loads nothing up
+// front, and lookup errors are returned rather than skipped.
+func (s *Service) ActiveProvidersIterator(ctx context.Context) *ProviderIterator {
+	return s.ActiveProvidersIteratorFrom(ctx, 0)
+}
+
+// ActiveProvidersIteratorFrom resumes iteration at a cursor previously
+// returned by ProviderIterator.Cursor.
+func (s *Service) ActiveProvidersIteratorFrom(ctx context.Context, cursor uint64) *ProviderIterator {
+	return &ProviderIterator{s: s, ctx: ctx, offset: cursor}
+}
+
+// Next returns the next active provider and true, or false once the registry
+// is exhausted. On error the iterator does not advance, so calling Next again
+// retries the same provider.
+func (it *ProviderIterator) Next() (*ProviderInfo, bool, error) {
+	for {
+		if err := it.ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		if !it.fetched || (it.next == len(it.page) && it.hasMore) {
+			if err := it.fetchPage(); err != nil {
+				return nil, false, err
+			}
+		}
+		if it.next == len(it.page) {
+			return nil, false, nil
+		}
+
+		id, err := toInt(it.page[it.next], "provider ID")
+		if err != nil {
+			return nil, false, err
+		}
+		provider, err := it.s.GetProvider(it.ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		it.next++
+		if provider != nil {
+			return provider, true, nil
+		}
+	}
+}
+
+// Cursor returns the position of the next provider Next would return. Pass it
+// to ActiveProvidersIteratorFrom to resume from there.
+func (it *ProviderIterator) Cursor() uint64 {
+	return it.offset + uint64(it.next)
+}
+
+// fetchPage loads the page starting at the current cursor. An empty page ends
+// iteration even if the registry reports more, so a misbehaving registry
+// cannot make Next refetch the same cursor forever.
+func (it *ProviderIterator) fetchPage() error {
+	offset := it.Cursor()
+	ids, hasMore, err := it.s.contract.GetAllActiveProviders(it.ctx, new(big.Int).SetUint64(offset), big.NewInt(int64(it.s.pageSize)))
+	if err != nil {
+		return err
+	}
+	it.offset, it.page, it.next, it.hasMore, it.fetched = offset, ids, 0, hasMore && len(ids) > 0, true
+	return nil
+}
@@ -0,0 +1,240 @@
+package spregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// JSON encoding of capability values. Well-known keys are emitted in their
+// decoded form: strings as-is, uints as decimal strings, bools as JSON bools
+// and addresses as checksummed hex. Unknown keys, and well-known keys whose
+// stored value is not in canonical form (e.g. a uint with leading zero
+// bytes), are emitted as 0x-prefixed hex of the raw bytes so they survive a
+// round trip. String values that are not valid UTF-8 are the one exception
+// and do not round-trip.
+
+type pdpOfferingJSON struct {
+	ServiceURL               string         `json:"serviceURL"`
+	MinPieceSizeInBytes      string         `json:"minPieceSizeInBytes,omitempty"`
+	MaxPieceSizeInBytes      string         `json:"maxPieceSizeInBytes,omitempty"`
+	IPNIPiece                bool           `json:"ipniPiece"`
+	IPNIIPFS                 bool           `json:"ipniIpfs"`
+	StoragePricePerTiBPerDay string         `json:"storagePricePerTibPerDay,omitempty"`
+	MinProvingPeriodInEpochs string         `json:"minProvingPeriodInEpochs,omitempty"`
+	Location                 string         `json:"location"`
+	PaymentTokenAddress      common.Address `json:"paymentTokenAddress"`
+}
+
+func (o PDPOffering) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pdpOfferingJSON{
+		ServiceURL:               o.ServiceURL,
+		MinPieceSizeInBytes:      bigToDecimal(o.MinPieceSizeInBytes),
+		MaxPieceSizeInBytes:      bigToDecimal(o.MaxPieceSizeInBytes),
+		IPNIPiece:                o.IPNIPiece,
+		IPNIIPFS:                 o.IPNIIPFS,
+		StoragePricePerTiBPerDay: bigToDecimal(o.StoragePricePerTiBPerDay),
+		MinProvingPeriodInEpochs: bigToDecimal(o.MinProvingPeriodInEpochs),
+		Location:                 o.Location,
+		PaymentTokenAddress:      o.PaymentTokenAddress,
+	})
+}
+
+func (o *PDPOffering) UnmarshalJSON(data []byte) error {
+	var raw pdpOfferingJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	out := PDPOffering{
+		ServiceURL:          raw.ServiceURL,
+		IPNIPiece:           raw.IPNIPiece,
+		IPNIIPFS:            raw.IPNIIPFS,
+		Location:            raw.Location,
+		PaymentTokenAddress: raw.PaymentTokenAddress,
+	}
+	var err error
+	if out.MinPieceSizeInBytes, err = decimalToBig(CapMinPieceSize, raw.MinPieceSizeInBytes); err != nil {
+		return err
+	}
+	if out.MaxPieceSizeInBytes, err = decimalToBig(CapMaxPieceSize, raw.MaxPieceSizeInBytes); err != nil {
+		return err
+	}
+	if out.StoragePricePerTiBPerDay, err = decimalToBig(CapStoragePrice, raw.StoragePricePerTiBPerDay); err != nil {
+		return err
+	}
+	if out.MinProvingPeriodInEpochs, err = decimalToBig(CapMinProvingPeriod, raw.MinProvingPeriodInEpochs); err != nil {
+		return err
+	}
+
+	*o = out
+	return nil
+}
+
+type serviceProductJSON struct {
+	Type         string                     `json:"type"`
+	IsActive     bool                       `json:"isActive"`
+	Capabilities map[string]json.RawMessage `json:"capabilities"`
+	Data         *PDPOffering               `json:"data,omitempty"`
+}
+
+func (p ServiceProduct) MarshalJSON() ([]byte, error) {
+	caps := make(map[string]json.RawMessage, len(p.Capabilities))
+	for key, v := range p.Capabilities {
+		encoded, err := marshalCapabilityValue(key, v)
+		if err != nil {
+			return nil, err
+		}
+		caps[key] = encoded
+	}
+
+	return json.Marshal(serviceProductJSON{
+		Type:         p.Type,
+		IsActive:     p.IsActive,
+		Capabilities: caps,
+		Data:         p.Data,
+	})
+}
+
+func (p *ServiceProduct) UnmarshalJSON(data []byte) error {
+	var raw serviceProductJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var caps map[string][]byte
+	if raw.Capabilities != nil {
+		caps = make(map[string][]byte, len(raw.Capabilities))
+		for key, v := range raw.Capabilities {
+			decoded, err := unmarshalCapabilityValue(key, v)
+			if err != nil {
+				return err
+			}
+			caps[key] = decoded
+		}
+	}
+
+	*p = ServiceProduct{
+		Type:         raw.Type,
+		IsActive:     raw.IsActive,
+		Capabilities: caps,
+		Data:         raw.Data,
+	}
+	return nil
+}
+
+type providerInfoJSON struct {
+	ID              int                        `json:"id"`
+	ServiceProvider common.Address             `json:"serviceProvider"`
+	Payee           common.Address             `json:"payee"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description"`
+	Active          bool                       `json:"active"`
+	Products        map[string]*ServiceProduct `json:"products"`
+}
+
+func (p ProviderInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(providerInfoJSON(p))
+}
+
+func (p *ProviderInfo) UnmarshalJSON(data []byte) error {
+	var raw providerInfoJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = ProviderInfo(raw)
+	return nil
+}
+
+// marshalCapabilityValue renders v in its decoded form when re-encoding that
+// form reproduces v exactly, and as raw hex otherwise.
+func marshalCapabilityValue(key string, v []byte) (json.RawMessage, error) {
+	var decoded any
+	switch CapabilityTypeOf(key) {
+	case CapTypeString:
+		decoded = string(v)
+	case CapTypeUint:
+		if n, err := DecodeCapabilityUint(v); err == nil && bytes.Equal(bigIntToBytes(n), v) {
+			decoded = n.String()
+		}
+	case CapTypeBool:
+		if bytes.Equal(v, []byte{0x01}) {
+			decoded = true
+		} else if bytes.Equal(v, []byte{0x00}) {
+			decoded = false
+		}
+	case CapTypeAddress:
+		if len(v) == common.AddressLength {
+			decoded = common.BytesToAddress(v).Hex()
+		}
+	}
+	if decoded == nil {
+		decoded = hexutil.Encode(v)
+	}
+
+	out, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capability %q: %w", key, err)
+	}
+	return out, nil
+}
+
+// unmarshalCapabilityValue is the inverse of marshalCapabilityValue.
+func unmarshalCapabilityValue(key string, data json.RawMessage) ([]byte, error) {
+	t := CapabilityTypeOf(key)
+
+	if t == CapTypeBool {
+		var b bool
+		if err := json.Unmarshal(data, &b); err == nil {
+			if b {
+				return []byte{0x01}, nil
+			}
+			return []byte{0x00}, nil
+		}
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("capability %q: expected %s value: %w", key, t, err)
+	}
+
+	switch {
+	case t == CapTypeString:
+		return []byte(s), nil
+	case t == CapTypeUint && !strings.HasPrefix(s, "0x"):
+		n, err := decimalToBig(key, s)
+		if err != nil {
+			return nil, err
+		}
+		return bigIntToBytes(n), nil
+	}
+
+	v, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("capability %q: invalid hex value: %w", key, err)
+	}
+	return v, nil
+}
+
+func bigToDecimal(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
+
+func decimalToBig(field, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("%s: %q is not a decimal integer", field, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package spregistry
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProviderInfoJSONRoundTrip(t *testing.T) {
+	token := common.HexToAddress("0xb3042734b608a1B16e9e86B374A3f3e389B4cDf0")
+	offering := PDPOffering{
+		ServiceURL:               "https://pdp.example.com",
+		MinPieceSizeInBytes:      big.NewInt(1024),
+		MaxPieceSizeInBytes:      big.NewInt(32 << 30),
+		IPNIPiece:                true,
+		StoragePricePerTiBPerDay: new(big.Int).Lsh(big.NewInt(1), 80),
+		MinProvingPeriodInEpochs: big.NewInt(30),
+		Location:                 "eu-west",
+		PaymentTokenAddress:      token,
+	}
+	keys, values, err := EncodePDPCapabilities(&offering, map[string]string{"custom": "0xdead"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caps := CapabilitiesListToMap(keys, values)
+	// non-canonical encodings must survive as raw bytes
+	caps[CapIPNIIPFS] = []byte{0x02}
+	caps[CapMinProvingPeriod] = []byte{0x00, 0x1e}
+
+	info := ProviderInfo{
+		ID:              7,
+		ServiceProvider: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Payee:           common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Name:            "sp",
+		Active:          true,
+		Products: map[string]*ServiceProduct{
+			"PDP": {Type: "PDP", IsActive: true, Capabilities: caps, Data: &offering},
+		},
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`"minPieceSizeInBytes":"1024"`,
+		`"storagePricePerTibPerDay":"1208925819614629174706176"`,
+		`"ipniPiece":true`,
+		`"paymentTokenAddress":"` + token.Hex() + `"`,
+		`"ipniIpfs":"0x02"`,
+		`"minProvingPeriodInEpochs":"0x001e"`,
+		`"custom":"0xdead"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON missing %s:\n%s", want, data)
+		}
+	}
+
+	var got ProviderInfo
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got.Products["PDP"], info.Products["PDP"])
+	}
+}
+
+func TestUnmarshalCapabilityValueErrors(t *testing.T) {
+	tests := []struct {
+		key  string
+		data string
+	}{
+		{CapMinPieceSize, `"12x"`},
+		{CapMinPieceSize, `true`},
+		{CapPaymentToken, `"0xzz"`},
+		{"custom", `"not hex"`},
+	}
+	for _, tt := range tests {
+		if _, err := unmarshalCapabilityValue(tt.key, json.RawMessage(tt.data)); err == nil {
+			t.Errorf("unmarshalCapabilityValue(%q, %s) expected error", tt.key, tt.data)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package spregistry
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// WithEndpointCheck makes CanStorePiece call ping with the provider's service
+// URL and reject providers for which it fails, e.g. with pdp.PingURL. Without
+// it, or with a nil ping, only on-chain data is checked.
+func WithEndpointCheck(ping func(ctx context.Context, serviceURL string) error) ServiceOption {
+	return func(s *Service) error {
+		s.pingEndpoint = ping
+		return nil
+	}
+}
+
+// CanStorePiece is a preflight check before uploading a piece of pieceSize
+// bytes to a provider. It verifies that the provider and its PDP offering are
+// active, that the size is within the offering's
+// [MinPieceSizeInBytes, MaxPieceSizeInBytes], and, if enabled with
+// WithEndpointCheck, that the service URL answers a ping. When the provider is
+// unsuitable it returns false and a human-readable reason; the error is
+// reserved for failures to perform the check itself.
+func (s *Service) CanStorePiece(ctx context.Context, providerID int, pieceSize int64) (bool, string, error) {
+	result, err := s.contract.GetProviderWithProduct(ctx, big.NewInt(int64(providerID)), uint8(ProductTypePDP))
+	if err != nil {
+		return false, "", err
+	}
+	if !result.ProviderInfo.IsActive {
+		return false, fmt.Sprintf("provider %d is not active", providerID), nil
+	}
+	if !result.Product.IsActive {
+		return false, fmt.Sprintf("provider %d has no active PDP offering", providerID), nil
+	}
+
+	offering := DecodePDPCapabilities(CapabilitiesListToMap(result.Product.CapabilityKeys, result.ProductCapabilityValues))
+	if reason := pieceSizeMismatch(offering, pieceSize); reason != "" {
+		return false, reason, nil
+	}
+
+	if s.pingEndpoint != nil {
+		if offering.ServiceURL == "" {
+			return false, fmt.Sprintf("provider %d advertises no service URL", providerID), nil
+		}
+		if err := s.pingEndpoint(ctx, offering.ServiceURL); err != nil {
+			if ctx.Err() != nil {
+				return false, "", ctx.Err()
+			}
+			return false, fmt.Sprintf("service URL %s is unreachable: %v", offering.ServiceURL, err), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// pieceSizeMismatch returns why pieceSize falls outside the offering's
+// bounds, or "" if it fits. Unset bounds are not enforced.
+func pieceSizeMismatch(offering *PDPOffering, pieceSize int64) string {
+	if pieceSize <= 0 {
+		return fmt.Sprintf("invalid piece size %d", pieceSize)
+	}
+	size := big.NewInt(pieceSize)
+	if offering.MinPieceSizeInBytes != nil && size.Cmp(offering.MinPieceSizeInBytes) < 0 {
+		return fmt.Sprintf("piece size %d is below the provider minimum of %s bytes", pieceSize, offering.MinPieceSizeInBytes)
+	}
+	if offering.MaxPieceSizeInBytes != nil && offering.MaxPieceSizeInBytes.Sign() > 0 && size.Cmp(offering.MaxPieceSizeInBytes) > 0 {
+		return fmt.Sprintf("piece size %d exceeds the provider maximum of %s bytes", pieceSize, offering.MaxPieceSizeInBytes)
+	}
+	return ""
+}
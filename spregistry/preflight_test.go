@@ -0,0 +1,75 @@
+package spregistry
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestPieceSizeMismatch(t *testing.T) {
+	offering := &PDPOffering{
+		MinPieceSizeInBytes: big.NewInt(127),
+		MaxPieceSizeInBytes: big.NewInt(1 << 20),
+	}
+
+	tests := []struct {
+		name     string
+		offering *PDPOffering
+		size     int64
+		fits     bool
+	}{
+		{"within bounds", offering, 4096, true},
+		{"at minimum", offering, 127, true},
+		{"at maximum", offering, 1 << 20, true},
+		{"below minimum", offering, 100, false},
+		{"above maximum", offering, 1<<20 + 1, false},
+		{"zero size", offering, 0, false},
+		{"unset bounds", &PDPOffering{}, 1 << 40, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := pieceSizeMismatch(tt.offering, tt.size)
+			if (reason == "") != tt.fits {
+				t.Errorf("pieceSizeMismatch(%d) = %q, want fits=%v", tt.size, reason, tt.fits)
+			}
+		})
+	}
+}
+
+func TestWithEndpointCheck(t *testing.T) {
+	api := newTestRegistryAPI()
+	api.pdp[3] = true
+	svc := newRegistryTestService(t, api)
+	ctx := context.Background()
+
+	if svc.pingEndpoint != nil {
+		t.Error("endpoint check should be disabled by default")
+	}
+	if ok, reason, err := svc.CanStorePiece(ctx, 3, 4096); !ok || err != nil {
+		t.Fatalf("CanStorePiece() without check = %v, %q, %v", ok, reason, err)
+	}
+
+	var pinged string
+	pingErr := errors.New("connection refused")
+	if err := WithEndpointCheck(func(ctx context.Context, serviceURL string) error {
+		pinged = serviceURL
+		return pingErr
+	})(svc); err != nil {
+		t.Fatal(err)
+	}
+	ok, reason, err := svc.CanStorePiece(ctx, 3, 4096)
+	if ok || err != nil || !strings.Contains(reason, "unreachable") {
+		t.Errorf("CanStorePiece() with failing ping = %v, %q, %v", ok, reason, err)
+	}
+	if pinged != "https://pdp.example.com" {
+		t.Errorf("pinged %q, want the provider's service URL", pinged)
+	}
+
+	pingErr = nil
+	if ok, reason, err := svc.CanStorePiece(ctx, 3, 4096); !ok || err != nil {
+		t.Errorf("CanStorePiece() with passing ping = %v, %q, %v", ok, reason, err)
+	}
+}
@@ -3,24 +3,67 @@ package spregistry
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
+	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+const defaultReceiptTimeout = 90 * time.Second
+
+// DefaultPageSize is the number of provider IDs requested per
+// getAllActiveProviders call.
+const DefaultPageSize = 50
+
 type Service struct {
 	client     *ethclient.Client
 	contract   *Contract
 	privateKey *ecdsa.PrivateKey
 	address    common.Address
 	chainID    *big.Int
+	pageSize   int
+	transactor *txutil.BaseTransactor
+
+	pingEndpoint func(ctx context.Context, serviceURL string) error
+}
+
+type ServiceOption func(*Service) error
+
+// WithBaseFeeMultiplier sets the factor applied to the latest base fee when
+// computing the fee cap of registry transactions. Defaults to
+// DefaultBaseFeeMultiplier.
+func WithBaseFeeMultiplier(multiplier float64) ServiceOption {
+	return func(s *Service) error {
+		return s.contract.SetBaseFeeMultiplier(multiplier)
+	}
+}
+
+// WithPageSize sets how many provider IDs GetAllActiveProviders requests per
+// call. Larger pages mean fewer round trips; smaller ones keep each eth_call
+// under provider gas limits. Defaults to DefaultPageSize.
+func WithPageSize(n int) ServiceOption {
+	return func(s *Service) error {
+		if n <= 0 {
+			return fmt.Errorf("page size must be positive, got %d", n)
+		}
+		s.pageSize = n
+		return nil
+	}
 }
 
-func NewService(client *ethclient.Client, registryAddress common.Address, privateKey *ecdsa.PrivateKey, chainID *big.Int) (*Service, error) {
+func NewService(client *ethclient.Client, registryAddress common.Address, privateKey *ecdsa.PrivateKey, chainID *big.Int, opts ...ServiceOption) (*Service, error) {
 	contract, err := NewContract(registryAddress, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create contract: %w", err)
@@ -31,18 +74,50 @@ func NewService(client *ethclient.Client, registryAddress common.Address, privat
 		address = crypto.PubkeyToAddress(privateKey.PublicKey)
 	}
 
-	return &Service{
+	s := &Service{
 		client:     client,
 		contract:   contract,
 		privateKey: privateKey,
 		address:    address,
 		chainID:    chainID,
-	}, nil
+		pageSize:   DefaultPageSize,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	// without a key the service is read-only
+	if privateKey != nil && chainID != nil {
+		transactor, err := txutil.NewBaseTransactor(privateKey, chainID)
+		if err != nil {
+			return nil, err
+		}
+		s.transactor = transactor
+	}
+
+	// a nil client is allowed for offline use and has nothing to check
+	if client != nil {
+		if err := contracts.CheckDeployed(context.Background(), client, "ServiceProviderRegistry", registryAddress); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SetBaseFeeMultiplier changes the base fee multiplier for subsequent
+// transactions. It is safe to call concurrently with transaction methods. See
+// WithBaseFeeMultiplier.
+func (s *Service) SetBaseFeeMultiplier(multiplier float64) error {
+	return s.contract.SetBaseFeeMultiplier(multiplier)
 }
 
 
-func (s *Service) GetProvider(ctx context.Context, providerID int) (*ProviderInfo, error) {
-	result, err := s.contract.GetProviderWithProduct(ctx, big.NewInt(int64(providerID)), uint8(ProductTypePDP))
+// GetProvider returns the provider with the given ID, or nil if it does not
+// exist. Pass callopt.WithBlock to read it as of a historical block.
+func (s *Service) GetProvider(ctx context.Context, providerID int, opts ...callopt.Option) (*ProviderInfo, error) {
+	result, err := s.contract.GetProviderWithProduct(ctx, big.NewInt(int64(providerID)), uint8(ProductTypePDP), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +139,52 @@ func (s *Service) GetProviderByAddress(ctx context.Context, addr common.Address)
 		return nil, nil
 	}
 
-	return s.GetProvider(ctx, int(result.ProviderID.Int64()))
+	id, err := toInt(result.ProviderID, "provider ID")
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProvider(ctx, id)
+}
+
+// Resolve looks up a provider from free-form user input: a decimal provider
+// ID or a 0x-prefixed service provider address. It returns the same full
+// ProviderInfo as GetProvider, or nil if no such provider is registered.
+// Malformed input fails with ErrInvalidProviderRef.
+func (s *Service) Resolve(ctx context.Context, ref string) (*ProviderInfo, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "0x") || strings.HasPrefix(ref, "0X") {
+		if !common.IsHexAddress(ref) {
+			return nil, fmt.Errorf("%w: %q is not a valid address", ErrInvalidProviderRef, ref)
+		}
+		return s.GetProviderByAddress(ctx, common.HexToAddress(ref))
+	}
+
+	id, err := strconv.ParseUint(ref, 10, 31)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is neither a provider ID nor a 0x address", ErrInvalidProviderRef, ref)
+	}
+	return s.GetProvider(ctx, int(id))
+}
+
+// GetProviderInfo returns the provider's identity from getProvider, without
+// reading any product, so it works for providers that have no PDP product.
+// Products is empty. It returns nil if the provider does not exist.
+func (s *Service) GetProviderInfo(ctx context.Context, providerID int) (*ProviderInfo, error) {
+	result, err := s.contract.GetProvider(ctx, big.NewInt(int64(providerID)))
+	if err != nil {
+		return nil, err
+	}
+	return rawToProviderInfo(result)
+}
+
+// GetProviderInfoByAddress is GetProviderInfo for a provider's service
+// address, in a single call.
+func (s *Service) GetProviderInfoByAddress(ctx context.Context, addr common.Address) (*ProviderInfo, error) {
+	result, err := s.contract.GetProviderByAddress(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return rawToProviderInfo(result)
 }
 
 func (s *Service) GetProviderIDByAddress(ctx context.Context, addr common.Address) (int, error) {
@@ -72,15 +192,19 @@ func (s *Service) GetProviderIDByAddress(ctx context.Context, addr common.Addres
 	if err != nil {
 		return 0, err
 	}
-	return int(id.Int64()), nil
+	return toInt(id, "provider ID")
 }
 
 func (s *Service) GetAllActiveProviders(ctx context.Context) ([]*ProviderInfo, error) {
 	var allProviders []*ProviderInfo
-	pageSize := big.NewInt(50)
+	pageSize := big.NewInt(int64(s.pageSize))
 	offset := big.NewInt(0)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		providerIDs, hasMore, err := s.contract.GetAllActiveProviders(ctx, offset, pageSize)
 		if err != nil {
 			return nil, err
@@ -88,7 +212,16 @@ func (s *Service) GetAllActiveProviders(ctx context.Context) ([]*ProviderInfo, e
 
 		if len(providerIDs) > 0 {
 			for _, id := range providerIDs {
-				provider, err := s.GetProvider(ctx, int(id.Int64()))
+				// lookup errors are skipped below, so check for
+				// cancellation explicitly rather than spinning through the page
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				providerID, err := toInt(id, "provider ID")
+				if err != nil {
+					return nil, err
+				}
+				provider, err := s.GetProvider(ctx, providerID)
 				if err != nil {
 					continue
 				}
@@ -114,6 +247,9 @@ func (s *Service) GetProviders(ctx context.Context, providerIDs []int) ([]*Provi
 
 	providers := make([]*ProviderInfo, 0, len(providerIDs))
 	for _, id := range providerIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		provider, err := s.GetProvider(ctx, id)
 		if err != nil {
 			continue
@@ -139,7 +275,7 @@ func (s *Service) GetProviderCount(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return int(count.Int64()), nil
+	return toInt(count, "provider count")
 }
 
 func (s *Service) ActiveProviderCount(ctx context.Context) (int, error) {
@@ -147,7 +283,7 @@ func (s *Service) ActiveProviderCount(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return int(count.Int64()), nil
+	return toInt(count, "active provider count")
 }
 
 func (s *Service) GetPDPService(ctx context.Context, providerID int) (*PDPServiceInfo, error) {
@@ -169,38 +305,133 @@ func (s *Service) GetPDPService(ctx context.Context, providerID int) (*PDPServic
 	}, nil
 }
 
+// GetProviderCapabilityKeys returns the capability keys providerID
+// advertises for productType exactly as stored on-chain, in order, including
+// custom keys that DecodePDPCapabilities ignores. It returns nil if the
+// provider has no active product of that type.
+func (s *Service) GetProviderCapabilityKeys(ctx context.Context, providerID int, productType ProductType) ([]string, error) {
+	result, err := s.contract.GetProviderWithProduct(ctx, big.NewInt(int64(providerID)), uint8(productType))
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Product.IsActive {
+		return nil, nil
+	}
+
+	return result.Product.CapabilityKeys, nil
+}
+
 func (s *Service) ProviderHasProduct(ctx context.Context, providerID int, productType ProductType) (bool, error) {
 	return s.contract.ProviderHasProduct(ctx, big.NewInt(int64(providerID)), uint8(productType))
 }
 
 
-func (s *Service) RegisterProvider(ctx context.Context, info ProviderRegistrationInfo) (common.Hash, error) {
-	if s.privateKey == nil {
-		return common.Hash{}, fmt.Errorf("private key required for write operations")
+// PreviewRegistration encodes info's offering and capabilities exactly as
+// RegisterProvider would and decodes them back with
+// DecodePDPCapabilitiesStrict, returning the keys and values that would be
+// written and the offering a client would read. Nothing is sent; use it to
+// check an offering round-trips before paying the registration fee.
+func (s *Service) PreviewRegistration(info ProviderRegistrationInfo) (keys []string, values [][]byte, decoded *PDPOffering, err error) {
+	keys, values, err = EncodePDPCapabilities(&info.PDPOffering, info.Capabilities)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode capabilities: %w", err)
 	}
 
-	fee, err := s.contract.RegistrationFee(ctx)
+	decoded, err = DecodePDPCapabilitiesStrict(CapabilitiesListToMap(keys, values))
 	if err != nil {
-		return common.Hash{}, fmt.Errorf("failed to get registration fee: %w", err)
+		return keys, values, nil, fmt.Errorf("encoded capabilities do not decode: %w", err)
+	}
+	return keys, values, decoded, nil
+}
+
+// RegisterProvider registers the caller as a PDP provider, waits for the
+// transaction to be mined and returns the provider ID assigned by the registry.
+func (s *Service) RegisterProvider(ctx context.Context, info ProviderRegistrationInfo) (*RegisterProviderResult, error) {
+	if s.privateKey == nil {
+		return nil, fmt.Errorf("private key required for write operations")
 	}
 
 	capabilityKeys, capabilityValues, err := EncodePDPCapabilities(&info.PDPOffering, info.Capabilities)
 	if err != nil {
-		return common.Hash{}, fmt.Errorf("failed to encode capabilities: %w", err)
+		return nil, fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+
+	fee, err := s.registrationPreflight(ctx, info, capabilityKeys, capabilityValues)
+	if err != nil {
+		return nil, err
 	}
 
 	opts, err := s.transactOpts(ctx)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
 	opts.Value = fee
 
 	tx, err := s.contract.RegisterProvider(opts, info.Payee, info.Name, info.Description, uint8(ProductTypePDP), capabilityKeys, capabilityValues)
 	if err != nil {
-		return common.Hash{}, fmt.Errorf("failed to register provider: %w", err)
+		return nil, fmt.Errorf("failed to register provider: %w", err)
 	}
 
-	return tx.Hash(), nil
+	receipt, err := txutil.WaitForReceipt(ctx, s.client, tx.Hash(), defaultReceiptTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for receipt of %s: %w", tx.Hash().Hex(), err)
+	}
+
+	providerID, err := s.extractProviderIDFromReceipt(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract provider ID: %w", err)
+	}
+
+	return &RegisterProviderResult{
+		ProviderID:      providerID,
+		TransactionHash: tx.Hash(),
+		Receipt:         receipt,
+	}, nil
+}
+
+// registrationPreflight reads the current registration fee, checks it against
+// info.MaxFee and checks that the wallet can pay the fee plus gas, so a
+// registration that would revert for lack of funds fails before sending.
+func (s *Service) registrationPreflight(ctx context.Context, info ProviderRegistrationInfo, capabilityKeys []string, capabilityValues [][]byte) (*big.Int, error) {
+	fee, err := s.contract.RegistrationFee(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration fee: %w", err)
+	}
+	if info.MaxFee != nil && fee.Cmp(info.MaxFee) > 0 {
+		return nil, &AmountError{Err: ErrRegistrationFeeTooHigh, Have: info.MaxFee, Want: fee}
+	}
+
+	balance, err := s.client.BalanceAt(ctx, s.address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+	// gas estimation itself fails if the value cannot be covered
+	if balance.Cmp(fee) < 0 {
+		return nil, &AmountError{Err: ErrInsufficientFunds, Have: balance, Want: fee}
+	}
+
+	gasCost, err := s.contract.EstimateRegisterProviderGasCost(ctx, s.address, fee, info.Payee, info.Name, info.Description, uint8(ProductTypePDP), capabilityKeys, capabilityValues)
+	if err != nil {
+		return nil, err
+	}
+	if total := new(big.Int).Add(fee, gasCost); balance.Cmp(total) < 0 {
+		return nil, &AmountError{Err: ErrInsufficientFunds, Have: balance, Want: total}
+	}
+
+	return fee, nil
+}
+
+// extractProviderIDFromReceipt finds the ProviderRegistered event in the
+// receipt logs and returns the provider ID it carries.
+func (s *Service) extractProviderIDFromReceipt(receipt *types.Receipt) (int, error) {
+	for _, log := range receipt.Logs {
+		id, err := s.contract.ParseProviderRegistered(*log)
+		if err == nil {
+			return toInt(id, "provider ID")
+		}
+	}
+	return 0, errors.New("ProviderRegistered event not found in receipt")
 }
 
 func (s *Service) UpdateProviderInfo(ctx context.Context, name, description string) (common.Hash, error) {
@@ -329,11 +560,40 @@ func (s *Service) convertToProviderInfo(providerID int, result *GetProviderWithP
 	}
 }
 
-func (s *Service) transactOpts(ctx context.Context) (*bind.TransactOpts, error) {
-	opts, err := bind.NewKeyedTransactorWithChainID(s.privateKey, s.chainID)
+// rawToProviderInfo converts a getProvider result, returning nil for the
+// empty info the registry returns for unknown providers.
+func rawToProviderInfo(result *GetProviderResult) (*ProviderInfo, error) {
+	if result.Info.ServiceProvider == (common.Address{}) {
+		return nil, nil
+	}
+	id, err := toInt(result.ProviderID, "provider ID")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transactor: %w", err)
+		return nil, err
+	}
+	return &ProviderInfo{
+		ID:              id,
+		ServiceProvider: result.Info.ServiceProvider,
+		Payee:           result.Info.Payee,
+		Name:            result.Info.Name,
+		Description:     result.Info.Description,
+		Active:          result.Info.IsActive,
+		Products:        map[string]*ServiceProduct{},
+	}, nil
+}
+
+// toInt converts a uint256 read from the registry to int, failing with
+// ErrIntOverflow rather than truncating.
+func toInt(n *big.Int, what string) (int, error) {
+	if n == nil || !n.IsInt64() || n.Int64() < math.MinInt || n.Int64() > math.MaxInt {
+		return 0, fmt.Errorf("%w: %s %v", ErrIntOverflow, what, n)
+	}
+	return int(n.Int64()), nil
+}
+
+// transactOpts returns a per-call copy of the transactor built in NewService.
+func (s *Service) transactOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	if s.transactor == nil {
+		return nil, fmt.Errorf("failed to create transactor: no private key and chain ID configured")
 	}
-	opts.Context = ctx
-	return opts, nil
+	return s.transactor.Opts(ctx), nil
 }
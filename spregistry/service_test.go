@@ -0,0 +1,369 @@
+package spregistry
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestService_PaginationStopsOnCancelledContext(t *testing.T) {
+	// a nil client would panic if the loops reached the contract
+	svc, err := NewService(nil, common.Address{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := svc.GetAllActiveProviders(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAllActiveProviders() error = %v, want context.Canceled", err)
+	}
+	if _, err := svc.GetProviders(ctx, []int{1, 2, 3}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetProviders() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	svc, err := NewService(nil, common.Address{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.pageSize != DefaultPageSize {
+		t.Errorf("default pageSize = %d, want %d", svc.pageSize, DefaultPageSize)
+	}
+
+	svc, err = NewService(nil, common.Address{}, nil, nil, WithPageSize(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.pageSize != 200 {
+		t.Errorf("pageSize = %d, want 200", svc.pageSize)
+	}
+
+	if _, err := NewService(nil, common.Address{}, nil, nil, WithPageSize(0)); err == nil {
+		t.Error("expected error for zero page size")
+	}
+}
+
+type testProviderInfo struct {
+	ServiceProvider common.Address
+	Payee           common.Address
+	Name            string
+	Description     string
+	IsActive        bool
+}
+
+type testProduct struct {
+	ProductType    uint8
+	CapabilityKeys []string
+	IsActive       bool
+}
+
+// registryAPI serves eth_call for the provider lookups and active-provider
+// paging of the registry, answering from providers keyed by ID. Unknown IDs
+// and addresses get the zero-valued info the registry returns.
+type registryAPI struct {
+	providers map[int64]testProviderInfo
+	// pdp lists the IDs with an active PDP product
+	pdp map[int64]bool
+	// emptyPages makes getAllActiveProviders return no IDs but report more
+	emptyPages bool
+	// pageCalls counts getAllActiveProviders calls
+	pageCalls int
+}
+
+// GetCode reports code at every address, so the deployment check passes.
+func (a *registryAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return hexutil.Bytes{0x60, 0x80}
+}
+
+func (a *registryAPI) Call(args struct {
+	Input hexutil.Bytes `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	parsed, err := abi.JSON(strings.NewReader(SPRegistryABIJSON))
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(args.Input)
+	if err != nil {
+		return nil, err
+	}
+	in, err := method.Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	switch v := in[0].(type) {
+	case *big.Int:
+		id = v.Int64()
+	case common.Address:
+		for pid, p := range a.providers {
+			if p.ServiceProvider == v {
+				id = pid
+			}
+		}
+	}
+	info := a.providers[id]
+	if info.ServiceProvider == (common.Address{}) {
+		id = 0
+	}
+
+	switch method.Name {
+	case "getProvider", "getProviderByAddress":
+		return method.Outputs.Pack(struct {
+			ProviderId *big.Int
+			Info       testProviderInfo
+		}{big.NewInt(id), info})
+	case "getProviderIdByAddress":
+		return method.Outputs.Pack(big.NewInt(id))
+	case "getAllActiveProviders":
+		a.pageCalls++
+		if a.emptyPages {
+			return method.Outputs.Pack([]*big.Int{}, true)
+		}
+		var active []int64
+		for pid, p := range a.providers {
+			if p.IsActive {
+				active = append(active, pid)
+			}
+		}
+		sort.Slice(active, func(i, j int) bool { return active[i] < active[j] })
+		offset, limit := in[0].(*big.Int).Int64(), in[1].(*big.Int).Int64()
+		ids := []*big.Int{}
+		for i := offset; i < offset+limit && i < int64(len(active)); i++ {
+			ids = append(ids, big.NewInt(active[i]))
+		}
+		return method.Outputs.Pack(ids, offset+limit < int64(len(active)))
+	case "getProviderWithProduct":
+		product := testProduct{CapabilityKeys: []string{}}
+		values := [][]byte{}
+		if a.pdp[id] {
+			product = testProduct{CapabilityKeys: []string{CapServiceURL, "x-region"}, IsActive: true}
+			values = [][]byte{[]byte("https://pdp.example.com"), []byte("eu")}
+		}
+		return method.Outputs.Pack(struct {
+			ProviderId              *big.Int
+			ProviderInfo            testProviderInfo
+			Product                 testProduct
+			ProductCapabilityValues [][]byte
+		}{big.NewInt(id), info, product, values})
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func newRegistryTestService(t *testing.T, api *registryAPI) *Service {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	svc, err := NewService(ethclient.NewClient(rpcClient), common.Address{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+var testSPAddress = common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+// newTestRegistryAPI returns a registry holding provider 3 at testSPAddress,
+// with no products.
+func newTestRegistryAPI() *registryAPI {
+	return &registryAPI{
+		providers: map[int64]testProviderInfo{
+			3: {ServiceProvider: testSPAddress, Payee: testSPAddress, Name: "sp", Description: "no products", IsActive: true},
+		},
+		pdp: map[int64]bool{},
+	}
+}
+
+func TestService_GetProviderInfo(t *testing.T) {
+	svc := newRegistryTestService(t, newTestRegistryAPI())
+	ctx := context.Background()
+
+	info, err := svc.GetProviderInfo(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetProviderInfo() error = %v", err)
+	}
+	if info == nil || info.ID != 3 || info.ServiceProvider != testSPAddress || info.Name != "sp" || !info.Active {
+		t.Fatalf("GetProviderInfo() = %+v", info)
+	}
+	if len(info.Products) != 0 {
+		t.Errorf("Products = %v, want none", info.Products)
+	}
+
+	byAddr, err := svc.GetProviderInfoByAddress(ctx, testSPAddress)
+	if err != nil || byAddr == nil || byAddr.ID != 3 {
+		t.Errorf("GetProviderInfoByAddress() = %+v, %v; want provider 3", byAddr, err)
+	}
+
+	if missing, err := svc.GetProviderInfo(ctx, 9); err != nil || missing != nil {
+		t.Errorf("GetProviderInfo(unknown) = %+v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestService_Resolve(t *testing.T) {
+	api := newTestRegistryAPI()
+	api.pdp[3] = true
+	svc := newRegistryTestService(t, api)
+	ctx := context.Background()
+
+	for _, ref := range []string{"3", " 3 ", testSPAddress.Hex(), strings.ToLower(testSPAddress.Hex())} {
+		info, err := svc.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", ref, err)
+		}
+		if info == nil || info.ID != 3 || info.Products["PDP"] == nil {
+			t.Errorf("Resolve(%q) = %+v, want provider 3 with its PDP product", ref, info)
+		}
+	}
+
+	if info, err := svc.Resolve(ctx, "0x2000000000000000000000000000000000000002"); err != nil || info != nil {
+		t.Errorf("Resolve(unknown address) = %+v, %v; want nil, nil", info, err)
+	}
+
+	for _, ref := range []string{"", "-1", "abc", "0x1234", "3.5"} {
+		if _, err := svc.Resolve(ctx, ref); !errors.Is(err, ErrInvalidProviderRef) {
+			t.Errorf("Resolve(%q) error = %v, want ErrInvalidProviderRef", ref, err)
+		}
+	}
+}
+
+func TestService_PreviewRegistration(t *testing.T) {
+	s := &Service{}
+	info := ProviderRegistrationInfo{
+		Name: "provider",
+		PDPOffering: PDPOffering{
+			ServiceURL:               "https://provider.example.com",
+			MinPieceSizeInBytes:      big.NewInt(1024),
+			MaxPieceSizeInBytes:      big.NewInt(1 << 30),
+			StoragePricePerTiBPerDay: big.NewInt(1000000),
+			MinProvingPeriodInEpochs: big.NewInt(2880),
+			Location:                 "US-EAST",
+		},
+		Capabilities: map[string]string{"region": "us"},
+	}
+
+	keys, values, decoded, err := s.PreviewRegistration(info)
+	if err != nil {
+		t.Fatalf("PreviewRegistration() error = %v", err)
+	}
+	if len(keys) != len(values) || len(keys) == 0 {
+		t.Fatalf("PreviewRegistration() = %d keys, %d values", len(keys), len(values))
+	}
+	if decoded.ServiceURL != info.PDPOffering.ServiceURL || decoded.MaxPieceSizeInBytes.Cmp(info.PDPOffering.MaxPieceSizeInBytes) != 0 {
+		t.Errorf("decoded offering = %+v, want %+v", decoded, info.PDPOffering)
+	}
+	if got := string(CapabilitiesListToMap(keys, values)["region"]); got != "us" {
+		t.Errorf("extra capability region = %q, want us", got)
+	}
+
+	info.Capabilities = map[string]string{"badHex": "0xZZZZ"}
+	if _, _, _, err := s.PreviewRegistration(info); err == nil {
+		t.Error("PreviewRegistration() expected error for invalid capability value")
+	}
+}
+
+func TestProviderIterator(t *testing.T) {
+	api := &registryAPI{providers: map[int64]testProviderInfo{}, pdp: map[int64]bool{}}
+	for id := int64(1); id <= 5; id++ {
+		addr := common.BigToAddress(big.NewInt(0x1000 + id))
+		api.providers[id] = testProviderInfo{ServiceProvider: addr, Payee: addr, IsActive: id != 3}
+	}
+	svc := newRegistryTestService(t, api)
+	svc.pageSize = 2
+	ctx := context.Background()
+
+	it := svc.ActiveProvidersIterator(ctx)
+	var got []int
+	for len(got) < 2 {
+		p, ok, err := it.Next()
+		if err != nil || !ok {
+			t.Fatalf("Next() = %v, %v, %v", p, ok, err)
+		}
+		got = append(got, p.ID)
+	}
+
+	// stop early and resume from the saved cursor
+	it = svc.ActiveProvidersIteratorFrom(ctx, it.Cursor())
+	for {
+		p, ok, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if !ok {
+			break
+		}
+		got = append(got, p.ID)
+	}
+	if want := []int{1, 2, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("iterated providers = %v, want %v", got, want)
+	}
+	if _, ok, err := it.Next(); ok || err != nil {
+		t.Errorf("Next() after end = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestProviderIterator_EmptyPage(t *testing.T) {
+	api := &registryAPI{providers: map[int64]testProviderInfo{}, pdp: map[int64]bool{}, emptyPages: true}
+	svc := newRegistryTestService(t, api)
+
+	it := svc.ActiveProvidersIterator(context.Background())
+	for i := 0; i < 2; i++ {
+		if p, ok, err := it.Next(); ok || err != nil {
+			t.Fatalf("Next() = %v, %v, %v; want nil, false, nil", p, ok, err)
+		}
+	}
+	if api.pageCalls != 1 {
+		t.Errorf("getAllActiveProviders calls = %d, want 1", api.pageCalls)
+	}
+}
+
+func TestService_GetProviderCapabilityKeys(t *testing.T) {
+	api := newTestRegistryAPI()
+	svc := newRegistryTestService(t, api)
+	ctx := context.Background()
+
+	keys, err := svc.GetProviderCapabilityKeys(ctx, 3, ProductTypePDP)
+	if err != nil || keys != nil {
+		t.Errorf("GetProviderCapabilityKeys() = %v, %v; want nil for a provider without the product", keys, err)
+	}
+
+	api.pdp[3] = true
+	keys, err = svc.GetProviderCapabilityKeys(ctx, 3, ProductTypePDP)
+	if err != nil {
+		t.Fatalf("GetProviderCapabilityKeys() error = %v", err)
+	}
+	if want := []string{CapServiceURL, "x-region"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("GetProviderCapabilityKeys() = %v, want %v", keys, want)
+	}
+}
+
+func TestToInt(t *testing.T) {
+	if n, err := toInt(big.NewInt(42), "provider ID"); err != nil || n != 42 {
+		t.Errorf("toInt(42) = %d, %v", n, err)
+	}
+
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 64)
+	for _, v := range []*big.Int{nil, tooBig} {
+		if _, err := toInt(v, "provider ID"); !errors.Is(err, ErrIntOverflow) {
+			t.Errorf("toInt(%v) error = %v, want ErrIntOverflow", v, err)
+		}
+	}
+}
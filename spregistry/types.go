@@ -4,6 +4,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 type ProductType int
@@ -22,6 +23,10 @@ type PDPOffering struct {
 	MinProvingPeriodInEpochs *big.Int
 	Location                string
 	PaymentTokenAddress     common.Address
+	// CapabilityVersion is the capability encoding version the offering was
+	// decoded from (see CapabilityEncodingVersion). It is ignored when
+	// encoding, which always writes the current version.
+	CapabilityVersion int
 }
 
 type ServiceProduct struct {
@@ -47,6 +52,17 @@ type ProviderRegistrationInfo struct {
 	Description  string
 	PDPOffering  PDPOffering
 	Capabilities map[string]string
+	// MaxFee is the most the caller agrees to pay as registration fee, in
+	// attoFIL. Nil accepts whatever fee the registry charges.
+	MaxFee *big.Int
+}
+
+// RegisterProviderResult is returned by Service.RegisterProvider once the
+// registration transaction has been mined.
+type RegisterProviderResult struct {
+	ProviderID      int
+	TransactionHash common.Hash
+	Receipt         *types.Receipt
 }
 
 type PDPServiceInfo struct {
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+// ErrInvalidCAR is returned when the input to SplitCAR or ImportCAR is not a
+// well-formed CARv1 stream.
+var ErrInvalidCAR = errors.New("invalid CAR")
+
+// maxCARHeaderSize bounds the header length prefix so a corrupt stream does
+// not trigger a huge allocation.
+const maxCARHeaderSize = 32 << 20
+
+// carHeader is a decoded CARv1 header.
+type carHeader struct {
+	Version uint64
+	Roots   []cid.Cid
+}
+
+// parseCARHeader decodes the DAG-CBOR header map of a CAR. It requires a
+// version and a roots array of CIDs; other keys are skipped.
+func parseCARHeader(b []byte) (*carHeader, error) {
+	r := bytes.NewReader(b)
+	cr := cbg.NewCborReader(r)
+
+	maj, n, err := cr.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if maj != cbg.MajMap {
+		return nil, fmt.Errorf("header is not a map")
+	}
+
+	var h carHeader
+	var hasVersion, hasRoots bool
+	for i := uint64(0); i < n; i++ {
+		key, err := cbg.ReadString(cr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read header key: %w", err)
+		}
+		switch key {
+		case "version":
+			maj, v, err := cr.ReadHeader()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read version: %w", err)
+			}
+			if maj != cbg.MajUnsignedInt {
+				return nil, fmt.Errorf("version is not an integer")
+			}
+			h.Version = v
+			hasVersion = true
+		case "roots":
+			maj, count, err := cr.ReadHeader()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read roots: %w", err)
+			}
+			if maj != cbg.MajArray || count > cbg.MaxLength {
+				return nil, fmt.Errorf("roots is not an array")
+			}
+			h.Roots = make([]cid.Cid, 0, count)
+			for j := uint64(0); j < count; j++ {
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read root %d: %w", j, err)
+				}
+				h.Roots = append(h.Roots, c)
+			}
+			hasRoots = true
+		default:
+			var skip cbg.Deferred
+			if err := skip.UnmarshalCBOR(cr); err != nil {
+				return nil, fmt.Errorf("failed to read %q: %w", key, err)
+			}
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after header", r.Len())
+	}
+	if !hasVersion {
+		return nil, fmt.Errorf("header has no version")
+	}
+	if h.Version == 1 && !hasRoots {
+		return nil, fmt.Errorf("header has no roots")
+	}
+	return &h, nil
+}
+
+// SplitCAR reads a CARv1 stream and calls fn with consecutive chunks of at
+// most maxPieceSize bytes (constants.MaxUploadSize if maxPieceSize <= 0).
+// Chunks are split only between blocks and each one repeats the original
+// header, so every chunk is itself a valid CAR with the same roots. fn owns
+// the chunk it is passed. A block too large to fit in a chunk on its own is
+// an error.
+func SplitCAR(r io.Reader, maxPieceSize int64, fn func(chunk []byte) error) error {
+	if maxPieceSize <= 0 || maxPieceSize > constants.MaxUploadSize {
+		maxPieceSize = constants.MaxUploadSize
+	}
+	br := bufio.NewReader(r)
+
+	headerLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read header length: %v", ErrInvalidCAR, err)
+	}
+	if headerLen == 0 || headerLen > maxCARHeaderSize {
+		return fmt.Errorf("%w: header length %d", ErrInvalidCAR, headerLen)
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return fmt.Errorf("%w: failed to read header: %v", ErrInvalidCAR, err)
+	}
+	parsed, err := parseCARHeader(header)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	switch parsed.Version {
+	case 1:
+	case 2:
+		return fmt.Errorf("%w: CARv2 is not supported; extract the CARv1 payload first", ErrInvalidCAR)
+	default:
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidCAR, parsed.Version)
+	}
+
+	headerSection := binary.AppendUvarint(nil, headerLen)
+	headerSection = append(headerSection, header...)
+	maxSection := maxPieceSize - int64(len(headerSection))
+
+	chunk := append([]byte(nil), headerSection...)
+	blocks := 0
+	flush := func() error {
+		if blocks == 0 {
+			return nil
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		chunk = append([]byte(nil), headerSection...)
+		blocks = 0
+		return nil
+	}
+
+	for {
+		sectionLen, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: failed to read block length: %v", ErrInvalidCAR, err)
+		}
+		if sectionLen == 0 {
+			return fmt.Errorf("%w: empty block section", ErrInvalidCAR)
+		}
+
+		// compare as uint64 before converting: a corrupt length of 2^63 or
+		// more would turn negative as an int64 and slip past the size checks
+		if sectionLen > constants.MaxUploadSize {
+			return fmt.Errorf("%w: block section length %d exceeds the %d-byte upload limit", ErrInvalidCAR, sectionLen, int64(constants.MaxUploadSize))
+		}
+
+		prefix := binary.AppendUvarint(nil, sectionLen)
+		size := int64(len(prefix)) + int64(sectionLen)
+		if size > maxSection {
+			return fmt.Errorf("%w: block section of %d bytes does not fit in a %d-byte piece", ErrInvalidPieceSize, sectionLen, maxPieceSize)
+		}
+		if int64(len(chunk))+size > maxPieceSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		chunk = append(chunk, prefix...)
+		start := len(chunk)
+		chunk = append(chunk, make([]byte, sectionLen)...)
+		if _, err := io.ReadFull(br, chunk[start:]); err != nil {
+			return fmt.Errorf("%w: truncated block section: %v", ErrInvalidCAR, err)
+		}
+		blocks++
+	}
+
+	return flush()
+}
+
+// ImportCAR splits a CARv1 stream with SplitCAR and uploads each chunk as a
+// piece to the manager's data set, returning one result per piece in order.
+// On failure it returns the pieces uploaded so far along with the error.
+// Chunks below constants.MinUploadSize cannot be uploaded: a CAR that small,
+// or one whose last chunk holds only a few tiny blocks, fails with
+// ErrInvalidPieceSize for that chunk.
+func (m *Manager) ImportCAR(ctx context.Context, r io.Reader, maxPieceSize int64) ([]UploadResult, error) {
+	var results []UploadResult
+	err := SplitCAR(r, maxPieceSize, func(chunk []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := m.UploadBytes(ctx, chunk, nil)
+		if err != nil {
+			return fmt.Errorf("failed to upload piece %d: %w", len(results), err)
+		}
+		results = append(results, *result)
+		return nil
+	})
+	return results, err
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/data-preservation-programs/go-synapse/pdp/pdptest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-cid"
+)
+
+// testCAR builds a CARv1 with n raw blocks of blockSize bytes and returns it
+// along with its header section and block sections.
+func testCAR(t *testing.T, n, blockSize int) ([]byte, []byte, [][]byte) {
+	t.Helper()
+	prefix := cid.Prefix{Version: 1, Codec: cid.Raw, MhType: 0x12, MhLength: -1}
+
+	var root []byte
+	var sections [][]byte
+	for i := 0; i < n; i++ {
+		data := bytes.Repeat([]byte{byte(i)}, blockSize)
+		c, err := prefix.Sum(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root == nil {
+			// DAG-CBOR CID bytes carry a leading multibase identity prefix
+			root = append([]byte{0x00}, c.Bytes()...)
+		}
+		section := append(c.Bytes(), data...)
+		sections = append(sections, append(binary.AppendUvarint(nil, uint64(len(section))), section...))
+	}
+
+	// {"roots": [tag42(root)], "version": 1}
+	header := []byte{0xa2, 0x65, 'r', 'o', 'o', 't', 's', 0x81, 0xd8, 0x2a, 0x58, byte(len(root))}
+	header = append(header, root...)
+	header = append(header, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x01)
+	headerSection := append(binary.AppendUvarint(nil, uint64(len(header))), header...)
+
+	car := append([]byte(nil), headerSection...)
+	for _, s := range sections {
+		car = append(car, s...)
+	}
+	return car, headerSection, sections
+}
+
+func TestSplitCAR(t *testing.T) {
+	car, headerSection, sections := testCAR(t, 10, 100)
+	maxPieceSize := int64(len(headerSection) + 3*len(sections[0]))
+
+	var chunks [][]byte
+	err := SplitCAR(bytes.NewReader(car), maxPieceSize, func(chunk []byte) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SplitCAR() error = %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want 4", len(chunks))
+	}
+
+	var blocks []byte
+	for i, chunk := range chunks {
+		if int64(len(chunk)) > maxPieceSize {
+			t.Errorf("chunk %d is %d bytes, max %d", i, len(chunk), maxPieceSize)
+		}
+		if !bytes.HasPrefix(chunk, headerSection) {
+			t.Errorf("chunk %d does not start with the CAR header", i)
+		}
+		blocks = append(blocks, chunk[len(headerSection):]...)
+	}
+	if !bytes.Equal(blocks, car[len(headerSection):]) {
+		t.Error("chunks do not reassemble to the original blocks")
+	}
+}
+
+func TestSplitCAR_Errors(t *testing.T) {
+	car, headerSection, sections := testCAR(t, 2, 100)
+	noop := func([]byte) error { return nil }
+	withHeader := func(header ...byte) []byte {
+		return append(binary.AppendUvarint(nil, uint64(len(header))), header...)
+	}
+	roots := []byte{0x65, 'r', 'o', 'o', 't', 's', 0x80}
+	version := func(v byte) []byte { return []byte{0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', v} }
+
+	tests := []struct {
+		name    string
+		input   []byte
+		max     int64
+		wantErr error
+	}{
+		{"empty", nil, 0, ErrInvalidCAR},
+		{"CARv2", withHeader(append([]byte{0xa1}, version(2)...)...), 0, ErrInvalidCAR},
+		{"unsupported version", withHeader(append(append([]byte{0xa2}, roots...), version(3)...)...), 0, ErrInvalidCAR},
+		{"no version", withHeader(append([]byte{0xa1}, roots...)...), 0, ErrInvalidCAR},
+		{"no roots", withHeader(append([]byte{0xa1}, version(1)...)...), 0, ErrInvalidCAR},
+		// the bytes of "version": 1 inside a string value are not a version
+		{"version in a value", withHeader(append(append([]byte{0xa2}, roots...), append([]byte{0x61, 'x', 0x69}, version(1)...)...)...), 0, ErrInvalidCAR},
+		{"not a map", withHeader(0x80), 0, ErrInvalidCAR},
+		{"truncated block", car[:len(car)-10], 0, ErrInvalidCAR},
+		// a 10-byte varint section length of 2^64-1, which is -1 as an int64
+		{"huge block length", append(append([]byte(nil), headerSection...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01), 0, ErrInvalidCAR},
+		{"block larger than piece", car, int64(len(headerSection) + len(sections[0]) - 1), ErrInvalidPieceSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SplitCAR(bytes.NewReader(tt.input), tt.max, noop); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SplitCAR() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	stop := fmt.Errorf("stop")
+	if err := SplitCAR(bytes.NewReader(car), 0, func([]byte) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("SplitCAR() error = %v, want callback error", err)
+	}
+}
+
+func TestManager_ImportCAR(t *testing.T) {
+	mock := pdptest.NewServer(t)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warmStorage := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	auth := pdp.NewAuthHelperFromKey(key, warmStorage, big.NewInt(31337))
+	m := NewManager(auth.Address(), warmStorage, auth, mock.Client(), 0)
+
+	// four 200-byte blocks, two to a piece
+	car, headerSection, sections := testCAR(t, 4, 200)
+	maxPieceSize := int64(len(headerSection) + 2*len(sections[0]))
+
+	results, err := m.ImportCAR(ctx, bytes.NewReader(car), maxPieceSize)
+	if err != nil {
+		t.Fatalf("ImportCAR() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("ImportCAR() uploaded %d pieces, want 2", len(results))
+	}
+	for i, r := range results {
+		stored, ok := mock.Piece(r.PieceCID)
+		if !ok {
+			t.Fatalf("piece %d not on mock", i)
+		}
+		if !bytes.HasPrefix(stored, headerSection) {
+			t.Errorf("piece %d does not start with the CAR header", i)
+		}
+	}
+	if ds, ok := mock.DataSet(results[0].DataSetID); !ok || len(ds.Pieces) != 2 {
+		t.Errorf("data set holds %v, want 2 pieces", ds)
+	}
+}
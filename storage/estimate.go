@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/spregistry"
+)
+
+const (
+	// EstimateUploadBandwidth is the upload throughput, in bytes per second,
+	// EstimateUpload assumes.
+	EstimateUploadBandwidth = 10 << 20
+
+	// EstimateConfirmationEpochs is how many epochs EstimateUpload assumes
+	// adding one piece to the data set takes to confirm.
+	EstimateConfirmationEpochs = 2
+)
+
+// EstimateUpload forecasts the cost of storing totalBytes with provider for
+// durationEpochs and roughly how long uploading it will take. The cost uses
+// the provider's PDP StoragePricePerTiBPerDay applied to the padded piece
+// sizes, truncated per epoch as the payment rail is; the duration assumes
+// EstimateUploadBandwidth and EstimateConfirmationEpochs. Nothing is read
+// from the chain.
+func EstimateUpload(ctx context.Context, totalBytes int64, provider *spregistry.ProviderInfo, durationEpochs *big.Int) (*UploadEstimate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if durationEpochs == nil || durationEpochs.Sign() <= 0 {
+		return nil, fmt.Errorf("duration must be positive, got %v", durationEpochs)
+	}
+	product := provider.Products["PDP"]
+	if product == nil || product.Data == nil || !product.IsActive {
+		return nil, fmt.Errorf("provider %d has no active PDP product", provider.ID)
+	}
+	offering := product.Data
+	if offering.StoragePricePerTiBPerDay == nil {
+		return nil, fmt.Errorf("provider %d does not advertise a storage price", provider.ID)
+	}
+
+	if totalBytes < constants.MinUploadSize {
+		_, err := ValidatePieceSize(totalBytes)
+		return nil, err
+	}
+
+	// full-size pieces plus one for the remainder
+	pieces := totalBytes / constants.MaxUploadSize
+	padded := pieces * paddedPieceSize(constants.MaxUploadSize)
+	if rest := totalBytes % constants.MaxUploadSize; rest > 0 {
+		padded += paddedPieceSize(rest)
+		pieces++
+	}
+
+	ratePerDay := new(big.Int).Mul(offering.StoragePricePerTiBPerDay, big.NewInt(padded))
+	ratePerDay.Div(ratePerDay, big.NewInt(constants.TiB))
+	ratePerEpoch := new(big.Int).Div(ratePerDay, big.NewInt(constants.EpochsPerDay))
+
+	return &UploadEstimate{
+		TotalBytes:       totalBytes,
+		Pieces:           int(pieces),
+		PaddedBytes:      padded,
+		RatePerEpoch:     ratePerEpoch,
+		RatePerDay:       ratePerDay,
+		DurationEpochs:   new(big.Int).Set(durationEpochs),
+		TotalCost:        new(big.Int).Mul(ratePerEpoch, durationEpochs),
+		PaymentToken:     offering.PaymentTokenAddress,
+		TransferTime:     time.Duration(float64(totalBytes) / EstimateUploadBandwidth * float64(time.Second)),
+		ConfirmationTime: time.Duration(pieces) * EstimateConfirmationEpochs * constants.EpochDuration,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/spregistry"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEstimateUpload(t *testing.T) {
+	token := common.HexToAddress("0x4000000000000000000000000000000000000004")
+	provider := &spregistry.ProviderInfo{
+		ID: 7,
+		Products: map[string]*spregistry.ServiceProduct{
+			"PDP": {
+				Type:     "PDP",
+				IsActive: true,
+				Data: &spregistry.PDPOffering{
+					// 2880 per padded TiB per day is one unit per TiB-epoch
+					StoragePricePerTiBPerDay: big.NewInt(2880 << 20),
+					PaymentTokenAddress:      token,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	// two full pieces and a 1 MiB remainder
+	total := int64(2*constants.MaxUploadSize + constants.MiB)
+	est, err := EstimateUpload(ctx, total, provider, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("EstimateUpload() error = %v", err)
+	}
+	if est.Pieces != 3 {
+		t.Errorf("Pieces = %d, want 3", est.Pieces)
+	}
+	if want := int64(2*constants.GiB + 2*constants.MiB); est.PaddedBytes != want {
+		t.Errorf("PaddedBytes = %d, want %d", est.PaddedBytes, want)
+	}
+	// (2 GiB + 2 MiB) padded at 2^20 per TiB-epoch
+	if est.RatePerEpoch.Int64() != 2050 || est.TotalCost.Int64() != 205000 {
+		t.Errorf("RatePerEpoch = %s, TotalCost = %s; want 2050 and 205000", est.RatePerEpoch, est.TotalCost)
+	}
+	if est.PaymentToken != token {
+		t.Errorf("PaymentToken = %s, want %s", est.PaymentToken.Hex(), token.Hex())
+	}
+	if want := 3 * EstimateConfirmationEpochs * constants.EpochDuration; est.ConfirmationTime != want {
+		t.Errorf("ConfirmationTime = %v, want %v", est.ConfirmationTime, want)
+	}
+	if est.TransferTime < 200*time.Second || est.TransferTime > 210*time.Second {
+		t.Errorf("TransferTime = %v, want about 205s at %d B/s", est.TransferTime, EstimateUploadBandwidth)
+	}
+
+	if _, err := EstimateUpload(ctx, 10, provider, big.NewInt(100)); err == nil {
+		t.Error("EstimateUpload() expected error below the minimum upload size")
+	}
+	if _, err := EstimateUpload(ctx, constants.MiB, &spregistry.ProviderInfo{ID: 8}, big.NewInt(100)); err == nil {
+		t.Error("EstimateUpload() expected error for a provider without a PDP product")
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+)
+
+// DefaultIPNIEndpoint is the public IPNI indexer FindProvidersForPiece
+// queries when no endpoint is given.
+const DefaultIPNIEndpoint = "https://cid.contact"
+
+// ipniFindResponse is the part of an IPNI /cid lookup response we read.
+type ipniFindResponse struct {
+	MultihashResults []struct {
+		ProviderResults []struct {
+			Provider struct {
+				ID    string
+				Addrs []string
+			}
+		}
+	}
+}
+
+// FindProvidersForPiece asks the IPNI indexer at ipniEndpoint (or
+// DefaultIPNIEndpoint when empty) which providers advertise pieceCID, for
+// providers with the IPNIPiece capability. Each provider is returned once,
+// in the indexer's order. A piece nobody advertises returns nil, nil.
+func FindProvidersForPiece(ctx context.Context, pieceCID cid.Cid, ipniEndpoint string) ([]ProviderRef, error) {
+	if !pieceCID.Defined() {
+		return nil, fmt.Errorf("piece CID is undefined")
+	}
+	if ipniEndpoint == "" {
+		ipniEndpoint = DefaultIPNIEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(ipniEndpoint, "/")+"/cid/"+pieceCID.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IPNI request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IPNI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("IPNI lookup failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var found ipniFindResponse
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("failed to decode IPNI response: %w", err)
+	}
+
+	var refs []ProviderRef
+	seen := map[string]bool{}
+	for _, mh := range found.MultihashResults {
+		for _, result := range mh.ProviderResults {
+			p := result.Provider
+			if p.ID == "" || seen[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			refs = append(refs, ProviderRef{
+				PeerID:     p.ID,
+				Addrs:      p.Addrs,
+				ServiceURL: httpServiceURL(p.Addrs),
+			})
+		}
+	}
+	return refs, nil
+}
+
+// httpServiceURL returns the base URL of the first HTTP multiaddr in addrs,
+// such as /dns/sp.example/tcp/443/https, or "" if there is none.
+func httpServiceURL(addrs []string) string {
+	for _, addr := range addrs {
+		parts := strings.Split(strings.Trim(addr, "/"), "/")
+		if len(parts) < 5 || parts[2] != "tcp" {
+			continue
+		}
+		switch parts[0] {
+		case "dns", "dns4", "dns6", "ip4", "ip6":
+		default:
+			continue
+		}
+
+		var scheme string
+		switch strings.Join(parts[4:], "/") {
+		case "https", "tls/http":
+			scheme = "https"
+		case "http":
+			scheme = "http"
+		default:
+			continue
+		}
+
+		host, port := parts[1], parts[3]
+		if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+			if parts[0] == "ip6" {
+				host = "[" + host + "]"
+			}
+		} else {
+			host = net.JoinHostPort(host, port)
+		}
+		return (&url.URL{Scheme: scheme, Host: host}).String()
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestFindProvidersForPiece(t *testing.T) {
+	pieceCID, err := CalculatePieceCID(make([]byte, 256))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cid/"+pieceCID.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"MultihashResults":[{"ProviderResults":[
+			{"Provider":{"ID":"12D3KooWA","Addrs":["/ip4/10.0.0.1/tcp/4001","/dns/sp1.example/tcp/443/https"]}},
+			{"Provider":{"ID":"12D3KooWB","Addrs":["/ip4/10.0.0.2/tcp/8080/http"]}},
+			{"Provider":{"ID":"12D3KooWA","Addrs":["/dns/sp1.example/tcp/443/https"]}},
+			{"Provider":{"ID":"12D3KooWC","Addrs":["/ip4/10.0.0.3/tcp/4001"]}}
+		]}]}`))
+	}))
+	defer srv.Close()
+
+	refs, err := FindProvidersForPiece(context.Background(), pieceCID, srv.URL+"/")
+	if err != nil {
+		t.Fatalf("FindProvidersForPiece() error = %v", err)
+	}
+	want := []ProviderRef{
+		{PeerID: "12D3KooWA", ServiceURL: "https://sp1.example"},
+		{PeerID: "12D3KooWB", ServiceURL: "http://10.0.0.2:8080"},
+		{PeerID: "12D3KooWC", ServiceURL: ""},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("FindProvidersForPiece() returned %d providers, want %d: %+v", len(refs), len(want), refs)
+	}
+	for i, w := range want {
+		if refs[i].PeerID != w.PeerID || refs[i].ServiceURL != w.ServiceURL {
+			t.Errorf("provider %d = %+v, want %+v", i, refs[i], w)
+		}
+	}
+
+	other, err := CalculatePieceCID(make([]byte, 512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err = FindProvidersForPiece(context.Background(), other, srv.URL)
+	if err != nil || refs != nil {
+		t.Errorf("FindProvidersForPiece(unknown) = %v, %v, want nil, nil", refs, err)
+	}
+
+	if _, err := FindProvidersForPiece(context.Background(), cid.Undef, srv.URL); err == nil {
+		t.Error("FindProvidersForPiece(cid.Undef) expected error")
+	}
+}
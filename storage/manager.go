@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/data-preservation-programs/go-synapse/internal/retry"
 	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
+	"github.com/data-preservation-programs/go-synapse/signer"
 	"github.com/data-preservation-programs/go-synapse/warmstorage"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/filecoin-project/go-commp-utils/v2/writer"
@@ -20,10 +27,32 @@ const (
 	pieceParkingTimeout    = 7 * time.Minute
 	pieceAdditionTimeout   = 7 * time.Minute
 	dataSetCreationTimeout = 7 * time.Minute
+
+	// resumeBackoffBase and resumeBackoffMax bound the wait before each
+	// resumed download request.
+	resumeBackoffBase = 250 * time.Millisecond
+	resumeBackoffMax  = 5 * time.Second
 )
 
+// ErrNotPayer is returned when adding to an existing data set whose payer is
+// not the manager's client address; FWSS would reject the signed extraData.
+var ErrNotPayer = errors.New("client is not the data set payer")
+
+// ErrSignerMismatch is returned when the auth helper signs for a different
+// address than the client address encoded as payer; FWSS would reject the
+// resulting signatures on-chain.
+var ErrSignerMismatch = errors.New("auth helper address does not match client address")
+
+// ErrPieceCIDMismatch is returned by verified uploads when the data does not
+// hash to the PieceCID the caller supplied.
+var ErrPieceCIDMismatch = errors.New("piece CID does not match data")
+
+// ErrSizeMismatch is returned by downloads when the provider sends a
+// different number of bytes than DownloadOptions.ExpectedSize.
+var ErrSizeMismatch = errors.New("piece size does not match expected size")
+
 type DataSetInfoFetcher interface {
-	GetDataSet(ctx context.Context, dataSetID int) (*warmstorage.DataSetInfo, error)
+	GetDataSet(ctx context.Context, dataSetID int, opts ...callopt.Option) (*warmstorage.DataSetInfo, error)
 }
 
 type Manager struct {
@@ -35,6 +64,12 @@ type Manager struct {
 	clientDataSetID    *big.Int
 	dataSetInfoFetcher DataSetInfoFetcher
 	clientDataSetIDLoaded bool
+	pendingCreationTx  string
+	configErr          error
+
+	inFlightMu sync.Mutex
+	inFlight   int
+	drained    chan struct{}
 }
 
 type ManagerOption func(*Manager)
@@ -52,6 +87,17 @@ func WithClientDataSetID(clientDataSetID *big.Int) ManagerOption {
 	}
 }
 
+// WithPendingDataSetCreation resumes a data set creation submitted by an
+// earlier manager (see PendingDataSetCreation) instead of creating a new one.
+// clientDataSetID must be the one the creation was signed with.
+func WithPendingDataSetCreation(txHash string, clientDataSetID *big.Int) ManagerOption {
+	return func(m *Manager) {
+		m.pendingCreationTx = txHash
+		m.clientDataSetID = clientDataSetID
+		m.clientDataSetIDLoaded = true
+	}
+}
+
 func NewManager(
 	clientAddress common.Address,
 	warmStorageAddress common.Address,
@@ -68,13 +114,30 @@ func NewManager(
 		dataSetID:          dataSetID,
 		clientDataSetID:    big.NewInt(0),
 	}
+	if authHelper != nil && authHelper.Address() != clientAddress {
+		// reported by every write so the mismatch surfaces before a transaction
+		m.configErr = fmt.Errorf("%w: signer %s, client %s",
+			ErrSignerMismatch, describeAddress(authHelper.Address()), describeAddress(clientAddress))
+	}
 	for _, opt := range opts {
 		opt(m)
 	}
 	return m
 }
 
+// Err returns the configuration error detected by NewManager, such as
+// ErrSignerMismatch, or nil. Uploads fail with the same error.
+func (m *Manager) Err() error {
+	return m.configErr
+}
+
+// Upload stores data with the provider and adds it to the data set. If
+// opts.PieceCID and opts.Size are both set, data is streamed without being
+// buffered or re-hashed (unless opts.Verify is set); otherwise it is read
+// into memory and handled by UploadBytes.
 func (m *Manager) Upload(ctx context.Context, data io.Reader, opts *UploadOptions) (*UploadResult, error) {
+	defer m.track()()
+
 	if opts == nil {
 		opts = &UploadOptions{}
 	}
@@ -88,28 +151,41 @@ func (m *Manager) Upload(ctx context.Context, data io.Reader, opts *UploadOption
 		return nil, fmt.Errorf("failed to read data: %w", err)
 	}
 
-	return m.UploadBytes(ctx, dataBytes, opts)
+	return m.uploadBytes(ctx, dataBytes, opts)
 }
 
 func (m *Manager) UploadBytes(ctx context.Context, data []byte, opts *UploadOptions) (*UploadResult, error) {
+	defer m.track()()
+	return m.uploadBytes(ctx, data, opts)
+}
+
+func (m *Manager) uploadBytes(ctx context.Context, data []byte, opts *UploadOptions) (*UploadResult, error) {
 	if opts == nil {
 		opts = &UploadOptions{}
 	}
 
+	if _, err := ValidatePieceSize(int64(len(data))); err != nil {
+		return nil, err
+	}
+
 	pieceCID := opts.PieceCID
-	if pieceCID == cid.Undef {
-		var err error
-		pieceCID, err = CalculatePieceCID(data)
+	if pieceCID == cid.Undef || opts.Verify {
+		computed, err := CalculatePieceCID(data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate PieceCID: %w", err)
 		}
+		if pieceCID != cid.Undef && !computed.Equals(pieceCID) {
+			return nil, fmt.Errorf("%w: computed %s, expected %s", ErrPieceCIDMismatch, computed, pieceCID)
+		}
+		pieceCID = computed
 	}
 
-	if err := m.ensureDataSet(ctx); err != nil {
+	creationTxHash, err := m.ensureDataSet(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to ensure data set: %w", err)
 	}
 
-	_, err := m.pdpServer.UploadPiece(ctx, bytes.NewReader(data), int64(len(data)), pieceCID)
+	_, err = m.pdpServer.UploadPiece(ctx, bytes.NewReader(data), int64(len(data)), pieceCID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload piece: %w", err)
 	}
@@ -118,57 +194,394 @@ func (m *Manager) UploadBytes(ctx context.Context, data []byte, opts *UploadOpti
 		return nil, fmt.Errorf("failed waiting for piece: %w", err)
 	}
 
-	pieceID, err := m.addPieceToDataSet(ctx, pieceCID, opts.Metadata)
+	pieceID, txHash, err := m.addPieceToDataSet(ctx, pieceCID, opts.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add piece to data set: %w", err)
 	}
 
 	return &UploadResult{
-		PieceCID:  pieceCID,
-		Size:      int64(len(data)),
-		PieceID:   pieceID,
-		DataSetID: m.dataSetID,
+		PieceCID:              pieceCID,
+		Size:                  int64(len(data)),
+		PieceID:               pieceID,
+		DataSetID:             m.dataSetID,
+		TxHash:                txHash,
+		DataSetCreationTxHash: creationTxHash,
 	}, nil
 }
 
+// uploadStream is the zero-recompute path: data is streamed to the provider
+// as-is under the caller's PieceCID and Size. With opts.Verify the CommP is
+// computed from the same stream as it is sent.
 func (m *Manager) uploadStream(ctx context.Context, data io.Reader, opts *UploadOptions) (*UploadResult, error) {
-	if err := m.ensureDataSet(ctx); err != nil {
+	if _, err := ValidatePieceSize(opts.Size); err != nil {
+		return nil, err
+	}
+
+	creationTxHash, err := m.ensureDataSet(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to ensure data set: %w", err)
 	}
 
-	_, err := m.pdpServer.UploadPiece(ctx, data, opts.Size, opts.PieceCID)
+	var cp *writer.Writer
+	if opts.Verify {
+		cp = &writer.Writer{}
+		data = io.TeeReader(data, cp)
+	}
+
+	_, err = m.pdpServer.UploadPiece(ctx, data, opts.Size, opts.PieceCID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload piece: %w", err)
 	}
 
+	if cp != nil {
+		sum, err := cp.Sum()
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate PieceCID: %w", err)
+		}
+		if sum.PayloadSize != opts.Size {
+			return nil, fmt.Errorf("%w: read %d bytes, expected %d", ErrPieceCIDMismatch, sum.PayloadSize, opts.Size)
+		}
+		if !sum.PieceCID.Equals(opts.PieceCID) {
+			return nil, fmt.Errorf("%w: computed %s, expected %s", ErrPieceCIDMismatch, sum.PieceCID, opts.PieceCID)
+		}
+	}
+
 	if err := m.pdpServer.WaitForPiece(ctx, opts.PieceCID, pieceParkingTimeout); err != nil {
 		return nil, fmt.Errorf("failed waiting for piece: %w", err)
 	}
 
-	pieceID, err := m.addPieceToDataSet(ctx, opts.PieceCID, opts.Metadata)
+	pieceID, txHash, err := m.addPieceToDataSet(ctx, opts.PieceCID, opts.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add piece to data set: %w", err)
 	}
 
 	return &UploadResult{
-		PieceCID:  opts.PieceCID,
-		Size:      opts.Size,
-		PieceID:   pieceID,
-		DataSetID: m.dataSetID,
+		PieceCID:              opts.PieceCID,
+		Size:                  opts.Size,
+		PieceID:               pieceID,
+		DataSetID:             m.dataSetID,
+		TxHash:                txHash,
+		DataSetCreationTxHash: creationTxHash,
 	}, nil
 }
 
+// InFlight returns the number of uploads currently running on the manager.
+func (m *Manager) InFlight() int {
+	m.inFlightMu.Lock()
+	defer m.inFlightMu.Unlock()
+	return m.inFlight
+}
+
+// Drain blocks until no uploads are in flight or ctx is done, in which case
+// it returns ctx.Err(). It does not stop new uploads from starting; on
+// shutdown, stop issuing uploads first and then call Drain so pieces being
+// uploaded and add-pieces transactions being confirmed are not abandoned.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.inFlightMu.Lock()
+	if m.inFlight == 0 {
+		m.inFlightMu.Unlock()
+		return nil
+	}
+	drained := m.drained
+	m.inFlightMu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track registers an in-flight upload and returns the func that ends it.
+func (m *Manager) track() func() {
+	m.inFlightMu.Lock()
+	if m.inFlight == 0 {
+		m.drained = make(chan struct{})
+	}
+	m.inFlight++
+	m.inFlightMu.Unlock()
+
+	return func() {
+		m.inFlightMu.Lock()
+		m.inFlight--
+		if m.inFlight == 0 {
+			close(m.drained)
+		}
+		m.inFlightMu.Unlock()
+	}
+}
+
+// Download fetches the piece from the manager's provider, falling back to
+// opts.Providers in order, and returns the first copy whose CommP matches
+// pieceCID (and whose length matches opts.ExpectedSize, if set).
 func (m *Manager) Download(ctx context.Context, pieceCID cid.Cid, opts *DownloadOptions) ([]byte, error) {
-	return m.pdpServer.DownloadPiece(ctx, pieceCID)
+	sources, err := m.downloadSources(opts)
+	if err != nil {
+		return nil, err
+	}
+	expectedSize := expectedDownloadSize(opts)
+
+	var errs []error
+	for _, server := range sources {
+		data, err := server.DownloadPiece(ctx, pieceCID)
+		if err == nil {
+			err = checkDownloadSize(int64(len(data)), expectedSize)
+		}
+		if err == nil {
+			err = verifyPieceCID(data, pieceCID)
+		}
+		if err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", server.BaseURL(), err))
+	}
+	return nil, fmt.Errorf("failed to download piece %s: %w", pieceCID, errors.Join(errs...))
+}
+
+// DownloadToFile streams the piece to path, verifying its CommP (and
+// opts.ExpectedSize, if set) as it is written, and returns the number of
+// bytes written. Sources are tried as in Download. The data goes to a
+// temporary file next to path that is renamed into place only once verified,
+// so path never holds a partial or corrupt piece. With opts.MaxResumes set, a
+// transfer cut off part way is continued from the last byte received rather
+// than restarted or abandoned.
+func (m *Manager) DownloadToFile(ctx context.Context, pieceCID cid.Cid, path string, opts *DownloadOptions) (int64, error) {
+	sources, err := m.downloadSources(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	expectedSize := expectedDownloadSize(opts)
+	var maxResumes int
+	if opts != nil {
+		maxResumes = opts.MaxResumes
+	}
+
+	var errs []error
+	for _, server := range sources {
+		n, err := streamPieceTo(ctx, server, pieceCID, expectedSize, maxResumes, tmp)
+		if err == nil {
+			if err := tmp.Close(); err != nil {
+				return 0, fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			if err := os.Rename(tmp.Name(), path); err != nil {
+				return 0, fmt.Errorf("failed to move piece into place: %w", err)
+			}
+			return n, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", server.BaseURL(), err))
+	}
+	return 0, fmt.Errorf("failed to download piece %s: %w", pieceCID, errors.Join(errs...))
+}
+
+// streamPieceTo truncates f and copies the piece into it from server,
+// computing CommP on the way. With expectedSize > 0 it reads at most one
+// byte past it, so a padded body is caught without reading it all. A read
+// error part way through is resumed from the bytes already written, up to
+// maxResumes times, backing off between attempts.
+func streamPieceTo(ctx context.Context, server *pdp.Server, pieceCID cid.Cid, expectedSize int64, maxResumes int, f *os.File) (int64, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+
+	cp := &writer.Writer{}
+	dst := io.MultiWriter(f, cp)
+	var n int64
+	var wait time.Duration
+	for resumes := 0; ; resumes++ {
+		if resumes > 0 {
+			// give a provider that just dropped the connection a moment
+			// before asking it again
+			wait = retry.CalculateBackoff(wait, resumeBackoffBase, resumeBackoffMax)
+			select {
+			case <-ctx.Done():
+				return n, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		body, err := server.DownloadPieceStreamFrom(ctx, pieceCID, n)
+		if err != nil {
+			// only a dropped transfer is resumed; a failed first request or
+			// a provider without Range support is not
+			if resumes == 0 || resumes >= maxResumes || errors.Is(err, pdp.ErrRangeNotSupported) || ctx.Err() != nil {
+				return n, err
+			}
+			continue
+		}
+
+		src := &readErrRecorder{r: body}
+		var limited io.Reader = src
+		if expectedSize > 0 {
+			limited = io.LimitReader(src, expectedSize+1-n)
+		}
+		copied, err := io.Copy(dst, limited)
+		body.Close()
+		n += copied
+		if err == nil {
+			break
+		}
+		if src.err == nil || resumes >= maxResumes || ctx.Err() != nil {
+			return n, fmt.Errorf("failed to read piece: %w", err)
+		}
+	}
+
+	if err := checkDownloadSize(n, expectedSize); err != nil {
+		return n, err
+	}
+	sum, err := cp.Sum()
+	if err != nil {
+		return n, fmt.Errorf("failed to calculate PieceCID: %w", err)
+	}
+	if !sum.PieceCID.Equals(pieceCID) {
+		return n, fmt.Errorf("%w: computed %s, expected %s", ErrPieceCIDMismatch, sum.PieceCID, pieceCID)
+	}
+	return n, nil
+}
+
+// readErrRecorder keeps the error a read failed with, so a dropped
+// connection can be told apart from a failed write to the file.
+type readErrRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// downloadSources returns the manager's provider followed by the fallbacks
+// in opts.
+func (m *Manager) downloadSources(opts *DownloadOptions) ([]*pdp.Server, error) {
+	var sources []*pdp.Server
+	if m.pdpServer != nil {
+		sources = append(sources, m.pdpServer)
+	}
+	if opts != nil {
+		for _, providerURL := range opts.Providers {
+			server, err := pdp.NewServerValidated(providerURL)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, server)
+		}
+	}
+	return sources, nil
+}
+
+func expectedDownloadSize(opts *DownloadOptions) int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.ExpectedSize
+}
+
+// checkDownloadSize compares a received length with the expected one; an
+// expected size of 0 or less is not checked.
+func checkDownloadSize(got, expected int64) error {
+	if expected > 0 && got != expected {
+		if got > expected {
+			return fmt.Errorf("%w: received more than %d bytes", ErrSizeMismatch, expected)
+		}
+		return fmt.Errorf("%w: received %d bytes, expected %d", ErrSizeMismatch, got, expected)
+	}
+	return nil
+}
+
+func verifyPieceCID(data []byte, pieceCID cid.Cid) error {
+	computed, err := CalculatePieceCID(data)
+	if err != nil {
+		return fmt.Errorf("failed to calculate PieceCID: %w", err)
+	}
+	if !computed.Equals(pieceCID) {
+		return fmt.Errorf("%w: computed %s, expected %s", ErrPieceCIDMismatch, computed, pieceCID)
+	}
+	return nil
+}
+
+// StatPiece reports whether the provider holds pieceCID and its size, so a
+// download can be planned (or a missing piece caught) before any data is
+// transferred.
+func (m *Manager) StatPiece(ctx context.Context, pieceCID cid.Cid) (*PieceStat, error) {
+	size, exists, err := m.pdpServer.HeadPiece(ctx, pieceCID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat piece: %w", err)
+	}
+	if !exists {
+		size = 0
+	}
+	return &PieceStat{
+		Exists:   exists,
+		Size:     size,
+		Provider: m.pdpServer.BaseURL(),
+	}, nil
+}
+
+// UseDataSet rebinds the manager to an existing data set, loading its client
+// data set ID through the DataSetInfoFetcher. Any pending creation is
+// forgotten. On error the manager is left unbound (data set 0), so the next
+// upload creates a new data set.
+func (m *Manager) UseDataSet(ctx context.Context, dataSetID int) error {
+	m.dataSetID = dataSetID
+	m.clientDataSetID = big.NewInt(0)
+	m.clientDataSetIDLoaded = false
+	m.pendingCreationTx = ""
+
+	if dataSetID == 0 {
+		return nil
+	}
+	if err := m.ensureClientDataSetID(ctx); err != nil {
+		m.dataSetID = 0
+		return err
+	}
+	return nil
 }
 
 func (m *Manager) DataSetID() int {
 	return m.dataSetID
 }
 
-func (m *Manager) ensureDataSet(ctx context.Context) error {
+// ensureDataSet binds the manager to a data set, creating one if needed. It
+// returns the creation transaction hash when this call created (or finished
+// creating) the data set, and "" when one was already bound.
+func (m *Manager) ensureDataSet(ctx context.Context) (string, error) {
+	if m.configErr != nil {
+		return "", m.configErr
+	}
+
 	if m.dataSetID != 0 {
-		return m.ensureClientDataSetID(ctx)
+		return "", m.ensureClientDataSetID(ctx)
+	}
+
+	if m.pendingCreationTx != "" {
+		txHash := m.pendingCreationTx
+		resumed, err := m.resumeDataSetCreation(ctx)
+		if err != nil {
+			return "", err
+		}
+		if resumed {
+			return txHash, nil
+		}
 	}
 
 	m.clientDataSetID = randomBigInt()
@@ -177,7 +590,7 @@ func (m *Manager) ensureDataSet(ctx context.Context) error {
 
 	authSig, err := m.authHelper.SignCreateDataSet(m.clientDataSetID, m.authHelper.Address(), metadata)
 	if err != nil {
-		return fmt.Errorf("failed to sign create data set: %w", err)
+		return "", fmt.Errorf("failed to sign create data set: %w", err)
 	}
 
 	extraData, err := pdp.EncodeDataSetCreateData(
@@ -187,15 +600,46 @@ func (m *Manager) ensureDataSet(ctx context.Context) error {
 		authSig.Signature,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to encode extra data: %w", err)
+		return "", fmt.Errorf("failed to encode extra data: %w", err)
 	}
 
 	createResp, err := m.pdpServer.CreateDataSet(ctx, m.warmStorageAddress.Hex(), extraData)
 	if err != nil {
-		return fmt.Errorf("failed to create data set: %w", err)
+		return "", fmt.Errorf("failed to create data set: %w", err)
 	}
 
-	status, err := m.pdpServer.WaitForDataSetCreation(ctx, createResp.TxHash, dataSetCreationTimeout)
+	// remembered until the creation is confirmed so a timeout below does not
+	// lead the next upload to create a second data set
+	m.pendingCreationTx = createResp.TxHash
+
+	if err := m.waitForDataSetCreation(ctx); err != nil {
+		return "", err
+	}
+	return createResp.TxHash, nil
+}
+
+// resumeDataSetCreation checks on a creation submitted earlier. It reports
+// true once the manager is bound to the created data set, and false if the
+// creation failed on-chain and a new one should be submitted.
+func (m *Manager) resumeDataSetCreation(ctx context.Context) (bool, error) {
+	status, err := m.pdpServer.GetDataSetCreationStatus(ctx, m.pendingCreationTx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending data set creation %s: %w", m.pendingCreationTx, err)
+	}
+
+	if status.OK != nil && !*status.OK {
+		m.pendingCreationTx = ""
+		return false, nil
+	}
+
+	if err := m.waitForDataSetCreation(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *Manager) waitForDataSetCreation(ctx context.Context) error {
+	status, err := m.pdpServer.WaitForDataSetCreation(ctx, m.pendingCreationTx, dataSetCreationTimeout)
 	if err != nil {
 		return fmt.Errorf("failed waiting for data set creation: %w", err)
 	}
@@ -205,9 +649,21 @@ func (m *Manager) ensureDataSet(ctx context.Context) error {
 	}
 
 	m.dataSetID = *status.DataSetID
+	m.pendingCreationTx = ""
 	return nil
 }
 
+// PendingDataSetCreation returns the transaction hash and client data set ID
+// of a data set creation that was submitted but not yet confirmed, or "" if
+// there is none. Persist them and pass them to WithPendingDataSetCreation to
+// resume after a restart.
+func (m *Manager) PendingDataSetCreation() (string, *big.Int) {
+	if m.pendingCreationTx == "" {
+		return "", nil
+	}
+	return m.pendingCreationTx, m.clientDataSetID
+}
+
 func (m *Manager) ensureClientDataSetID(ctx context.Context) error {
 	if m.clientDataSetIDLoaded {
 		return nil
@@ -218,16 +674,36 @@ func (m *Manager) ensureClientDataSetID(ctx context.Context) error {
 	}
 
 	info, err := m.dataSetInfoFetcher.GetDataSet(ctx, m.dataSetID)
+	if errors.Is(err, warmstorage.ErrDataSetNotExist) {
+		return fmt.Errorf("dataset %d not found on-chain; check the configured data set ID: %w", m.dataSetID, err)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch dataset info for dataset %d: %w", m.dataSetID, err)
 	}
 
+	if info.Payer != m.clientAddress {
+		return fmt.Errorf("%w: data set %d is paid by %s, client is %s",
+			ErrNotPayer, m.dataSetID, describeAddress(info.Payer), describeAddress(m.clientAddress))
+	}
+
 	m.clientDataSetID = info.ClientDataSetID
 	m.clientDataSetIDLoaded = true
 	return nil
 }
 
-func (m *Manager) addPieceToDataSet(ctx context.Context, pieceCID cid.Cid, metadata map[string]string) (int, error) {
+// describeAddress formats an EVM address with its f410 form, since users
+// often know their wallet by its Filecoin address.
+func describeAddress(addr common.Address) string {
+	fil, err := signer.EVMToFilecoin(addr)
+	if err != nil {
+		return addr.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", addr.Hex(), fil)
+}
+
+// addPieceToDataSet adds the piece to the bound data set and returns its
+// piece ID and the add-pieces transaction hash.
+func (m *Manager) addPieceToDataSet(ctx context.Context, pieceCID cid.Cid, metadata map[string]string) (int, string, error) {
 	var pieceMetadata []pdp.MetadataEntry
 	for k, v := range metadata {
 		pieceMetadata = append(pieceMetadata, pdp.MetadataEntry{Key: k, Value: v})
@@ -238,45 +714,52 @@ func (m *Manager) addPieceToDataSet(ctx context.Context, pieceCID cid.Cid, metad
 
 	authSig, err := m.authHelper.SignAddPieces(m.clientDataSetID, nonce, []cid.Cid{pieceCID}, allMetadata)
 	if err != nil {
-		return 0, fmt.Errorf("failed to sign add pieces: %w", err)
+		return 0, "", fmt.Errorf("failed to sign add pieces: %w", err)
 	}
 
 	extraData, err := pdp.EncodeAddPiecesExtraData(nonce, allMetadata, authSig.Signature)
 	if err != nil {
-		return 0, fmt.Errorf("failed to encode extra data: %w", err)
+		return 0, "", fmt.Errorf("failed to encode extra data: %w", err)
 	}
 
 	addResp, err := m.pdpServer.AddPieces(ctx, m.dataSetID, []cid.Cid{pieceCID}, extraData)
 	if err != nil {
-		return 0, fmt.Errorf("failed to add pieces: %w", err)
+		return 0, "", fmt.Errorf("failed to add pieces: %w", err)
 	}
 
 	status, err := m.pdpServer.WaitForPieceAddition(ctx, m.dataSetID, addResp.TxHash, pieceAdditionTimeout)
 	if err != nil {
-		return 0, fmt.Errorf("failed waiting for piece addition: %w", err)
+		return 0, "", fmt.Errorf("failed waiting for piece addition: %w", err)
 	}
 
 	if len(status.ConfirmedPieceIDs) == 0 {
-		return 0, fmt.Errorf("no piece IDs returned")
+		return 0, "", fmt.Errorf("no piece IDs returned")
 	}
 
-	return status.ConfirmedPieceIDs[0], nil
+	return status.ConfirmedPieceIDs[0], addResp.TxHash, nil
 }
 
 func CalculatePieceCID(data []byte) (cid.Cid, error) {
+	pieceCID, _, _, err := CalculatePieceCIDWithSizes(data)
+	return pieceCID, err
+}
+
+// CalculatePieceCIDWithSizes is CalculatePieceCID that also returns the raw
+// size of data and the padded size of the piece it makes, as used for
+// proof set entries and cost estimates.
+func CalculatePieceCIDWithSizes(data []byte) (pieceCID cid.Cid, rawSize, paddedSize int64, err error) {
 	w := &writer.Writer{}
 
-	_, err := w.Write(data)
-	if err != nil {
-		return cid.Undef, fmt.Errorf("failed to write to CommP calculator: %w", err)
+	if _, err := w.Write(data); err != nil {
+		return cid.Undef, 0, 0, fmt.Errorf("failed to write to CommP calculator: %w", err)
 	}
 
 	result, err := w.Sum()
 	if err != nil {
-		return cid.Undef, fmt.Errorf("failed to calculate CommP: %w", err)
+		return cid.Undef, 0, 0, fmt.Errorf("failed to calculate CommP: %w", err)
 	}
 
-	return result.PieceCID, nil
+	return result.PieceCID, result.PayloadSize, int64(result.PieceSize), nil
 }
 
 func randomBigInt() *big.Int {
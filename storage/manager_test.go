@@ -0,0 +1,453 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
+	"github.com/data-preservation-programs/go-synapse/warmstorage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type fakeDataSetInfoFetcher struct {
+	info *warmstorage.DataSetInfo
+	err  error
+}
+
+func (f fakeDataSetInfoFetcher) GetDataSet(ctx context.Context, dataSetID int, opts ...callopt.Option) (*warmstorage.DataSetInfo, error) {
+	return f.info, f.err
+}
+
+func TestEnsureClientDataSetID_ChecksPayer(t *testing.T) {
+	client := common.HexToAddress("0xd388ab098ed3e84c0d808776440b48f685198498")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	t.Run("payer matches", func(t *testing.T) {
+		fetcher := fakeDataSetInfoFetcher{info: &warmstorage.DataSetInfo{Payer: client, ClientDataSetID: big.NewInt(42)}}
+		m := NewManager(client, common.Address{}, nil, nil, 7, WithDataSetInfoFetcher(fetcher))
+		if err := m.ensureClientDataSetID(context.Background()); err != nil {
+			t.Fatalf("ensureClientDataSetID() error = %v", err)
+		}
+		if m.clientDataSetID.Int64() != 42 {
+			t.Errorf("clientDataSetID = %s, want 42", m.clientDataSetID)
+		}
+	})
+
+	t.Run("different payer", func(t *testing.T) {
+		fetcher := fakeDataSetInfoFetcher{info: &warmstorage.DataSetInfo{Payer: other, ClientDataSetID: big.NewInt(42)}}
+		m := NewManager(client, common.Address{}, nil, nil, 7, WithDataSetInfoFetcher(fetcher))
+		err := m.ensureClientDataSetID(context.Background())
+		if !errors.Is(err, ErrNotPayer) {
+			t.Fatalf("ensureClientDataSetID() error = %v, want ErrNotPayer", err)
+		}
+		if m.clientDataSetIDLoaded {
+			t.Error("client data set ID should not be cached after a payer mismatch")
+		}
+	})
+
+	t.Run("data set does not exist", func(t *testing.T) {
+		fetcher := fakeDataSetInfoFetcher{err: fmt.Errorf("%w: 7", warmstorage.ErrDataSetNotExist)}
+		m := NewManager(client, common.Address{}, nil, nil, 7, WithDataSetInfoFetcher(fetcher))
+		err := m.ensureClientDataSetID(context.Background())
+		if !errors.Is(err, warmstorage.ErrDataSetNotExist) {
+			t.Fatalf("ensureClientDataSetID() error = %v, want ErrDataSetNotExist", err)
+		}
+	})
+}
+
+// acceptingUploadServer accepts any upload without checking the piece CID,
+// so client-side verification is what catches a mismatch.
+func acceptingUploadServer(t *testing.T) *pdp.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/pdp/piece/uploads":
+			w.Header().Set("Location", "/pdp/piece/uploads/0a1b2c3d")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut:
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return pdp.NewServer(srv.URL)
+}
+
+func TestUpload_VerifyPieceCID(t *testing.T) {
+	data := bytes.Repeat([]byte("verify"), 64)
+	wrong, err := CalculatePieceCID(bytes.Repeat([]byte("other!"), 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("buffered path fails before contacting the provider", func(t *testing.T) {
+		m := NewManager(common.Address{}, common.Address{}, nil, nil, 7)
+		_, err := m.UploadBytes(context.Background(), data, &UploadOptions{PieceCID: wrong, Verify: true})
+		if !errors.Is(err, ErrPieceCIDMismatch) {
+			t.Fatalf("UploadBytes() error = %v, want ErrPieceCIDMismatch", err)
+		}
+	})
+
+	t.Run("streaming path fails before adding the piece", func(t *testing.T) {
+		m := NewManager(common.Address{}, common.Address{}, nil, acceptingUploadServer(t), 7, WithClientDataSetID(big.NewInt(1)))
+		opts := &UploadOptions{PieceCID: wrong, Size: int64(len(data)), Verify: true}
+		_, err := m.Upload(context.Background(), bytes.NewReader(data), opts)
+		if !errors.Is(err, ErrPieceCIDMismatch) {
+			t.Fatalf("Upload() error = %v, want ErrPieceCIDMismatch", err)
+		}
+	})
+}
+
+func TestDrain(t *testing.T) {
+	m := NewManager(common.Address{}, common.Address{}, nil, nil, 7)
+	if err := m.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() on idle manager error = %v", err)
+	}
+
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Location", "/pdp/piece/uploads/0a1b2c3d")
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			_, _ = io.Copy(io.Discard, r.Body)
+			close(arrived)
+			<-release
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	m = NewManager(common.Address{}, common.Address{}, nil, pdp.NewServer(srv.URL), 7, WithClientDataSetID(big.NewInt(1)))
+
+	data := bytes.Repeat([]byte("drain!"), 64)
+	pieceCID, err := CalculatePieceCID(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.Upload(context.Background(), bytes.NewReader(data), &UploadOptions{PieceCID: pieceCID, Size: int64(len(data))})
+		done <- err
+	}()
+
+	<-arrived
+	if n := m.InFlight(); n != 1 {
+		t.Errorf("InFlight() = %d, want 1", n)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() with upload in flight error = %v, want DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := m.Drain(context.Background()); err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+	if n := m.InFlight(); n != 0 {
+		t.Errorf("InFlight() after drain = %d, want 0", n)
+	}
+	if err := <-done; err == nil {
+		t.Error("Upload() expected error from failed PUT")
+	}
+}
+
+// pieceServer serves data for every piece download.
+func pieceServer(t *testing.T, data []byte) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestDownloadToFile(t *testing.T) {
+	data := bytes.Repeat([]byte("retrieve"), 64)
+	pieceCID, err := CalculatePieceCID(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(missing.Close)
+	corrupt := pieceServer(t, bytes.Repeat([]byte("garbage!"), 64))
+	good := pieceServer(t, data)
+
+	m := NewManager(common.Address{}, common.Address{}, nil, pdp.NewServer(missing.URL), 7)
+	path := filepath.Join(t.TempDir(), "piece.bin")
+
+	n, err := m.DownloadToFile(context.Background(), pieceCID, path, &DownloadOptions{Providers: []string{corrupt, good}})
+	if err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("DownloadToFile() wrote %d bytes, want %d", n, len(data))
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("file contents do not match the piece")
+	}
+
+	downloaded, err := m.Download(context.Background(), pieceCID, &DownloadOptions{Providers: []string{corrupt, good}})
+	if err != nil || !bytes.Equal(downloaded, data) {
+		t.Errorf("Download() = %d bytes, %v; want the piece", len(downloaded), err)
+	}
+
+	t.Run("schemeless fallback", func(t *testing.T) {
+		_, err := m.Download(context.Background(), pieceCID, &DownloadOptions{Providers: []string{"provider.example.com"}})
+		if err == nil || !strings.Contains(err.Error(), "scheme") {
+			t.Errorf("Download() error = %v, want invalid provider URL", err)
+		}
+	})
+
+	t.Run("no valid source", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "piece.bin")
+		_, err := m.DownloadToFile(context.Background(), pieceCID, path, &DownloadOptions{Providers: []string{corrupt}})
+		if !errors.Is(err, ErrPieceCIDMismatch) {
+			t.Fatalf("DownloadToFile() error = %v, want ErrPieceCIDMismatch", err)
+		}
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		if len(entries) != 0 {
+			t.Errorf("left %d files behind after failed download", len(entries))
+		}
+	})
+
+	t.Run("expected size", func(t *testing.T) {
+		truncated := pieceServer(t, data[:len(data)-8])
+		padded := pieceServer(t, append(append([]byte(nil), data...), 0, 0, 0))
+
+		for _, src := range []string{truncated, padded} {
+			opts := &DownloadOptions{Providers: []string{src}, ExpectedSize: int64(len(data))}
+			if _, err := m.Download(context.Background(), pieceCID, opts); !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("Download() error = %v, want ErrSizeMismatch", err)
+			}
+			path := filepath.Join(t.TempDir(), "piece.bin")
+			if _, err := m.DownloadToFile(context.Background(), pieceCID, path, opts); !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("DownloadToFile() error = %v, want ErrSizeMismatch", err)
+			}
+		}
+
+		opts := &DownloadOptions{Providers: []string{padded, good}, ExpectedSize: int64(len(data))}
+		got, err := m.Download(context.Background(), pieceCID, opts)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Download() = %d bytes, %v; want the piece from the second source", len(got), err)
+		}
+	})
+}
+
+// flakyPieceServer serves data honouring "Range: bytes=N-", but cuts the
+// connection after sending at most chunk bytes of any response, so a
+// download only completes by resuming. It records the Range headers seen.
+func flakyPieceServer(t *testing.T, data []byte, chunk int) (string, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+
+		body := data
+		if rng := r.Header.Get("Range"); rng != "" {
+			var offset int
+			if _, err := fmt.Sscanf(rng, "bytes=%d-", &offset); err != nil || offset >= len(data) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			body = data[offset:]
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(data)-1, len(data)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		}
+		if len(body) <= chunk {
+			_, _ = w.Write(body)
+			return
+		}
+		_, _ = w.Write(body[:chunk])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &ranges
+}
+
+func TestDownloadToFile_Resume(t *testing.T) {
+	data := bytes.Repeat([]byte("resumed!"), 128)
+	pieceCID, err := CalculatePieceCID(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(common.Address{}, common.Address{}, nil, nil, 7)
+
+	flaky, ranges := flakyPieceServer(t, data, 400)
+	path := filepath.Join(t.TempDir(), "piece.bin")
+	start := time.Now()
+	n, err := m.DownloadToFile(context.Background(), pieceCID, path, &DownloadOptions{Providers: []string{flaky}, MaxResumes: 2})
+	if err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*resumeBackoffBase {
+		t.Errorf("two resumes took %v, want at least %v of backoff", elapsed, 2*resumeBackoffBase)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("DownloadToFile() wrote %d bytes, want %d", n, len(data))
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Error("file contents do not match the piece")
+	}
+	if want := []string{"", "bytes=400-", "bytes=800-"}; strings.Join(*ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("Range headers = %q, want %q", *ranges, want)
+	}
+
+	// one resume is not enough to get past the second drop
+	flaky, _ = flakyPieceServer(t, data, 400)
+	path = filepath.Join(t.TempDir(), "piece.bin")
+	if _, err := m.DownloadToFile(context.Background(), pieceCID, path, &DownloadOptions{Providers: []string{flaky}, MaxResumes: 1}); err == nil {
+		t.Error("DownloadToFile() expected error once MaxResumes is used up")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("partial download left at %s", path)
+	}
+
+	// without MaxResumes the first drop is fatal
+	flaky, ranges = flakyPieceServer(t, data, 400)
+	if _, err := m.DownloadToFile(context.Background(), pieceCID, path, &DownloadOptions{Providers: []string{flaky}}); err == nil {
+		t.Error("DownloadToFile() expected error without MaxResumes")
+	}
+	if len(*ranges) != 1 {
+		t.Errorf("made %d requests without MaxResumes, want 1", len(*ranges))
+	}
+}
+
+func TestEnsureDataSet_ResumesPendingCreation(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := pdp.NewAuthHelperFromKey(key, common.Address{}, big.NewInt(31337))
+
+	// 0xpending is the earlier creation; 0xretry is submitted if it reverted
+	newServer := func(t *testing.T, pendingOK bool) (*pdp.Server, *int) {
+		creates := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/pdp/data-sets":
+				creates++
+				w.Header().Set("Location", "/pdp/data-sets/created/0xretry")
+				w.WriteHeader(http.StatusCreated)
+			case r.URL.Path == "/pdp/data-sets/created/0xpending":
+				id := 5
+				_ = json.NewEncoder(w).Encode(pdp.DataSetCreationStatus{DataSetCreated: pendingOK, OK: &pendingOK, DataSetID: &id})
+			case r.URL.Path == "/pdp/data-sets/created/0xretry":
+				ok, id := true, 6
+				_ = json.NewEncoder(w).Encode(pdp.DataSetCreationStatus{DataSetCreated: true, OK: &ok, DataSetID: &id})
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return pdp.NewServer(srv.URL), &creates
+	}
+
+	t.Run("creation landed", func(t *testing.T) {
+		server, creates := newServer(t, true)
+		m := NewManager(auth.Address(), common.Address{}, auth, server, 0, WithPendingDataSetCreation("0xpending", big.NewInt(9)))
+		if _, err := m.ensureDataSet(context.Background()); err != nil {
+			t.Fatalf("ensureDataSet() error = %v", err)
+		}
+		if m.DataSetID() != 5 || *creates != 0 {
+			t.Errorf("data set %d after %d creates, want 5 after 0", m.DataSetID(), *creates)
+		}
+		if m.clientDataSetID.Int64() != 9 {
+			t.Errorf("clientDataSetID = %s, want 9", m.clientDataSetID)
+		}
+		if tx, _ := m.PendingDataSetCreation(); tx != "" {
+			t.Errorf("PendingDataSetCreation() = %q, want none", tx)
+		}
+	})
+
+	t.Run("creation reverted", func(t *testing.T) {
+		server, creates := newServer(t, false)
+		m := NewManager(auth.Address(), common.Address{}, auth, server, 0, WithPendingDataSetCreation("0xpending", big.NewInt(9)))
+		if _, err := m.ensureDataSet(context.Background()); err != nil {
+			t.Fatalf("ensureDataSet() error = %v", err)
+		}
+		if m.DataSetID() != 6 || *creates != 1 {
+			t.Errorf("data set %d after %d creates, want 6 after 1", m.DataSetID(), *creates)
+		}
+	})
+}
+
+func TestUseDataSet(t *testing.T) {
+	client := common.HexToAddress("0xd388ab098ed3e84c0d808776440b48f685198498")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	fetcher := fakeDataSetInfoFetcher{info: &warmstorage.DataSetInfo{Payer: client, ClientDataSetID: big.NewInt(42)}}
+	m := NewManager(client, common.Address{}, nil, nil, 7, WithDataSetInfoFetcher(fetcher), WithClientDataSetID(big.NewInt(1)))
+
+	if err := m.UseDataSet(context.Background(), 8); err != nil {
+		t.Fatalf("UseDataSet() error = %v", err)
+	}
+	if m.DataSetID() != 8 || m.clientDataSetID.Int64() != 42 {
+		t.Errorf("bound to data set %d with client ID %s, want 8 with 42", m.DataSetID(), m.clientDataSetID)
+	}
+
+	m.dataSetInfoFetcher = fakeDataSetInfoFetcher{info: &warmstorage.DataSetInfo{Payer: other, ClientDataSetID: big.NewInt(43)}}
+	if err := m.UseDataSet(context.Background(), 9); !errors.Is(err, ErrNotPayer) {
+		t.Fatalf("UseDataSet() error = %v, want ErrNotPayer", err)
+	}
+	if m.DataSetID() != 0 || m.clientDataSetIDLoaded {
+		t.Errorf("after failed rebind: data set %d, loaded %v; want unbound", m.DataSetID(), m.clientDataSetIDLoaded)
+	}
+}
+
+func TestNewManager_SignerMismatch(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := pdp.NewAuthHelperFromKey(key, common.Address{}, big.NewInt(31337))
+
+	if m := NewManager(auth.Address(), common.Address{}, auth, nil, 0); m.Err() != nil {
+		t.Errorf("Err() = %v, want nil for matching addresses", m.Err())
+	}
+
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	m := NewManager(other, common.Address{}, auth, nil, 0)
+	if !errors.Is(m.Err(), ErrSignerMismatch) {
+		t.Fatalf("Err() = %v, want ErrSignerMismatch", m.Err())
+	}
+	data := bytes.Repeat([]byte("mismatch"), 32)
+	if _, err := m.UploadBytes(context.Background(), data, nil); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("UploadBytes() error = %v, want ErrSignerMismatch", err)
+	}
+}
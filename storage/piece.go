@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/bits"
+	"runtime"
+	"sync"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/filecoin-project/go-commp-utils/v2/zerocomm"
+	commcid "github.com/filecoin-project/go-fil-commcid"
+	commphh "github.com/filecoin-project/go-fil-commp-hashhash"
+	"github.com/ipfs/go-cid"
+)
+
+// ErrInvalidPieceSize is returned when data is too small or too large to be
+// uploaded as a single piece.
+var ErrInvalidPieceSize = errors.New("invalid piece size")
+
+// ValidatePieceSize returns the padded piece size that rawSize bytes of data
+// occupy after FR32 padding (127 bytes become 128) rounded up to a power of
+// two. It returns ErrInvalidPieceSize, along with the padded size, if rawSize
+// is outside [constants.MinUploadSize, constants.MaxUploadSize].
+func ValidatePieceSize(rawSize int64) (int64, error) {
+	padded := paddedPieceSize(rawSize)
+	if rawSize < constants.MinUploadSize {
+		return padded, fmt.Errorf("%w: %d bytes is below the minimum of %d bytes (padded size %d)",
+			ErrInvalidPieceSize, rawSize, constants.MinUploadSize, padded)
+	}
+	if rawSize > constants.MaxUploadSize {
+		return padded, fmt.Errorf("%w: %d bytes exceeds the maximum of %d bytes (padded size %d)",
+			ErrInvalidPieceSize, rawSize, int64(constants.MaxUploadSize), padded)
+	}
+	return padded, nil
+}
+
+func paddedPieceSize(rawSize int64) int64 {
+	if rawSize <= 127 {
+		return 128
+	}
+	fr32 := uint64(rawSize+126) / 127 * 128
+	return int64(1) << bits.Len64(fr32-1)
+}
+
+// minParallelSubtree is the smallest padded subtree CalculatePieceCIDParallel
+// hashes on its own; below this the goroutine overhead outweighs the gain.
+const minParallelSubtree = 1 << 20
+
+// CalculatePieceCIDParallel computes the same piece CID as CalculatePieceCID
+// using up to workers goroutines (runtime.NumCPU() if workers <= 0).
+//
+// The input is split into equal power-of-two subtrees of the CommP merkle
+// tree, each hashed directly from data without copying, and the subtree roots
+// are then combined. Inputs too small to split fall back to CalculatePieceCID.
+func CalculatePieceCIDParallel(data []byte, workers int) (cid.Cid, error) {
+	return calculatePieceCIDParallel(data, workers, minParallelSubtree)
+}
+
+func calculatePieceCIDParallel(data []byte, workers int, minSubtree uint64) (cid.Cid, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// pick the largest padded subtree size that still yields at least one
+	// subtree per worker
+	perWorker := uint64(len(data)+workers-1) / uint64(workers) * 128 / 127
+	subtreePadded := minSubtree
+	if perWorker > minSubtree {
+		subtreePadded = uint64(1) << (bits.Len64(perWorker) - 1)
+	}
+	subtreeRaw := int(subtreePadded / 128 * 127)
+
+	if workers == 1 || len(data) <= subtreeRaw {
+		return CalculatePieceCID(data)
+	}
+
+	n := (len(data) + subtreeRaw - 1) / subtreeRaw
+	roots := make([][]byte, n)
+	errs := make([]error, n)
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		end := (i + 1) * subtreeRaw
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[i*subtreeRaw : end]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			roots[i], errs[i] = subtreeCommP(chunk, subtreePadded)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return cid.Undef, fmt.Errorf("failed to calculate CommP of subtree %d: %w", i, err)
+		}
+	}
+
+	// fill up to a power-of-two number of subtrees with zero subtrees
+	zero := zerocomm.PieceComms[bits.TrailingZeros64(subtreePadded)-7]
+	for len(roots)&(len(roots)-1) != 0 {
+		roots = append(roots, zero[:])
+	}
+
+	for len(roots) > 1 {
+		next := roots[:len(roots)/2]
+		for i := range next {
+			next[i] = hashPair(roots[2*i], roots[2*i+1])
+		}
+		roots = next
+	}
+
+	return commcid.PieceCommitmentV1ToCID(roots[0])
+}
+
+// subtreeCommP returns the CommP of chunk zero-padded to a subtree of
+// paddedSize bytes.
+func subtreeCommP(chunk []byte, paddedSize uint64) ([]byte, error) {
+	var calc commphh.Calc
+	if _, err := calc.Write(chunk); err != nil {
+		return nil, err
+	}
+	if len(chunk) < int(commphh.MinPiecePayload) {
+		if _, err := calc.Write(make([]byte, int(commphh.MinPiecePayload)-len(chunk))); err != nil {
+			return nil, err
+		}
+	}
+
+	commP, size, err := calc.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if size == paddedSize {
+		return commP, nil
+	}
+	return commphh.PadCommP(commP, size, paddedSize)
+}
+
+// hashPair is the CommP node hash: sha256 of both children with the two most
+// significant bits of the result cleared so it stays within the field.
+func hashPair(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+	out := h.Sum(nil)
+	out[31] &= 0x3f
+	return out
+}
@@ -1,8 +1,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ipfs/go-cid"
 )
 
@@ -54,6 +58,21 @@ func TestCalculatePieceCID_ZeroData(t *testing.T) {
 	}
 }
 
+func TestCalculatePieceCIDWithSizes(t *testing.T) {
+	for _, fixture := range zeroPieceCidFixtures {
+		pieceCID, rawSize, paddedSize, err := CalculatePieceCIDWithSizes(make([]byte, fixture.RawSize))
+		if err != nil {
+			t.Fatalf("CalculatePieceCIDWithSizes failed for size %d: %v", fixture.RawSize, err)
+		}
+		if pieceCID.String() != fixture.V1PieceCID {
+			t.Errorf("PieceCID for size %d = %s, want %s", fixture.RawSize, pieceCID, fixture.V1PieceCID)
+		}
+		if rawSize != int64(fixture.RawSize) || paddedSize != int64(fixture.PaddedSize) {
+			t.Errorf("sizes for %d bytes = (%d, %d), want (%d, %d)", fixture.RawSize, rawSize, paddedSize, fixture.RawSize, fixture.PaddedSize)
+		}
+	}
+}
+
 func TestCalculatePieceCID_NonZeroData(t *testing.T) {
 	data1 := []byte("Hello, World!")
 	data2 := []byte("Hello, World?")
@@ -126,3 +145,104 @@ func TestCalculatePieceCID_EmptyData(t *testing.T) {
 		t.Error("Expected error for empty data, but got nil")
 	}
 }
+
+func TestCalculatePieceCIDParallel_MatchesSerial(t *testing.T) {
+	// a tiny subtree size exercises splitting, short tails and zero-subtree
+	// filling without hashing megabytes of data
+	sizes := []int{65, 127, 128, 254, 300, 508, 1000, 1016, 1017, 4096, 10000}
+	for _, size := range sizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*7 + 3)
+		}
+
+		want, err := CalculatePieceCID(data)
+		if err != nil {
+			t.Fatalf("CalculatePieceCID(%d bytes): %v", size, err)
+		}
+
+		for _, workers := range []int{2, 3, 8} {
+			got, err := calculatePieceCIDParallel(data, workers, 128)
+			if err != nil {
+				t.Fatalf("calculatePieceCIDParallel(%d bytes, %d workers): %v", size, workers, err)
+			}
+			if !got.Equals(want) {
+				t.Errorf("size %d, %d workers: got %s, want %s", size, workers, got, want)
+			}
+		}
+	}
+}
+
+func TestCalculatePieceCIDParallel_ZeroFixtures(t *testing.T) {
+	for _, fixture := range zeroPieceCidFixtures {
+		got, err := calculatePieceCIDParallel(make([]byte, fixture.RawSize), 4, 128)
+		if err != nil {
+			t.Fatalf("size %d: %v", fixture.RawSize, err)
+		}
+		if got.String() != fixture.V1PieceCID {
+			t.Errorf("size %d: got %s, want %s", fixture.RawSize, got, fixture.V1PieceCID)
+		}
+	}
+}
+
+func TestCalculatePieceCIDParallel_EmptyData(t *testing.T) {
+	if _, err := CalculatePieceCIDParallel(nil, 4); err == nil {
+		t.Error("Expected error for empty data, but got nil")
+	}
+}
+
+func benchmarkPieceCID(b *testing.B, fn func([]byte) (cid.Cid, error)) {
+	data := make([]byte, 1<<30)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fn(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalculatePieceCID_1GiB(b *testing.B) {
+	benchmarkPieceCID(b, CalculatePieceCID)
+}
+
+func BenchmarkCalculatePieceCIDParallel_1GiB(b *testing.B) {
+	benchmarkPieceCID(b, func(data []byte) (cid.Cid, error) {
+		return CalculatePieceCIDParallel(data, 0)
+	})
+}
+
+func TestValidatePieceSize(t *testing.T) {
+	for _, fixture := range zeroPieceCidFixtures {
+		padded, err := ValidatePieceSize(int64(fixture.RawSize))
+		if padded != int64(fixture.PaddedSize) {
+			t.Errorf("ValidatePieceSize(%d) padded = %d, want %d", fixture.RawSize, padded, fixture.PaddedSize)
+		}
+		if tooSmall := fixture.RawSize < constants.MinUploadSize; tooSmall != (err != nil) {
+			t.Errorf("ValidatePieceSize(%d) error = %v", fixture.RawSize, err)
+		}
+	}
+
+	padded, err := ValidatePieceSize(constants.MaxUploadSize)
+	if err != nil || padded != constants.GiB {
+		t.Errorf("ValidatePieceSize(max) = %d, %v; want %d, nil", padded, err, constants.GiB)
+	}
+
+	padded, err = ValidatePieceSize(constants.MaxUploadSize + 1)
+	if !errors.Is(err, ErrInvalidPieceSize) {
+		t.Errorf("ValidatePieceSize(max+1) error = %v, want ErrInvalidPieceSize", err)
+	}
+	if padded != 2*constants.GiB {
+		t.Errorf("ValidatePieceSize(max+1) padded = %d, want %d", padded, 2*constants.GiB)
+	}
+}
+
+func TestUploadBytes_RejectsInvalidSize(t *testing.T) {
+	m := NewManager(common.Address{}, common.Address{}, nil, nil, 0)
+	if _, err := m.UploadBytes(context.Background(), make([]byte, 10), nil); !errors.Is(err, ErrInvalidPieceSize) {
+		t.Errorf("UploadBytes() error = %v, want ErrInvalidPieceSize", err)
+	}
+}
@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ipfs/go-cid"
 )
 
@@ -9,13 +13,84 @@ type UploadResult struct {
 	Size      int64
 	PieceID   int
 	DataSetID int
+	// TxHash is the add-pieces transaction that put the piece on-chain.
+	TxHash string
+	// DataSetCreationTxHash is the data set creation transaction when this
+	// upload created the data set, and "" otherwise.
+	DataSetCreationTxHash string
 }
 
 type UploadOptions struct {
 	Metadata map[string]string
+	// PieceCID is the precomputed CommP of the data. When set together with
+	// Size, Upload streams the reader straight to the provider with no
+	// buffering and no CommP recomputation.
 	PieceCID cid.Cid
+	// Size is the raw (unpadded) byte length of the data.
 	Size     int64  
+	// Verify recomputes the CommP while uploading and fails with
+	// ErrPieceCIDMismatch, before the piece is added to the data set, if it
+	// differs from PieceCID or if the data length differs from Size.
+	Verify bool
 }
 
 type DownloadOptions struct {
+	// Providers are fallback provider base URLs, tried in order when the
+	// manager's own provider fails or serves data that does not match the
+	// PieceCID.
+	Providers []string
+	// ExpectedSize is the raw piece size the download must match; a source
+	// sending a truncated or padded body fails with ErrSizeMismatch. Zero
+	// disables the check.
+	ExpectedSize int64
+	// MaxResumes is how many times DownloadToFile picks a source's transfer
+	// back up with a Range request after the connection drops mid-piece,
+	// before giving up on that source. Zero disables resuming.
+	MaxResumes int
+}
+
+// PieceStat describes a piece as seen by the storage provider. Size is -1
+// when the provider does not report it.
+type PieceStat struct {
+	Exists   bool
+	Size     int64
+	Provider string
+}
+
+// ProviderRef is a provider that advertises a piece to IPNI. ServiceURL is
+// the HTTP endpoint taken from its advertised addresses, suitable for
+// DownloadOptions.Providers, or "" if it advertises none.
+type ProviderRef struct {
+	PeerID     string
+	Addrs      []string
+	ServiceURL string
+}
+
+// UploadEstimate is a rough forecast of an upload's cost and duration,
+// meant for confirmation before committing funds and bandwidth. Costs are
+// in the units of PaymentToken and exclude fees and lockup.
+type UploadEstimate struct {
+	TotalBytes int64
+	// Pieces is how many pieces the data is split into, each at most
+	// constants.MaxUploadSize bytes.
+	Pieces int
+	// PaddedBytes is the total padded size of the pieces, which the
+	// provider's price is applied to.
+	PaddedBytes    int64
+	RatePerEpoch   *big.Int
+	RatePerDay     *big.Int
+	DurationEpochs *big.Int
+	// TotalCost is RatePerEpoch over DurationEpochs.
+	TotalCost    *big.Int
+	PaymentToken common.Address
+	// TransferTime assumes EstimateUploadBandwidth; ConfirmationTime
+	// assumes each piece waits EstimateConfirmationEpochs on chain, one
+	// piece after another.
+	TransferTime     time.Duration
+	ConfirmationTime time.Duration
+}
+
+// Duration is the estimated wall-clock time of the whole upload.
+func (e *UploadEstimate) Duration() time.Duration {
+	return e.TransferTime + e.ConfirmationTime
 }
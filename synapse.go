@@ -5,24 +5,65 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"net/url"
+	"strings"
 
 	"github.com/data-preservation-programs/go-synapse/constants"
 	"github.com/data-preservation-programs/go-synapse/costs"
 	"github.com/data-preservation-programs/go-synapse/pdp"
+	"github.com/data-preservation-programs/go-synapse/pkg/ratelimit"
+	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
 	"github.com/data-preservation-programs/go-synapse/storage"
 	"github.com/data-preservation-programs/go-synapse/warmstorage"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type Options struct {
 	PrivateKey *ecdsa.PrivateKey
 
+	// RPCURL is the Filecoin EVM RPC endpoint. It may be left empty when
+	// Network is set, in which case RPCURLs[Network] is used.
 	RPCURL string
 
+	// RPCClient, if set, is used instead of dialing RPCURL, so callers can
+	// tune the transport (connection reuse, timeouts) for busy services. The
+	// caller owns it; Client.Close does not close it. See the README for
+	// settings suited to the rate-limited public Glif endpoints.
+	RPCClient *rpc.Client
+
+	// RPCRateLimit, if positive, caps requests to an http(s) RPCURL at this
+	// many per second, allowing bursts of RPCBurst (default 1), so bulk
+	// operations stay under provider limits instead of failing with 429.
+	// It is ignored when RPCClient is set; use pkg/ratelimit directly there.
+	RPCRateLimit float64
+	RPCBurst     int
+
+	// Network is the expected network. When set, New fails if the RPC
+	// endpoint serves a different chain.
+	Network Network
+
 	WarmStorageAddress common.Address
 
+	// Contract address overrides for custom deployments. Zero values use the
+	// network's built-in (or FWSS-resolved) addresses.
+	PDPVerifierAddress          common.Address
+	PaymentsAddress             common.Address
+	USDFCAddress                common.Address
+	SPRegistryAddress           common.Address
+	WarmStorageStateViewAddress common.Address
+
+	// SigningChainID overrides the chainId of the WarmStorage EIP-712
+	// domain used for storage authorizations. Leave it zero to use the
+	// connected chain's ID, which is correct for Filecoin mainnet,
+	// calibration and devnet. Set it only when the deployed contract pins its
+	// domain to a different chainId than the RPC reports (e.g. a fork or an
+	// FEVM-compatible L2 that reuses a contract built for another chain);
+	// transactions are still sent with the connected chain ID.
+	SigningChainID int64
+
 	ProviderURL string
 
 	DataSetID int
@@ -31,34 +72,133 @@ type Options struct {
 type Client struct {
 	network            Network
 	chainID            int64
+	signingChainID     int64
 	ethClient          *ethclient.Client
+	ownsClient         bool
 	privateKey         *ecdsa.PrivateKey
 	address            common.Address
 	warmStorageAddress common.Address
+	pdpVerifierAddress common.Address
+	paymentsAddress    common.Address
+	usdfcAddress       common.Address
+	spRegistryAddress  common.Address
+	stateViewAddress   common.Address
 	storageManager     *storage.Manager
 	costsService       *costs.Service
 	providerURL        string
 	dataSetID          int
 }
 
-func New(ctx context.Context, opts Options) (*Client, error) {
-	if opts.PrivateKey == nil {
-		return nil, fmt.Errorf("private key is required")
+// Validate checks the options for missing or inconsistent settings without
+// contacting the network. New calls it first.
+func (o Options) Validate() error {
+	if o.PrivateKey == nil {
+		return fmt.Errorf("private key is required")
 	}
-	if opts.RPCURL == "" {
-		return nil, fmt.Errorf("RPC URL is required")
+	if o.Network != "" {
+		if _, ok := constants.NetworkChainIDs[o.Network]; !ok {
+			return fmt.Errorf("unknown network %q", o.Network)
+		}
+	}
+	if o.RPCClient == nil {
+		rpcURL := o.rpcURL()
+		if rpcURL == "" {
+			return fmt.Errorf("RPC URL is required (or set Network to use its default endpoint, or RPCClient)")
+		}
+		if err := validateURL(rpcURL, "http", "https", "ws", "wss"); err != nil {
+			return fmt.Errorf("invalid RPC URL: %w", err)
+		}
 	}
+	if o.RPCRateLimit < 0 || o.RPCBurst < 0 {
+		return fmt.Errorf("RPC rate limit and burst must not be negative")
+	}
+	if o.ProviderURL != "" {
+		if err := validateURL(o.ProviderURL, "http", "https"); err != nil {
+			return fmt.Errorf("invalid provider URL: %w", err)
+		}
+	}
+	if o.SigningChainID < 0 {
+		return fmt.Errorf("signing chain ID must not be negative, got %d", o.SigningChainID)
+	}
+	if o.DataSetID < 0 {
+		return fmt.Errorf("data set ID must not be negative, got %d", o.DataSetID)
+	}
+	if o.DataSetID != 0 && o.ProviderURL == "" {
+		return fmt.Errorf("data set ID %d is only used for storage; set ProviderURL to the provider that holds it", o.DataSetID)
+	}
+	return nil
+}
 
-	ethClient, err := ethclient.DialContext(ctx, opts.RPCURL)
+// rpcURL returns RPCURL, falling back to the default endpoint for Network.
+func (o Options) rpcURL() string {
+	if o.RPCURL != "" || o.Network == "" {
+		return o.RPCURL
+	}
+	return RPCURLs[o.Network]
+}
+
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		return err
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			if u.Host == "" {
+				return fmt.Errorf("%q has no host", raw)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must use one of the schemes %s", raw, strings.Join(schemes, ", "))
+}
+
+// dialRPC dials the configured RPC URL, throttling http(s) requests when
+// RPCRateLimit is set.
+func dialRPC(ctx context.Context, opts Options) (*rpc.Client, error) {
+	rpcURL := opts.rpcURL()
+	if opts.RPCRateLimit <= 0 || !strings.HasPrefix(rpcURL, "http") {
+		return rpc.DialContext(ctx, rpcURL)
+	}
+	burst := opts.RPCBurst
+	if burst == 0 {
+		burst = 1
+	}
+	httpClient := ratelimit.NewHTTPClient(nil, opts.RPCRateLimit, burst)
+	return rpc.DialOptions(ctx, rpcURL, rpc.WithHTTPClient(httpClient))
+}
+
+func New(ctx context.Context, opts Options) (*Client, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var ethClient *ethclient.Client
+	ownsClient := opts.RPCClient == nil
+	if ownsClient {
+		rpcClient, err := dialRPC(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		}
+		ethClient = ethclient.NewClient(rpcClient)
+	} else {
+		ethClient = ethclient.NewClient(opts.RPCClient)
+	}
+	release := func() {
+		if ownsClient {
+			ethClient.Close()
+		}
 	}
 
 	network, chainID, err := DetectNetwork(ctx, ethClient)
 	if err != nil {
-		ethClient.Close()
+		release()
 		return nil, fmt.Errorf("failed to detect network: %w", err)
 	}
+	if opts.Network != "" && opts.Network != network {
+		release()
+		return nil, fmt.Errorf("RPC endpoint serves %s (chain ID %d), expected %s", network, chainID, opts.Network)
+	}
 
 	warmStorageAddr := opts.WarmStorageAddress
 	if warmStorageAddr == (common.Address{}) {
@@ -68,33 +208,58 @@ func New(ctx context.Context, opts Options) (*Client, error) {
 	// for networks without static addresses (e.g. devnet), resolve from FWSS at runtime
 	if _, ok := constants.PDPVerifierAddresses[constants.Network(network)]; !ok {
 		if warmStorageAddr == (common.Address{}) {
-			ethClient.Close()
+			release()
 			return nil, fmt.Errorf("network %s has no built-in addresses; set WarmStorageAddress (FWSS) to resolve at runtime", network)
 		}
 		addrs, err := constants.ResolveFromFWSS(ctx, ethClient, warmStorageAddr)
 		if err != nil {
-			ethClient.Close()
+			release()
 			return nil, fmt.Errorf("failed to resolve addresses from FWSS on %s: %w", network, err)
 		}
 		constants.RegisterNetwork(constants.Network(network), addrs)
 	}
 
 	address := crypto.PubkeyToAddress(opts.PrivateKey.PublicKey)
+	n := constants.Network(network)
+
+	stateViewAddr := addressOr(opts.WarmStorageStateViewAddress, constants.WarmStorageStateViewAddresses[n])
+	if opts.DataSetID != 0 && stateViewAddr == (common.Address{}) {
+		release()
+		return nil, fmt.Errorf("data set ID %d requires the FWSS state view, which is unknown on %s; set WarmStorageStateViewAddress", opts.DataSetID, network)
+	}
 
 	client := &Client{
 		network:            network,
 		chainID:            chainID,
+		signingChainID:     chainID,
 		ethClient:          ethClient,
+		ownsClient:         ownsClient,
 		privateKey:         opts.PrivateKey,
 		address:            address,
 		warmStorageAddress: warmStorageAddr,
+		pdpVerifierAddress: addressOr(opts.PDPVerifierAddress, constants.PDPVerifierAddresses[n]),
+		paymentsAddress:    addressOr(opts.PaymentsAddress, constants.PaymentsAddresses[n]),
+		usdfcAddress:       addressOr(opts.USDFCAddress, constants.USDFCAddresses[n]),
+		spRegistryAddress:  addressOr(opts.SPRegistryAddress, constants.SPRegistryAddresses[n]),
+		stateViewAddress:   stateViewAddr,
 		providerURL:        opts.ProviderURL,
 		dataSetID:          opts.DataSetID,
 	}
 
+	if opts.SigningChainID != 0 {
+		client.signingChainID = opts.SigningChainID
+	}
+
 	return client, nil
 }
 
+func addressOr(override, fallback common.Address) common.Address {
+	if override != (common.Address{}) {
+		return override
+	}
+	return fallback
+}
+
 func (c *Client) Network() Network {
 	return c.network
 }
@@ -103,6 +268,13 @@ func (c *Client) ChainID() int64 {
 	return c.chainID
 }
 
+// SigningChainID returns the chainId of the EIP-712 domain the client signs
+// storage authorizations with. It equals ChainID unless
+// Options.SigningChainID was set.
+func (c *Client) SigningChainID() int64 {
+	return c.signingChainID
+}
+
 func (c *Client) Address() common.Address {
 	return c.address
 }
@@ -111,6 +283,33 @@ func (c *Client) WarmStorageAddress() common.Address {
 	return c.warmStorageAddress
 }
 
+// PDPVerifierAddress returns the PDPVerifier contract the client uses.
+func (c *Client) PDPVerifierAddress() common.Address {
+	return c.pdpVerifierAddress
+}
+
+// PaymentsAddress returns the Payments contract the client uses.
+func (c *Client) PaymentsAddress() common.Address {
+	return c.paymentsAddress
+}
+
+// USDFCAddress returns the USDFC token contract the client uses.
+func (c *Client) USDFCAddress() common.Address {
+	return c.usdfcAddress
+}
+
+// SPRegistryAddress returns the service provider registry contract the client
+// uses.
+func (c *Client) SPRegistryAddress() common.Address {
+	return c.spRegistryAddress
+}
+
+// WarmStorageStateViewAddress returns the FWSS state view contract the client
+// uses.
+func (c *Client) WarmStorageStateViewAddress() common.Address {
+	return c.stateViewAddress
+}
+
 func (c *Client) EthClient() *ethclient.Client {
 	return c.ethClient
 }
@@ -124,16 +323,18 @@ func (c *Client) Storage() (*storage.Manager, error) {
 		return nil, fmt.Errorf("provider URL is required for storage operations")
 	}
 
-	authHelper := pdp.NewAuthHelperFromKey(c.privateKey, c.warmStorageAddress, big.NewInt(c.chainID))
+	authHelper := pdp.NewAuthHelperFromKey(c.privateKey, c.warmStorageAddress, big.NewInt(c.signingChainID))
 	pdpServer := pdp.NewServer(c.providerURL)
 
 	var opts []storage.ManagerOption
 	if c.dataSetID != 0 {
-		stateViewAddr := constants.WarmStorageStateViewAddresses[constants.Network(c.network)]
-		stateView, err := warmstorage.NewStateViewContract(stateViewAddr, c.ethClient)
+		stateView, err := warmstorage.NewStateViewContract(c.stateViewAddress, c.ethClient)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create state view contract: %w", err)
 		}
+		if addr, ok := constants.Multicall3Addresses[constants.Network(c.network)]; ok {
+			stateView.SetMulticall3Address(addr)
+		}
 		opts = append(opts, storage.WithDataSetInfoFetcher(stateView))
 	}
 
@@ -156,9 +357,14 @@ func (c *Client) Costs() (*costs.Service, error) {
 		return c.costsService, nil
 	}
 
-	config, err := costs.NetworkConfig(constants.Network(c.network))
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve costs config: %w", err)
+	config := costs.ServiceConfig{
+		FWSSAddress:        c.warmStorageAddress,
+		PDPVerifierAddress: c.pdpVerifierAddress,
+		PaymentsAddress:    c.paymentsAddress,
+		USDFCAddress:       c.usdfcAddress,
+	}
+	if config.PDPVerifierAddress == (common.Address{}) || config.PaymentsAddress == (common.Address{}) || config.USDFCAddress == (common.Address{}) {
+		return nil, fmt.Errorf("failed to resolve costs config: missing contract addresses for network %s", c.network)
 	}
 
 	svc, err := costs.NewService(c.ethClient, c.chainID, config)
@@ -185,14 +391,23 @@ func (c *Client) GetUploadCosts(
 	return svc.GetUploadCosts(ctx, c.address, dataSetSizeBytes, uploadSizeBytes, opts)
 }
 
+// TxStatus reports what happened to a submitted transaction.
+type TxStatus = txutil.TxStatus
+
+// TransactionStatus reports whether txHash is pending, succeeded or reverted,
+// including the revert reason when the node can reproduce it.
+func (c *Client) TransactionStatus(ctx context.Context, txHash common.Hash) (*TxStatus, error) {
+	return txutil.GetTxStatus(ctx, c.ethClient, txHash)
+}
+
 func (c *Client) Close() {
-	if c.ethClient != nil {
+	if c.ethClient != nil && c.ownsClient {
 		c.ethClient.Close()
 	}
 }
 
 func (c *Client) NewAuthHelper() *pdp.AuthHelper {
-	return pdp.NewAuthHelperFromKey(c.privateKey, c.warmStorageAddress, big.NewInt(c.chainID))
+	return pdp.NewAuthHelperFromKey(c.privateKey, c.warmStorageAddress, big.NewInt(c.signingChainID))
 }
 
 func (c *Client) NewPDPServer(providerURL string) *pdp.Server {
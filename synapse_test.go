@@ -0,0 +1,184 @@
+package synapse
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// chainIDAPI serves eth_chainId, and eth_getCode from code, for an
+// in-process RPC server.
+type chainIDAPI struct {
+	chainID int64
+	code    map[common.Address][]byte
+}
+
+func (a *chainIDAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(a.chainID))
+}
+
+func (a *chainIDAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return a.code[addr]
+}
+
+func TestNew_WithRPCClient(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &chainIDAPI{chainID: ChainIDCalibration}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	client, err := New(context.Background(), Options{PrivateKey: key, RPCClient: rpcClient})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.Network() != NetworkCalibration {
+		t.Errorf("Network() = %s, want %s", client.Network(), NetworkCalibration)
+	}
+
+	// the caller's RPC client stays usable after Close
+	client.Close()
+	if _, err := ethclient.NewClient(rpcClient).ChainID(context.Background()); err != nil {
+		t.Errorf("RPC client unusable after Close: %v", err)
+	}
+
+	if _, err := New(context.Background(), Options{PrivateKey: key, RPCClient: rpcClient, Network: NetworkMainnet}); err == nil {
+		t.Error("New() expected error for network mismatch")
+	}
+}
+
+func TestNew_RPCRateLimit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &chainIDAPI{chainID: ChainIDCalibration}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	client, err := New(context.Background(), Options{PrivateKey: key, RPCURL: httpSrv.URL, RPCRateLimit: 100})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+	if client.Network() != NetworkCalibration {
+		t.Errorf("Network() = %s, want %s", client.Network(), NetworkCalibration)
+	}
+}
+
+func TestNew_SigningChainID(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &chainIDAPI{chainID: ChainIDCalibration}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	client, err := New(context.Background(), Options{PrivateKey: key, RPCClient: rpcClient})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.SigningChainID() != ChainIDCalibration {
+		t.Errorf("SigningChainID() = %d, want connected chain %d", client.SigningChainID(), ChainIDCalibration)
+	}
+
+	client, err = New(context.Background(), Options{PrivateKey: key, RPCClient: rpcClient, SigningChainID: 31337})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.ChainID() != ChainIDCalibration {
+		t.Errorf("ChainID() = %d, want %d", client.ChainID(), ChainIDCalibration)
+	}
+	if got := client.NewAuthHelper().ChainID().Int64(); got != 31337 {
+		t.Errorf("auth helper domain chain ID = %d, want 31337", got)
+	}
+}
+
+func TestOptionsRPCURL(t *testing.T) {
+	o := Options{Network: NetworkMainnet}
+	if got := o.rpcURL(); got != RPCURLs[NetworkMainnet] {
+		t.Errorf("rpcURL() = %q, want %q", got, RPCURLs[NetworkMainnet])
+	}
+	o.RPCURL = "http://localhost:1234/rpc/v1"
+	if got := o.rpcURL(); got != o.RPCURL {
+		t.Errorf("rpcURL() = %q, want explicit %q", got, o.RPCURL)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := Options{PrivateKey: key, RPCURL: "https://api.calibration.node.glif.io/rpc/v1"}
+
+	tests := []struct {
+		name    string
+		modify  func(o *Options)
+		wantErr bool
+	}{
+		{"minimal", func(o *Options) {}, false},
+		{"with storage", func(o *Options) { o.ProviderURL = "https://sp.example.com"; o.DataSetID = 3 }, false},
+		{"websocket RPC", func(o *Options) { o.RPCURL = "wss://example.com/rpc" }, false},
+		{"missing key", func(o *Options) { o.PrivateKey = nil }, true},
+		{"missing RPC URL", func(o *Options) { o.RPCURL = "" }, true},
+		{"default RPC URL for network", func(o *Options) { o.RPCURL = ""; o.Network = NetworkCalibration }, false},
+		{"rate limit", func(o *Options) { o.RPCRateLimit = 5; o.RPCBurst = 10 }, false},
+		{"negative rate limit", func(o *Options) { o.RPCRateLimit = -1 }, true},
+		{"unknown network", func(o *Options) { o.Network = "testnet" }, true},
+		{"RPC URL without scheme", func(o *Options) { o.RPCURL = "api.node.glif.io" }, true},
+		{"provider URL scheme", func(o *Options) { o.ProviderURL = "ftp://sp.example.com" }, true},
+		{"negative signing chain ID", func(o *Options) { o.SigningChainID = -1 }, true},
+		{"negative data set", func(o *Options) { o.ProviderURL = "https://sp.example.com"; o.DataSetID = -1 }, true},
+		{"data set without provider", func(o *Options) { o.DataSetID = 3 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid
+			tt.modify(&o)
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNetworkFromChainID(t *testing.T) {
+	for _, network := range []Network{NetworkMainnet, NetworkCalibration, NetworkDevnet} {
+		chainID := constants.NetworkChainIDs[network]
+		got, id, err := NetworkFromChainID(big.NewInt(chainID))
+		if err != nil || got != network || id != chainID {
+			t.Errorf("NetworkFromChainID(%d) = %q, %d, %v; want %q", chainID, got, id, err, network)
+		}
+	}
+	if _, _, err := NetworkFromChainID(big.NewInt(1)); err == nil {
+		t.Error("NetworkFromChainID(1) expected error for unsupported chain")
+	}
+}
@@ -0,0 +1,64 @@
+package synapse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MissingContract names a contract VerifyContracts found no code for.
+type MissingContract struct {
+	Name    string
+	Address common.Address
+}
+
+// MissingContractsError is returned by VerifyContracts when one or more of
+// the configured contract addresses is unset or holds no code.
+type MissingContractsError struct {
+	Network Network
+	Missing []MissingContract
+}
+
+func (e *MissingContractsError) Error() string {
+	parts := make([]string, len(e.Missing))
+	for i, m := range e.Missing {
+		parts[i] = fmt.Sprintf("%s (%s)", m.Name, m.Address.Hex())
+	}
+	return fmt.Sprintf("no contract code on %s for: %s", e.Network, strings.Join(parts, ", "))
+}
+
+// VerifyContracts checks that the WarmStorage, Payments, SPRegistry and
+// PDPVerifier addresses the client resolved hold deployed code. Call it after
+// New to catch a stale constant, a mistyped override or a network that is not
+// deployed yet before it surfaces as an opaque revert. A *MissingContractsError
+// lists every address without code.
+func (c *Client) VerifyContracts(ctx context.Context) error {
+	contracts := []MissingContract{
+		{"WarmStorage", c.warmStorageAddress},
+		{"Payments", c.paymentsAddress},
+		{"SPRegistry", c.spRegistryAddress},
+		{"PDPVerifier", c.pdpVerifierAddress},
+	}
+
+	var missing []MissingContract
+	for _, contract := range contracts {
+		if contract.Address == (common.Address{}) {
+			missing = append(missing, contract)
+			continue
+		}
+		code, err := c.ethClient.CodeAt(ctx, contract.Address, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get code for %s at %s: %w", contract.Name, contract.Address.Hex(), err)
+		}
+		if len(code) == 0 {
+			missing = append(missing, contract)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingContractsError{Network: c.network, Missing: missing}
+	}
+	return nil
+}
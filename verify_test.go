@@ -0,0 +1,51 @@
+package synapse
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestVerifyContracts(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &chainIDAPI{chainID: ChainIDCalibration, code: map[common.Address][]byte{}}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	stale := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	client, err := New(context.Background(), Options{PrivateKey: key, RPCClient: rpcClient, PaymentsAddress: stale})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, addr := range []common.Address{client.WarmStorageAddress(), client.SPRegistryAddress(), client.PDPVerifierAddress()} {
+		api.code[addr] = []byte{0x60, 0x80}
+	}
+
+	err = client.VerifyContracts(context.Background())
+	var missingErr *MissingContractsError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("VerifyContracts() error = %v, want *MissingContractsError", err)
+	}
+	if len(missingErr.Missing) != 1 || missingErr.Missing[0].Name != "Payments" || missingErr.Missing[0].Address != stale {
+		t.Errorf("Missing = %+v, want only Payments at %s", missingErr.Missing, stale.Hex())
+	}
+
+	api.code[stale] = []byte{0x60, 0x80}
+	if err := client.VerifyContracts(context.Background()); err != nil {
+		t.Errorf("VerifyContracts() error = %v, want nil", err)
+	}
+}
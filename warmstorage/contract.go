@@ -2,10 +2,19 @@ package warmstorage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/data-preservation-programs/go-synapse/internal/multicall"
+	"github.com/data-preservation-programs/go-synapse/internal/retry"
+	"github.com/data-preservation-programs/go-synapse/pkg/callopt"
+	"github.com/data-preservation-programs/go-synapse/pkg/txutil"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -37,42 +46,297 @@ const StateViewABIJSON = `[
 			}
 		],
 		"stateMutability": "view"
-	}
+	},
+	{
+		"type": "function",
+		"name": "clientDataSets",
+		"inputs": [{"name": "payer", "type": "address"}],
+		"outputs": [{"name": "dataSetIds", "type": "uint256[]"}],
+		"stateMutability": "view"
+	},` + fwssErrorsABIJSON + `
 ]`
 
+// ErrDataSetNotExist is returned when the requested data set ID has no
+// data set on-chain.
+var ErrDataSetNotExist = errors.New("data set does not exist")
+
+// dataSetInfoTuple mirrors the getDataSet return tuple.
+type dataSetInfoTuple struct {
+	PdpRailId       *big.Int
+	CacheMissRailId *big.Int
+	CdnRailId       *big.Int
+	Payer           common.Address
+	Payee           common.Address
+	ServiceProvider common.Address
+	CommissionBps   *big.Int
+	ClientDataSetId *big.Int
+	PdpEndEpoch     *big.Int
+	ProviderId      *big.Int
+	DataSetId       *big.Int
+}
+
 type StateViewContract struct {
-	address common.Address
-	abi     abi.ABI
-	client  *ethclient.Client
+	address   common.Address
+	abi       abi.ABI
+	client    *ethclient.Client
+	multicall multicall.Probe
+	retry     retry.Config
+
+	multicallMu      sync.Mutex
+	multicallAddress common.Address
+	multicallKnown   bool // whether multicallAddress is set or detected
+}
+
+// defaultReadRetries and defaultReadRetryInterval bound how long a state
+// view read keeps retrying transient RPC errors: about 3.5s in total.
+const (
+	defaultReadRetries       = 3
+	defaultReadRetryInterval = 500 * time.Millisecond
+)
+
+func readRetryConfig(maxRetries int, initialInterval time.Duration) retry.Config {
+	return retry.Config{
+		MaxRetries:      maxRetries,
+		InitialInterval: initialInterval,
+		MaxInterval:     10 * initialInterval,
+		Multiplier:      2,
+		Retryable:       txutil.IsRetryableError,
+	}
 }
 
+// NewStateViewContract binds the state view at address. GetDataSets batches
+// through the Multicall3 deployment for the client's chain, detected on first
+// use; on a chain without a known one, data sets are read one call at a time
+// unless SetMulticall3Address is used.
 func NewStateViewContract(address common.Address, client *ethclient.Client) (*StateViewContract, error) {
 	parsedABI, err := abi.JSON(strings.NewReader(StateViewABIJSON))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse StateView ABI: %w", err)
 	}
 
+	if client != nil {
+		if err := contracts.CheckDeployed(context.Background(), client, "WarmStorage state view", address); err != nil {
+			return nil, err
+		}
+	}
+
 	return &StateViewContract{
 		address: address,
 		abi:     parsedABI,
 		client:  client,
+		retry:   readRetryConfig(defaultReadRetries, defaultReadRetryInterval),
 	}, nil
 }
 
-func (c *StateViewContract) GetDataSet(ctx context.Context, dataSetID int) (*DataSetInfo, error) {
+func (c *StateViewContract) GetDataSet(ctx context.Context, dataSetID int, opts ...callopt.Option) (*DataSetInfo, error) {
 	data, err := c.abi.Pack("getDataSet", big.NewInt(int64(dataSetID)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack getDataSet call: %w", err)
 	}
 
-	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
-		To:   &c.address,
-		Data: data,
-	}, nil)
+	result, err := c.call(ctx, data, callopt.Apply(opts...).BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getDataSet: %w", contracts.DecodeCallError(c.abi, err))
+	}
+
+	return c.decodeDataSet(dataSetID, result)
+}
+
+// GetDataSetsForPayer returns the IDs of the data sets paid for by payer,
+// read from the state view's clientDataSets. Use GetDataSets to load them.
+func (c *StateViewContract) GetDataSetsForPayer(ctx context.Context, payer common.Address, opts ...callopt.Option) ([]int, error) {
+	data, err := c.abi.Pack("clientDataSets", payer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack clientDataSets call: %w", err)
+	}
+
+	result, err := c.call(ctx, data, callopt.Apply(opts...).BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call clientDataSets: %w", contracts.DecodeCallError(c.abi, err))
+	}
+
+	values, err := c.abi.Unpack("clientDataSets", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack clientDataSets result: %w", err)
+	}
+	rawIDs, ok := values[0].([]*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type for clientDataSets result: %T", values[0])
+	}
+
+	ids := make([]int, len(rawIDs))
+	for i, id := range rawIDs {
+		ids[i] = int(id.Int64())
+	}
+	return ids, nil
+}
+
+// FindDataSetByClientID returns the ID of payer's data set with the given
+// client data set ID, or nil if there is none. It reads every data set of
+// payer, failing if any cannot be read.
+func (c *StateViewContract) FindDataSetByClientID(ctx context.Context, payer common.Address, clientDataSetID *big.Int) (*big.Int, error) {
+	ids, err := c.GetDataSetsForPayer(ctx, payer)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := c.GetDataSets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if info := infos[id]; info.ClientDataSetID != nil && info.ClientDataSetID.Cmp(clientDataSetID) == 0 {
+			return big.NewInt(int64(id)), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetDataSets fetches several data sets in one eth_call through Multicall3.
+// Data sets that could be read are returned in the map; if any could not, the
+// error is a *DataSetsError listing them by ID. Failures of the batch call
+// itself are returned as a plain error with a nil map. On chains without
+// Multicall3 the data sets are fetched one call at a time instead.
+func (c *StateViewContract) GetDataSets(ctx context.Context, ids []int, opts ...callopt.Option) (map[int]*DataSetInfo, error) {
+	multicallAddress := c.multicall3(ctx)
+	if multicallAddress == (common.Address{}) || !c.multicall.Available(ctx, c.client, multicallAddress) {
+		return c.getDataSetsSequential(ctx, ids, opts...)
+	}
+
+	calls := make([]multicall.Call, len(ids))
+	for i, id := range ids {
+		data, err := c.abi.Pack("getDataSet", big.NewInt(int64(id)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack getDataSet call: %w", err)
+		}
+		calls[i] = multicall.Call{Target: c.address, CallData: data}
+	}
+
+	var results []multicall.Result
+	err := retry.Do(ctx, c.retry, func() error {
+		var err error
+		results, err = multicall.Aggregate3(ctx, c.client, multicallAddress, calls, callopt.Apply(opts...).BlockNumber)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to call getDataSet: %w", err)
+		return nil, err
+	}
+
+	infos := make(map[int]*DataSetInfo, len(ids))
+	var errs map[int]error
+	for i, r := range results {
+		var info *DataSetInfo
+		err := fmt.Errorf("getDataSet(%d) reverted", ids[i])
+		if r.Success {
+			info, err = c.decodeDataSet(ids[i], r.ReturnData)
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make(map[int]error)
+			}
+			errs[ids[i]] = err
+			continue
+		}
+		infos[ids[i]] = info
 	}
 
+	if errs != nil {
+		return infos, &DataSetsError{Errs: errs}
+	}
+	return infos, nil
+}
+
+// SetMulticall3Address sets the Multicall3 contract GetDataSets batches
+// through, skipping chain detection. Defaults to the deployment for the
+// client's chain; the zero address disables batching.
+func (c *StateViewContract) SetMulticall3Address(addr common.Address) {
+	c.multicallMu.Lock()
+	defer c.multicallMu.Unlock()
+	c.multicallAddress, c.multicallKnown = addr, true
+}
+
+// multicall3 returns the Multicall3 address GetDataSets batches through,
+// looking up the known deployment for the client's chain the first time. It
+// returns the zero address, without remembering it, if the chain ID cannot
+// be read.
+func (c *StateViewContract) multicall3(ctx context.Context) common.Address {
+	c.multicallMu.Lock()
+	addr, known := c.multicallAddress, c.multicallKnown
+	c.multicallMu.Unlock()
+	if known {
+		return addr
+	}
+
+	chainID, err := c.client.ChainID(ctx)
+	if err != nil {
+		return common.Address{}
+	}
+	if network, ok := constants.NetworkForChainID(chainID.Int64()); ok {
+		addr = constants.Multicall3Addresses[network]
+	}
+
+	c.multicallMu.Lock()
+	defer c.multicallMu.Unlock()
+	if !c.multicallKnown {
+		c.multicallAddress, c.multicallKnown = addr, true
+	}
+	return c.multicallAddress
+}
+
+// SetReadRetry sets how many times a read is retried after a transient RPC
+// error such as a timeout or reset connection, waiting initialInterval
+// before the first retry and doubling it each time. Reverts are never
+// retried. maxRetries 0 disables retrying. Defaults to 3 retries from 500ms.
+func (c *StateViewContract) SetReadRetry(maxRetries int, initialInterval time.Duration) {
+	c.retry = readRetryConfig(maxRetries, initialInterval)
+}
+
+// call runs an eth_call against the state view, retrying transient errors.
+func (c *StateViewContract) call(ctx context.Context, data []byte, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := retry.Do(ctx, c.retry, func() error {
+		var err error
+		result, err = c.client.CallContract(ctx, ethereum.CallMsg{
+			To:   &c.address,
+			Data: data,
+		}, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+// SetOnMulticall3Unavailable sets a function called once if no contract is
+// deployed at the Multicall3 address, after which GetDataSets falls back to
+// one call per data set.
+func (c *StateViewContract) SetOnMulticall3Unavailable(fn func(addr common.Address)) {
+	c.multicall.OnMissing = fn
+}
+
+// getDataSetsSequential is GetDataSets without Multicall3.
+func (c *StateViewContract) getDataSetsSequential(ctx context.Context, ids []int, opts ...callopt.Option) (map[int]*DataSetInfo, error) {
+	infos := make(map[int]*DataSetInfo, len(ids))
+	var errs map[int]error
+	for _, id := range ids {
+		info, err := c.GetDataSet(ctx, id, opts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if errs == nil {
+				errs = make(map[int]error)
+			}
+			errs[id] = err
+			continue
+		}
+		infos[id] = info
+	}
+
+	if errs != nil {
+		return infos, &DataSetsError{Errs: errs}
+	}
+	return infos, nil
+}
+
+func (c *StateViewContract) decodeDataSet(dataSetID int, result []byte) (*DataSetInfo, error) {
 	values, err := c.abi.Unpack("getDataSet", result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unpack getDataSet result: %w", err)
@@ -82,25 +346,13 @@ func (c *StateViewContract) GetDataSet(ctx context.Context, dataSetID int) (*Dat
 		return nil, fmt.Errorf("empty result from getDataSet")
 	}
 
-	infoStruct, ok := values[0].(struct {
-		PdpRailId       *big.Int       `abi:"pdpRailId"`
-		CacheMissRailId *big.Int       `abi:"cacheMissRailId"`
-		CdnRailId       *big.Int       `abi:"cdnRailId"`
-		Payer           common.Address `abi:"payer"`
-		Payee           common.Address `abi:"payee"`
-		ServiceProvider common.Address `abi:"serviceProvider"`
-		CommissionBps   *big.Int       `abi:"commissionBps"`
-		ClientDataSetId *big.Int       `abi:"clientDataSetId"`
-		PdpEndEpoch     *big.Int       `abi:"pdpEndEpoch"`
-		ProviderId      *big.Int       `abi:"providerId"`
-		DataSetId       *big.Int       `abi:"dataSetId"`
-	})
+	infoStruct, ok := abi.ConvertType(values[0], new(dataSetInfoTuple)).(*dataSetInfoTuple)
 	if !ok {
 		return nil, fmt.Errorf("unexpected type for getDataSet result: %T", values[0])
 	}
 
 	if infoStruct.PdpRailId.Sign() == 0 {
-		return nil, fmt.Errorf("data set %d does not exist", dataSetID)
+		return nil, fmt.Errorf("%w: %d", ErrDataSetNotExist, dataSetID)
 	}
 
 	return &DataSetInfo{
@@ -0,0 +1,392 @@
+package warmstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/data-preservation-programs/go-synapse/constants"
+	"github.com/data-preservation-programs/go-synapse/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// revertData encodes the custom error name of contractABI with args, as a
+// node returns it for a reverted call.
+func revertData(t *testing.T, contractABI abi.ABI, name string, args ...interface{}) []byte {
+	t.Helper()
+	abiErr, ok := contractABI.Errors[name]
+	if !ok {
+		t.Fatalf("%s not found in ABI", name)
+	}
+	packed, err := abiErr.Inputs.Pack(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(abiErr.ID[:4:4], packed...)
+}
+
+func TestABIsDecodeCustomErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		abiJSON string
+		errName string
+		args    []interface{}
+		want    string
+	}{
+		{"state view", StateViewABIJSON, "DataSetNotRegistered", []interface{}{big.NewInt(7)}, "DataSetNotRegistered(dataSetId=7)"},
+		{"FWSS", fwssABIJSON, "InvalidEpochRange", []interface{}{big.NewInt(10), big.NewInt(5)}, "InvalidEpochRange(startEpoch=10, endEpoch=5)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := abi.JSON(strings.NewReader(tt.abiJSON))
+			if err != nil {
+				t.Fatalf("parse ABI: %v", err)
+			}
+			reason, err := contracts.DecodeError(parsed, revertData(t, parsed, tt.errName, tt.args...))
+			if err != nil {
+				t.Fatalf("DecodeError() error = %v", err)
+			}
+			if reason != tt.want {
+				t.Errorf("DecodeError() = %q, want %q", reason, tt.want)
+			}
+		})
+	}
+}
+
+// aggregate3TestABI is the Multicall3 aggregate3 function the stub answers.
+const aggregate3TestABI = `[{"type":"function","name":"aggregate3","stateMutability":"payable",
+	"inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+	"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+var (
+	testStateView = common.HexToAddress("0x5000000000000000000000000000000000000005")
+	testPayer     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+)
+
+// revertError is a reverted eth_call carrying revert data, as a node
+// returns it.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// stateViewAPI is a node of chain chainID serving a state view at
+// testStateView. Data sets
+// are keyed by ID; getDataSet of an unknown ID returns the zero tuple, and
+// of revertID reverts with DataSetNotRegistered. aggregate3 is answered at
+// multicall when it is deployed. The first failures calls fail with a
+// transient network error.
+type stateViewAPI struct {
+	t         *testing.T
+	chainID   int64
+	multicall common.Address
+	deployed  map[common.Address]bool
+	dataSets  map[int64]dataSetInfoTuple
+	clients   map[common.Address][]int64
+	revertID  int64
+	failures  int
+
+	mu      sync.Mutex
+	calls   int
+	batches int
+}
+
+func newStateViewAPI(t *testing.T, network constants.Network) *stateViewAPI {
+	multicall := constants.Multicall3Addresses[network]
+	return &stateViewAPI{
+		t:         t,
+		chainID:   constants.NetworkChainIDs[network],
+		multicall: multicall,
+		deployed:  map[common.Address]bool{testStateView: true, multicall: true},
+		dataSets: map[int64]dataSetInfoTuple{
+			1: testDataSet(1, 100),
+			2: testDataSet(2, 200),
+		},
+		clients:  map[common.Address][]int64{testPayer: {1, 2}},
+		revertID: 13,
+	}
+}
+
+func testDataSet(id, clientID int64) dataSetInfoTuple {
+	return dataSetInfoTuple{
+		PdpRailId:       big.NewInt(id * 10),
+		CacheMissRailId: big.NewInt(0),
+		CdnRailId:       big.NewInt(0),
+		Payer:           testPayer,
+		Payee:           common.HexToAddress("0x2000000000000000000000000000000000000002"),
+		ServiceProvider: common.HexToAddress("0x3000000000000000000000000000000000000003"),
+		CommissionBps:   big.NewInt(100),
+		ClientDataSetId: big.NewInt(clientID),
+		PdpEndEpoch:     big.NewInt(0),
+		ProviderId:      big.NewInt(4),
+		DataSetId:       big.NewInt(id),
+	}
+}
+
+func (a *stateViewAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(a.chainID))
+}
+
+func (a *stateViewAPI) GetCode(addr common.Address, block string) hexutil.Bytes {
+	if a.deployed[addr] {
+		return hexutil.Bytes{0x60, 0x80}
+	}
+	return nil
+}
+
+func (a *stateViewAPI) Call(args struct {
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls++
+	if a.failures > 0 {
+		a.failures--
+		return nil, errors.New("read tcp: connection reset by peer")
+	}
+
+	if args.To != a.multicall {
+		return a.stateViewCall(args.Input)
+	}
+	a.batches++
+	mc, err := abi.JSON(strings.NewReader(aggregate3TestABI))
+	if err != nil {
+		return nil, err
+	}
+	in, err := mc.Methods["aggregate3"].Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := in[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	out := make([]result, len(calls))
+	for i, c := range calls {
+		data, err := a.stateViewCall(c.CallData)
+		out[i] = result{Success: err == nil, ReturnData: data}
+	}
+	return mc.Methods["aggregate3"].Outputs.Pack(out)
+}
+
+func (a *stateViewAPI) stateViewCall(input []byte) (hexutil.Bytes, error) {
+	parsed, err := abi.JSON(strings.NewReader(StateViewABIJSON))
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(input)
+	if err != nil {
+		return nil, err
+	}
+	in, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "getDataSet":
+		id := in[0].(*big.Int).Int64()
+		if id == a.revertID {
+			return nil, &revertError{data: revertData(a.t, parsed, "DataSetNotRegistered", big.NewInt(id))}
+		}
+		info, ok := a.dataSets[id]
+		if !ok {
+			zero := big.NewInt(0)
+			info = dataSetInfoTuple{zero, zero, zero, common.Address{}, common.Address{}, common.Address{}, zero, zero, zero, zero, zero}
+		}
+		return method.Outputs.Pack(info)
+	case "clientDataSets":
+		var ids []*big.Int
+		for _, id := range a.clients[in[0].(common.Address)] {
+			ids = append(ids, big.NewInt(id))
+		}
+		return method.Outputs.Pack(ids)
+	}
+	return nil, fmt.Errorf("unexpected method %s", method.Name)
+}
+
+func newTestStateView(t *testing.T, api *stateViewAPI) *StateViewContract {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+
+	c, err := NewStateViewContract(testStateView, ethclient.NewClient(rpcClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReadRetry(3, time.Millisecond)
+	return c
+}
+
+func TestNewStateViewContract_Multicall3Default(t *testing.T) {
+	for _, network := range []constants.Network{constants.NetworkMainnet, constants.NetworkCalibration, constants.NetworkDevnet} {
+		c := newTestStateView(t, newStateViewAPI(t, network))
+		if got, want := c.multicall3(context.Background()), constants.Multicall3Addresses[network]; got != want {
+			t.Errorf("%s: Multicall3 = %s, want %s", network, got.Hex(), want.Hex())
+		}
+	}
+
+	// a chain without a known Multicall3 reads data sets one at a time
+	api := newStateViewAPI(t, "custom")
+	api.chainID = 1
+	c := newTestStateView(t, api)
+	if got := c.multicall3(context.Background()); got != (common.Address{}) {
+		t.Errorf("custom chain Multicall3 = %s, want none", got.Hex())
+	}
+	infos, err := c.GetDataSets(context.Background(), []int{1, 2})
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("GetDataSets() = %v, %v", infos, err)
+	}
+	if api.batches != 0 || api.calls != 2 {
+		t.Errorf("made %d batches and %d calls, want 0 and 2", api.batches, api.calls)
+	}
+
+	// an explicit address overrides detection; the zero address disables batching
+	api = newStateViewAPI(t, constants.NetworkCalibration)
+	c = newTestStateView(t, api)
+	c.SetMulticall3Address(common.Address{})
+	if _, err := c.GetDataSets(context.Background(), []int{1, 2}); err != nil {
+		t.Fatalf("GetDataSets() error = %v", err)
+	}
+	if api.batches != 0 {
+		t.Errorf("made %d batches with Multicall3 disabled", api.batches)
+	}
+}
+
+func TestStateViewContract_GetDataSet(t *testing.T) {
+	c := newTestStateView(t, newStateViewAPI(t, constants.NetworkCalibration))
+
+	info, err := c.GetDataSet(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetDataSet() error = %v", err)
+	}
+	want := testDataSet(2, 200)
+	if info.PDPRailID.Cmp(want.PdpRailId) != 0 || info.Payer != want.Payer || info.ServiceProvider != want.ServiceProvider ||
+		info.ClientDataSetID.Cmp(want.ClientDataSetId) != 0 || info.ProviderID.Cmp(want.ProviderId) != 0 || info.DataSetID.Int64() != 2 {
+		t.Errorf("GetDataSet() = %+v, want %+v", info, want)
+	}
+
+	if _, err := c.GetDataSet(context.Background(), 99); !errors.Is(err, ErrDataSetNotExist) {
+		t.Errorf("GetDataSet(99) error = %v, want ErrDataSetNotExist", err)
+	}
+
+	_, err = c.GetDataSet(context.Background(), 13)
+	var revertErr *contracts.RevertError
+	if !errors.As(err, &revertErr) || revertErr.Reason != "DataSetNotRegistered(dataSetId=13)" {
+		t.Errorf("GetDataSet(13) error = %v, want decoded DataSetNotRegistered", err)
+	}
+}
+
+func TestStateViewContract_GetDataSets(t *testing.T) {
+	api := newStateViewAPI(t, constants.NetworkCalibration)
+	c := newTestStateView(t, api)
+
+	infos, err := c.GetDataSets(context.Background(), []int{1, 2, 13})
+	var setsErr *DataSetsError
+	if !errors.As(err, &setsErr) || len(setsErr.Errs) != 1 || setsErr.Errs[13] == nil {
+		t.Fatalf("GetDataSets() error = %v, want data set 13 reported", err)
+	}
+	if len(infos) != 2 || infos[1].ClientDataSetID.Int64() != 100 || infos[2].ClientDataSetID.Int64() != 200 {
+		t.Errorf("GetDataSets() = %+v", infos)
+	}
+	if api.batches != 1 {
+		t.Errorf("made %d batches, want 1", api.batches)
+	}
+}
+
+func TestStateViewContract_GetDataSetsWithoutMulticall(t *testing.T) {
+	api := newStateViewAPI(t, constants.NetworkCalibration)
+	delete(api.deployed, api.multicall)
+	c := newTestStateView(t, api)
+	var missing []common.Address
+	c.SetOnMulticall3Unavailable(func(addr common.Address) { missing = append(missing, addr) })
+
+	for i := 0; i < 2; i++ {
+		infos, err := c.GetDataSets(context.Background(), []int{1, 2})
+		if err != nil || len(infos) != 2 {
+			t.Fatalf("GetDataSets() = %v, %v", infos, err)
+		}
+	}
+	if api.batches != 0 {
+		t.Errorf("made %d batches without Multicall3", api.batches)
+	}
+	if len(missing) != 1 || missing[0] != api.multicall {
+		t.Errorf("OnMulticall3Unavailable called with %v, want once with %s", missing, api.multicall.Hex())
+	}
+}
+
+func TestStateViewContract_GetDataSetsForPayer(t *testing.T) {
+	c := newTestStateView(t, newStateViewAPI(t, constants.NetworkCalibration))
+
+	ids, err := c.GetDataSetsForPayer(context.Background(), testPayer)
+	if err != nil || len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("GetDataSetsForPayer() = %v, %v; want [1 2]", ids, err)
+	}
+	ids, err = c.GetDataSetsForPayer(context.Background(), common.HexToAddress("0x09"))
+	if err != nil || len(ids) != 0 {
+		t.Errorf("GetDataSetsForPayer(other) = %v, %v; want none", ids, err)
+	}
+
+	id, err := c.FindDataSetByClientID(context.Background(), testPayer, big.NewInt(200))
+	if err != nil || id == nil || id.Int64() != 2 {
+		t.Errorf("FindDataSetByClientID(200) = %v, %v; want 2", id, err)
+	}
+	id, err = c.FindDataSetByClientID(context.Background(), testPayer, big.NewInt(300))
+	if err != nil || id != nil {
+		t.Errorf("FindDataSetByClientID(300) = %v, %v; want nil, nil", id, err)
+	}
+}
+
+func TestStateViewContract_ReadRetry(t *testing.T) {
+	api := newStateViewAPI(t, constants.NetworkCalibration)
+	c := newTestStateView(t, api)
+
+	api.failures = 2
+	if _, err := c.GetDataSet(context.Background(), 1); err != nil {
+		t.Fatalf("GetDataSet() after transient errors = %v", err)
+	}
+	if api.calls != 3 {
+		t.Errorf("made %d calls, want 3", api.calls)
+	}
+
+	api.failures, api.calls = 2, 0
+	if _, err := c.GetDataSets(context.Background(), []int{1, 2}); err != nil {
+		t.Fatalf("GetDataSets() after transient errors = %v", err)
+	}
+
+	// reverts are not retried
+	api.calls = 0
+	if _, err := c.GetDataSet(context.Background(), 13); err == nil {
+		t.Fatal("GetDataSet(13) expected error")
+	}
+	if api.calls != 1 {
+		t.Errorf("revert made %d calls, want 1", api.calls)
+	}
+
+	c.SetReadRetry(0, time.Millisecond)
+	api.failures = 1
+	if _, err := c.GetDataSet(context.Background(), 1); err == nil {
+		t.Error("GetDataSet() with retries disabled expected error")
+	}
+}
@@ -6,12 +6,99 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/data-preservation-programs/go-synapse/contracts"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// fwssErrorsABIJSON declares the custom errors of FilecoinWarmStorageService
+// (its Errors library), shared by the service and its state view, as a
+// fragment of ABI entries.
+const fwssErrorsABIJSON = `
+	{
+		"type": "error",
+		"name": "ZeroAddress",
+		"inputs": [{"name": "field", "type": "uint8"}]
+	},
+	{
+		"type": "error",
+		"name": "OnlyPDPVerifierAllowed",
+		"inputs": [
+			{"name": "expected", "type": "address"},
+			{"name": "actual", "type": "address"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "InvalidEpochRange",
+		"inputs": [
+			{"name": "startEpoch", "type": "uint256"},
+			{"name": "endEpoch", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "DataSetNotRegistered",
+		"inputs": [{"name": "dataSetId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "ProvingPeriodNotInitialized",
+		"inputs": [{"name": "dataSetId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "DataSetPaymentAlreadyTerminated",
+		"inputs": [{"name": "dataSetId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "NoPDPPaymentRail",
+		"inputs": [{"name": "dataSetId", "type": "uint256"}]
+	},
+	{
+		"type": "error",
+		"name": "ProviderNotRegistered",
+		"inputs": [{"name": "provider", "type": "address"}]
+	},
+	{
+		"type": "error",
+		"name": "ProviderNotApproved",
+		"inputs": [
+			{"name": "provider", "type": "address"},
+			{"name": "providerId", "type": "uint256"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "CallerNotPayer",
+		"inputs": [
+			{"name": "dataSetId", "type": "uint256"},
+			{"name": "expectedPayer", "type": "address"},
+			{"name": "caller", "type": "address"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "CallerNotPayerOrPayee",
+		"inputs": [
+			{"name": "dataSetId", "type": "uint256"},
+			{"name": "expectedPayer", "type": "address"},
+			{"name": "expectedPayee", "type": "address"},
+			{"name": "caller", "type": "address"}
+		]
+	},
+	{
+		"type": "error",
+		"name": "InvalidSignature",
+		"inputs": [
+			{"name": "expected", "type": "address"},
+			{"name": "actual", "type": "address"}
+		]
+	}`
+
 const fwssABIJSON = `[
 	{
 		"type": "function",
@@ -32,7 +119,7 @@ const fwssABIJSON = `[
 			}
 		],
 		"stateMutability": "view"
-	}
+	},` + fwssErrorsABIJSON + `,` + contracts.UpgradeableErrorsABIJSON + `
 ]`
 
 type FWSSContract struct {
@@ -65,7 +152,7 @@ func (c *FWSSContract) GetServicePrice(ctx context.Context) (*ServicePrice, erro
 		Data: data,
 	}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call getServicePrice: %w", err)
+		return nil, fmt.Errorf("failed to call getServicePrice: %w", contracts.DecodeCallError(c.abi, err))
 	}
 
 	values, err := c.abi.Unpack("getServicePrice", result)
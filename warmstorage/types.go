@@ -1,6 +1,7 @@
 package warmstorage
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -28,3 +29,13 @@ type DataSetInfo struct {
 	ProviderID      *big.Int
 	DataSetID       *big.Int
 }
+
+// DataSetsError reports the data sets a batched GetDataSets could not read,
+// keyed by data set ID.
+type DataSetsError struct {
+	Errs map[int]error
+}
+
+func (e *DataSetsError) Error() string {
+	return fmt.Sprintf("failed to get %d data set(s)", len(e.Errs))
+}